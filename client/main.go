@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"os"
 	"time"
 
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/simulate"
 	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
@@ -17,7 +20,10 @@ const (
 )
 
 var possibleDomains [4]string = [4]string{"mywebsite.com", "api.mywebsite.com", "cdn.mywebsite.com", "blog.mywebsite.com"}
-var possibleQueryType [2]string = [2]string{"A", "AAAA"}
+var possibleQueryType [6]pb.QueryType = [6]pb.QueryType{
+	pb.QueryType_QUERY_TYPE_A, pb.QueryType_QUERY_TYPE_AAAA, pb.QueryType_QUERY_TYPE_MX,
+	pb.QueryType_QUERY_TYPE_TXT, pb.QueryType_QUERY_TYPE_CNAME, pb.QueryType_QUERY_TYPE_HTTPS,
+}
 
 func randomIPAddress() string {
 	return fmt.Sprintf("%d.%d.%d.%d", rand.Intn(256), rand.Intn(256), rand.Intn(256), rand.Intn(256))
@@ -27,10 +33,34 @@ func randomDomain() string {
 	return possibleDomains[rand.Intn(len(possibleDomains))]
 }
 
-func randomQueryType() string {
+func randomQueryType() pb.QueryType {
 	return possibleQueryType[rand.Intn(len(possibleQueryType))]
 }
 
+var possibleTransports [4]pb.Transport = [4]pb.Transport{
+	pb.Transport_TRANSPORT_UDP, pb.Transport_TRANSPORT_TCP, pb.Transport_TRANSPORT_DOT, pb.Transport_TRANSPORT_DOH,
+}
+
+// randomResponse fills the response telemetry of req: one in ten queries is
+// answered NXDOMAIN, A and AAAA queries otherwise get an address record
+func randomResponse(req *pb.DnsRequest) {
+	req.QuerySize = uint32(len(req.GetDomain()) + 18)
+	req.ResponseSize = req.QuerySize
+	if rand.Intn(10) == 0 {
+		req.Rcode = pb.ResponseCode_RESPONSE_CODE_NXDOMAIN.Enum()
+		return
+	}
+	req.Rcode = pb.ResponseCode_RESPONSE_CODE_NOERROR.Enum()
+	switch req.GetQueryType() {
+	case pb.QueryType_QUERY_TYPE_A:
+		req.Answers = []*pb.Answer{{Type: pb.QueryType_QUERY_TYPE_A, Data: randomIPAddress(), Ttl: 300}}
+		req.ResponseSize += 16
+	case pb.QueryType_QUERY_TYPE_AAAA:
+		req.Answers = []*pb.Answer{{Type: pb.QueryType_QUERY_TYPE_AAAA, Data: fmt.Sprintf("2001:db8::%x", rand.Intn(0x10000)), Ttl: 300}}
+		req.ResponseSize += 28
+	}
+}
+
 func main() {
 	// Sleep a bit to let the server setup
 	time.Sleep(10 * time.Second)
@@ -45,18 +75,48 @@ func main() {
 
 	client := pb.NewDnsServiceClient(conn)
 
+	// Requests belong to the tenant TENANT_ID, the default tenant if unset
+	ctx := context.Background()
+	if tenant := os.Getenv("TENANT_ID"); tenant != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", tenant)
+	}
+
+	// With TUNNEL_DOMAIN, one request in ten comes from a DNS tunnel through
+	// that domain, to exercise the tunneling detection of the consumer
+	var tunnel *simulate.Tunnel
+	if domain := os.Getenv("TUNNEL_DOMAIN"); domain != "" {
+		var err error
+		tunnel, err = simulate.NewTunnel(randomIPAddress(), domain, time.Now().UnixNano())
+		if err != nil {
+			log.Fatalf("Invalid TUNNEL_DOMAIN: %v", err)
+		}
+		log.Printf("Simulating a DNS tunnel from %s through %s", tunnel.Source, domain)
+	}
+
 	for {
+		if tunnel != nil && rand.Intn(10) == 0 {
+			if _, err := client.SendDnsRequest(ctx, tunnel.Next(time.Now())); err != nil {
+				log.Printf("Error sending DNS request: %v", err)
+			}
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
 		// Create a DNS request message
 
 		req := &pb.DnsRequest{
-			IpAddress: randomIPAddress(),
-			Domain:    randomDomain(),
-			QueryType: randomQueryType(),
-			Timestamp: time.Now().Unix(),
+			IpAddress:  randomIPAddress(),
+			Domain:     randomDomain(),
+			QueryType:  randomQueryType(),
+			Timestamp:  time.Now().Unix(),
+			ClientPort: uint32(1024 + rand.Intn(64512)),
+			Transport:  possibleTransports[rand.Intn(len(possibleTransports))],
+			SensorId:   "dns-client",
 		}
+		randomResponse(req)
 
 		// Send the request to the server
-		_, err := client.SendDnsRequest(context.Background(), req)
+		_, err := client.SendDnsRequest(ctx, req)
 		if err != nil {
 			log.Printf("Error sending DNS request: %v", err)
 		}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+// blocklistFormat returns the format named by the -format flag, guessed from
+// the extension of the file when the flag is empty.
+func blocklistFormat(format, file string) (pb.BlocklistFormat, error) {
+	if format == "" {
+		format = "jsonl"
+		if strings.EqualFold(filepath.Ext(file), ".csv") {
+			format = "csv"
+		}
+	}
+	switch format {
+	case "jsonl":
+		return pb.BlocklistFormat_BLOCKLIST_FORMAT_JSONL, nil
+	case "csv":
+		return pb.BlocklistFormat_BLOCKLIST_FORMAT_CSV, nil
+	}
+	return 0, fmt.Errorf("unknown format %q, expected jsonl or csv", format)
+}
+
+func runExport(e *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "jsonl or csv, guessed from the file extension if empty")
+	file := fs.String("file", "-", "file to write, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f, err := blocklistFormat(*format, *file)
+	if err != nil {
+		return err
+	}
+	// The export may be large, so it is not bounded by -timeout.
+	stream, err := e.client.ExportBlocklist(e.outgoing(context.Background()), &pb.ExportBlocklistRequest{Format: f})
+	if err != nil {
+		return err
+	}
+	w := e.out
+	var out *os.File
+	if *file != "-" {
+		if out, err = os.Create(*file); err != nil {
+			return err
+		}
+		defer out.Close()
+		w = out
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(resp.GetData()); err != nil {
+			return err
+		}
+	}
+	if out != nil {
+		return out.Close()
+	}
+	return nil
+}
+
+func runRestore(e *env, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	format := fs.String("format", "", "jsonl or csv, guessed from the file extension if empty")
+	file := fs.String("file", "-", "file to read, - for stdin")
+	mode := fs.String("mode", "merge", "merge the file into the blacklist, or replace the blacklist with it")
+	dryRun := fs.Bool("dry-run", false, "list the changes without applying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f, err := blocklistFormat(*format, *file)
+	if err != nil {
+		return err
+	}
+	var m pb.ImportMode
+	switch *mode {
+	case "merge":
+		m = pb.ImportMode_IMPORT_MODE_MERGE
+	case "replace":
+		m = pb.ImportMode_IMPORT_MODE_REPLACE
+	default:
+		return fmt.Errorf("unknown mode %q, expected merge or replace", *mode)
+	}
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		in, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		r = in
+	}
+
+	stream, err := e.client.ImportBlocklist(e.outgoing(context.Background()))
+	if err != nil {
+		return err
+	}
+	// The options go with the first chunk, sent even for an empty file
+	req := &pb.ImportBlocklistRequest{Format: f, Mode: m, DryRun: *dryRun}
+	buf := make([]byte, 64*1024)
+	for sent := false; ; {
+		n, err := r.Read(buf)
+		if n > 0 || (!sent && errors.Is(err, io.EOF)) {
+			req.Data = buf[:n]
+			if err := stream.Send(req); err != nil {
+				return err
+			}
+			req, sent = &pb.ImportBlocklistRequest{}, true
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(e.out, e.format, "action", "ip_address", "reason", "ttl_seconds", "previous_reason", "previous_ttl_seconds", "detail")
+	if err != nil {
+		return err
+	}
+	for _, c := range resp.GetChanges() {
+		prevReason, prevTTL := "", ""
+		if prev := c.GetPrevious(); prev != nil {
+			prevReason, prevTTL = prev.GetReason(), fmt.Sprint(prev.GetTtlSeconds())
+		}
+		if err := p.Row(c.GetAction(), c.GetEntry().GetIpAddress(), c.GetEntry().GetReason(), c.GetEntry().GetTtlSeconds(), prevReason, prevTTL, c.GetDetail()); err != nil {
+			return err
+		}
+	}
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d added, %d updated, %d removed, %d unchanged, %d skipped\n",
+		resp.GetStatus(), resp.GetAdded(), resp.GetUpdated(), resp.GetRemoved(), resp.GetUnchanged(), resp.GetSkipped())
+	if resp.GetTruncated() {
+		fmt.Fprintf(os.Stderr, "only the first %d changes are listed\n", len(resp.GetChanges()))
+	}
+	if resp.GetStatus() == "failed" {
+		return errors.New("some changes could not be applied")
+	}
+	return nil
+}
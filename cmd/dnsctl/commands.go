@@ -0,0 +1,571 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/qtype"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+var errUsage = errors.New("invalid arguments, see dnsctl help")
+
+// rcodeString returns the response code of req without its enum prefix, or
+// "" if it has none.
+func rcodeString(req *pb.DnsRequest) string {
+	if req.Rcode == nil {
+		return ""
+	}
+	return strings.TrimPrefix(req.GetRcode().String(), "RESPONSE_CODE_")
+}
+
+func runBlock(e *env, args []string) error {
+	fs := flag.NewFlagSet("block", flag.ContinueOnError)
+	reason := fs.String("reason", "", "reason recorded with the block")
+	ttl := fs.Duration("ttl", 0, "duration of the block, 0 blocks until unblocked")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	p, err := newPrinter(e.out, e.format, "ip_address", "status")
+	if err != nil {
+		return err
+	}
+	for _, ip := range fs.Args() {
+		ctx, cancel := e.context()
+		resp, err := e.client.BlockIp(ctx, &pb.BlockIpRequest{
+			IpAddress:  ip,
+			Reason:     *reason,
+			TtlSeconds: int64(ttl.Seconds()),
+		})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", ip, err)
+		}
+		if err := p.Row(ip, resp.GetStatus()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runUnblock(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	p, err := newPrinter(e.out, e.format, "ip_address", "status")
+	if err != nil {
+		return err
+	}
+	for _, ip := range args {
+		ctx, cancel := e.context()
+		resp, err := e.client.UnblockIp(ctx, &pb.UnblockIpRequest{IpAddress: ip})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", ip, err)
+		}
+		if err := p.Row(ip, resp.GetStatus()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runList(e *env, args []string) error {
+	ctx, cancel := e.context()
+	defer cancel()
+	resp, err := e.client.ListBlockedIps(ctx, &pb.ListBlockedIpsRequest{})
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "ip_address", "reason", "created_at", "ttl_seconds")
+	if err != nil {
+		return err
+	}
+	blocked := resp.GetBlockedIps()
+	sort.Slice(blocked, func(i, j int) bool { return blocked[i].GetIpAddress() < blocked[j].GetIpAddress() })
+	for _, b := range blocked {
+		if err := p.Row(b.GetIpAddress(), b.GetReason(), formatUnix(b.GetCreatedAt()), b.GetTtlSeconds()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runAllow(e *env, args []string) error {
+	fs := flag.NewFlagSet("allow", flag.ContinueOnError)
+	reason := fs.String("reason", "", "reason recorded with the entry")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	p, err := newPrinter(e.out, e.format, "value", "kind", "status")
+	if err != nil {
+		return err
+	}
+	for _, value := range fs.Args() {
+		ctx, cancel := e.context()
+		resp, err := e.client.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: value, Reason: *reason})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", value, err)
+		}
+		if err := p.Row(resp.GetEntry().GetValue(), resp.GetEntry().GetKind(), resp.GetStatus()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runDisallow(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	p, err := newPrinter(e.out, e.format, "value", "status")
+	if err != nil {
+		return err
+	}
+	for _, value := range args {
+		ctx, cancel := e.context()
+		resp, err := e.client.RemoveAllowlistEntry(ctx, &pb.RemoveAllowlistEntryRequest{Value: value})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", value, err)
+		}
+		if err := p.Row(value, resp.GetStatus()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runAllowlist(e *env, args []string) error {
+	ctx, cancel := e.context()
+	defer cancel()
+	resp, err := e.client.ListAllowlist(ctx, &pb.ListAllowlistRequest{})
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "value", "kind", "reason", "created_at")
+	if err != nil {
+		return err
+	}
+	entries := resp.GetEntries()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].GetValue() < entries[j].GetValue() })
+	for _, a := range entries {
+		if err := p.Row(a.GetValue(), a.GetKind(), a.GetReason(), formatUnix(a.GetCreatedAt())); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runBlockRule(e *env, args []string) error {
+	fs := flag.NewFlagSet("block-rule", flag.ContinueOnError)
+	domain := fs.String("domain", "", "domain, or *.domain for its subdomains, empty for any domain")
+	queryType := fs.String("type", "", "query type, empty for any type")
+	reason := fs.String("reason", "", "reason recorded with the rule")
+	ttl := fs.Duration("ttl", 0, "duration of the rule, 0 keeps it until removed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sources := fs.Args()
+	if len(sources) == 0 {
+		// A rule for any source needs a domain or a query type
+		sources = []string{""}
+	}
+	var qt pb.QueryType
+	if *queryType != "" {
+		var err error
+		if qt, err = qtype.Parse(*queryType); err != nil {
+			return err
+		}
+	}
+	p, err := newPrinter(e.out, e.format, "id", "source", "domain_pattern", "query_type", "status")
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		ctx, cancel := e.context()
+		resp, err := e.client.AddBlockRule(ctx, &pb.AddBlockRuleRequest{
+			Source:        source,
+			DomainPattern: *domain,
+			QueryType:     qt,
+			Reason:        *reason,
+			TtlSeconds:    int64(ttl.Seconds()),
+		})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		r := resp.GetRule()
+		if err := p.Row(r.GetId(), r.GetSource(), r.GetDomainPattern(), ruleQueryType(r), resp.GetStatus()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runUnblockRule(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	p, err := newPrinter(e.out, e.format, "id", "status")
+	if err != nil {
+		return err
+	}
+	for _, id := range args {
+		ctx, cancel := e.context()
+		resp, err := e.client.RemoveBlockRule(ctx, &pb.RemoveBlockRuleRequest{Id: id})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		if err := p.Row(id, resp.GetStatus()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runBlockRules(e *env, args []string) error {
+	ctx, cancel := e.context()
+	defer cancel()
+	resp, err := e.client.ListBlockRules(ctx, &pb.ListBlockRulesRequest{})
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "id", "source", "domain_pattern", "query_type", "reason", "created_at", "ttl_seconds")
+	if err != nil {
+		return err
+	}
+	rules := resp.GetRules()
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].GetSource() != rules[j].GetSource() {
+			return rules[i].GetSource() < rules[j].GetSource()
+		}
+		return rules[i].GetDomainPattern() < rules[j].GetDomainPattern()
+	})
+	for _, r := range rules {
+		if err := p.Row(r.GetId(), r.GetSource(), r.GetDomainPattern(), ruleQueryType(r), r.GetReason(), formatUnix(r.GetCreatedAt()), r.GetTtlSeconds()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+// ruleQueryType returns the query type of a block rule, "" for any type.
+func ruleQueryType(r *pb.BlockRule) string {
+	if r.GetQueryType() == pb.QueryType_QUERY_TYPE_UNKNOWN {
+		return ""
+	}
+	return qtype.String(r.GetQueryType())
+}
+
+func runCheck(e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	ctx, cancel := e.context()
+	defer cancel()
+	resp, err := e.client.CheckIp(ctx, &pb.CheckIpRequest{IpAddress: args[0]})
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "ip_address", "blocked", "reason", "ttl_seconds")
+	if err != nil {
+		return err
+	}
+	if err := p.Row(resp.GetIpAddress(), resp.GetBlocked(), resp.GetEntry().GetReason(), resp.GetEntry().GetTtlSeconds()); err != nil {
+		return err
+	}
+	return p.Flush()
+}
+
+// formatUnix formats a Unix time as RFC 3339 in UTC, or "" for zero.
+func formatUnix(sec int64) string {
+	if sec == 0 {
+		return ""
+	}
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
+
+func runSend(e *env, args []string) error {
+	fs := flag.NewFlagSet("send", flag.ContinueOnError)
+	ip := fs.String("ip", "", "source IP address")
+	domain := fs.String("domain", "", "queried domain")
+	queryType := fs.String("type", "A", "query type, a mnemonic like AAAA or TYPE<n>")
+	rcode := fs.String("rcode", "", "response code, e.g. NOERROR or NXDOMAIN")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ip == "" || *domain == "" {
+		return errUsage
+	}
+	qt, err := qtype.Parse(*queryType)
+	if err != nil {
+		return err
+	}
+	req := &pb.DnsRequest{
+		IpAddress: *ip,
+		Domain:    *domain,
+		QueryType: qt,
+		Timestamp: time.Now().Unix(),
+	}
+	if *rcode != "" {
+		v, ok := pb.ResponseCode_value["RESPONSE_CODE_"+strings.ToUpper(*rcode)]
+		if !ok {
+			return fmt.Errorf("unknown response code %q", *rcode)
+		}
+		req.Rcode = pb.ResponseCode(v).Enum()
+	}
+	ctx, cancel := e.context()
+	defer cancel()
+	resp, err := e.client.SendDnsRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "ip_address", "domain", "query_type", "status", "reason", "rule")
+	if err != nil {
+		return err
+	}
+	if err := p.Row(*ip, *domain, qtype.String(qt), resp.GetStatus(), resp.GetReason(), resp.GetRule().GetId()); err != nil {
+		return err
+	}
+	return p.Flush()
+}
+
+func runExplain(e *env, args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	ip := fs.String("ip", "", "source IP address")
+	domain := fs.String("domain", "", "queried domain")
+	queryType := fs.String("type", "A", "query type, a mnemonic like AAAA or TYPE<n>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ip == "" || *domain == "" {
+		return errUsage
+	}
+	qt, err := qtype.Parse(*queryType)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.context()
+	defer cancel()
+	resp, err := e.client.ExplainVerdict(ctx, &pb.ExplainVerdictRequest{IpAddress: *ip, Domain: *domain, QueryType: qt})
+	if err != nil {
+		return err
+	}
+	// One row per match, repeating the verdict, or a single row without
+	// match columns when nothing matched
+	p, err := newPrinter(e.out, e.format, "status", "reason", "kind", "value", "match_reason", "ttl_seconds", "decisive")
+	if err != nil {
+		return err
+	}
+	if len(resp.GetMatches()) == 0 {
+		if err := p.Row(resp.GetStatus(), resp.GetReason(), "", "", "", "", ""); err != nil {
+			return err
+		}
+	}
+	for _, m := range resp.GetMatches() {
+		if err := p.Row(resp.GetStatus(), resp.GetReason(), m.GetKind(), m.GetValue(), m.GetReason(), m.GetTtlSeconds(), m.GetDecisive()); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runImport(e *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "-", "file to import, - for stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	ips, err := readIPs(r)
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(e.out, e.format, "ip_address", "status")
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, ip := range ips {
+		ctx, cancel := e.context()
+		resp, err := e.client.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: ip})
+		cancel()
+		status := resp.GetStatus()
+		if err != nil {
+			failed++
+			status = err.Error()
+		}
+		if err := p.Row(ip, status); err != nil {
+			return err
+		}
+	}
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d IPs failed to import", failed, len(ips))
+	}
+	return nil
+}
+
+// readIPs reads IPs from a plain list (one per line) or a CSV file whose first
+// column is the IP. Empty lines, lines starting with # and a header row whose
+// first column is "ip" or "ip_address" are skipped.
+func readIPs(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var ips []string
+	for i, record := range records {
+		ip := strings.TrimSpace(record[0])
+		if i == 0 && (strings.EqualFold(ip, "ip") || strings.EqualFold(ip, "ip_address")) {
+			continue
+		}
+		if ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+func runStats(e *env, args []string) error {
+	ctx, cancel := e.context()
+	defer cancel()
+	resp, err := e.client.GetStats(ctx, &pb.GetStatsRequest{})
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "counter", "value")
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(resp.GetCounters()))
+	for name := range resp.GetCounters() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := p.Row(name, resp.GetCounters()[name]); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runTail(e *env, args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	count := fs.Int("n", 0, "stop after this many requests, 0 to follow forever")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// The stream is long lived, so it is not bounded by -timeout.
+	stream, err := e.client.TailDnsRequests(e.outgoing(context.Background()), &pb.TailDnsRequestsRequest{})
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "timestamp", "ip_address", "domain", "query_type", "rcode", "status")
+	if err != nil {
+		return err
+	}
+	for i := 0; *count == 0 || i < *count; i++ {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		req := ev.GetRequest()
+		if err := p.Row(formatUnix(req.GetTimestamp()), req.GetIpAddress(), req.GetDomain(), qtype.String(req.GetQueryType()), rcodeString(req), ev.GetStatus()); err != nil {
+			return err
+		}
+		if err := p.Flush(); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func runWatch(e *env, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	resume := fs.String("resume", "", "resume token of a previous watch, skipping the snapshot")
+	count := fs.Int("n", 0, "stop after this many events, 0 to follow forever")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// The stream is long lived, so it is not bounded by -timeout.
+	stream, err := e.client.WatchBlocks(e.outgoing(context.Background()), &pb.WatchBlocksRequest{ResumeToken: *resume})
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(e.out, e.format, "time", "type", "ip_address", "rule", "reason", "ttl_seconds", "resume_token")
+	if err != nil {
+		return err
+	}
+	for i := 0; *count == 0 || i < *count; i++ {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		kind := strings.ToLower(strings.TrimPrefix(ev.GetType().String(), "BLOCK_EVENT_TYPE_"))
+		ip, rule, reason, ttl := "", "", "", int64(0)
+		if b := ev.GetBlockedIp(); b != nil {
+			ip, reason, ttl = b.GetIpAddress(), b.GetReason(), b.GetTtlSeconds()
+		} else if r := ev.GetRule(); r != nil {
+			rule, reason, ttl = r.GetId(), r.GetReason(), r.GetTtlSeconds()
+		}
+		if err := p.Row(formatUnix(ev.GetTime()), kind, ip, rule, reason, ttl, ev.GetResumeToken()); err != nil {
+			return err
+		}
+		if err := p.Flush(); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
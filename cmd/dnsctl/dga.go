@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dga"
+)
+
+func runDGATrain(e *env, args []string) error {
+	fs := flag.NewFlagSet("dga-train", flag.ContinueOnError)
+	in := fs.String("in", "-", "list of benign domains or words, one per line or CSV ending with the domain, - for stdin")
+	out := fs.String("out", "-", "model file to write, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	m, err := dga.Train(r)
+	if err != nil {
+		return err
+	}
+	if *out == "-" {
+		return m.Write(e.out)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := m.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Trained on %d domains, %d trigrams\n", m.Labels, len(m.Trigrams))
+	return f.Close()
+}
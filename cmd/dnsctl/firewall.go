@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/firewall"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+func runFirewall(e *env, args []string) error {
+	fs := flag.NewFlagSet("firewall", flag.ContinueOnError)
+	x := &firewallExport{}
+	fs.StringVar(&x.format, "format", firewall.FormatNftables, "rule set format: "+strings.Join(firewall.Formats, ", "))
+	fs.StringVar(&x.name, "name", firewall.DefaultName, "name of the nftables table, ipset sets or iptables chain")
+	fs.StringVar(&x.out, "out", "-", "file replaced atomically with the rule set, - for stdout")
+	fs.StringVar(&x.reload, "exec", "", "shell command run after each rewrite, e.g. \"nft -f /etc/nftables.d/blocklist.nft\"")
+	watch := fs.Bool("watch", false, "keep running and rewrite the rule set when the blocks change")
+	debounce := fs.Duration("debounce", time.Second, "with -watch, wait for the changes to settle this long before rewriting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || (*watch && x.out == "-") {
+		return errUsage
+	}
+	x.stdout = e.out
+	if _, err := firewall.Render(x.format, x.name, nil, time.Now()); err != nil {
+		return err
+	}
+
+	if !*watch {
+		ctx, cancel := e.context()
+		defer cancel()
+		if err := x.load(ctx, e.client); err != nil {
+			return err
+		}
+		return x.write(time.Now())
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return x.watch(ctx, e, *debounce)
+}
+
+// firewallExport renders the blocks enforceable by a firewall: the
+// blacklisted IPs, and the block rules of a source for every domain and
+// query type.
+type firewallExport struct {
+	format, name, out, reload string
+	stdout                    io.Writer
+	// entries are keyed like the blockJournal of the server, "ip:<ip>"
+	// or "rule:<id>".
+	entries map[string]firewall.Entry
+	// snapshot is true while receiving the snapshot of a WatchBlocks
+	// stream.
+	snapshot bool
+}
+
+// load replaces the entries with the current blocks of the server.
+func (x *firewallExport) load(ctx context.Context, client pb.DnsServiceClient) error {
+	blocked, err := client.ListBlockedIps(ctx, &pb.ListBlockedIpsRequest{})
+	if err != nil {
+		return err
+	}
+	rules, err := client.ListBlockRules(ctx, &pb.ListBlockRulesRequest{})
+	if err != nil {
+		return err
+	}
+	x.entries = make(map[string]firewall.Entry)
+	now := time.Now()
+	for _, b := range blocked.GetBlockedIps() {
+		x.add(&pb.WatchBlocksResponse{BlockedIp: b}, now)
+	}
+	for _, r := range rules.GetRules() {
+		x.add(&pb.WatchBlocksResponse{Rule: r}, now)
+	}
+	return nil
+}
+
+// apply updates the entries with a WatchBlocks event and tells whether the
+// rule set may have changed.
+func (x *firewallExport) apply(ev *pb.WatchBlocksResponse, now time.Time) bool {
+	switch ev.GetType() {
+	case pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT:
+		if !x.snapshot {
+			// A new snapshot replaces every block known
+			x.entries = make(map[string]firewall.Entry)
+			x.snapshot = true
+		}
+		x.add(ev, now)
+		return false
+	case pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT_END:
+		if !x.snapshot {
+			// An empty snapshot
+			x.entries = make(map[string]firewall.Entry)
+		}
+		x.snapshot = false
+		return true
+	case pb.BlockEventType_BLOCK_EVENT_TYPE_ADDED:
+		// A replaced rule may no longer be enforceable
+		delete(x.entries, eventKey(ev))
+		x.add(ev, now)
+		return true
+	case pb.BlockEventType_BLOCK_EVENT_TYPE_REMOVED, pb.BlockEventType_BLOCK_EVENT_TYPE_EXPIRED:
+		_, ok := x.entries[eventKey(ev)]
+		delete(x.entries, eventKey(ev))
+		return ok
+	}
+	return false
+}
+
+func (x *firewallExport) add(ev *pb.WatchBlocksResponse, now time.Time) {
+	source, reason, ttl := "", "", int64(-1)
+	if b := ev.GetBlockedIp(); b != nil {
+		source, reason, ttl = b.GetIpAddress(), b.GetReason(), b.GetTtlSeconds()
+	} else if r := ev.GetRule(); r != nil {
+		if r.GetDomainPattern() != "" || r.GetQueryType() != pb.QueryType_QUERY_TYPE_UNKNOWN {
+			// Firewalls do not see the domains nor the query types
+			return
+		}
+		source, reason, ttl = r.GetSource(), r.GetReason(), r.GetTtlSeconds()
+	}
+	var expiresAt time.Time
+	if ttl >= 0 {
+		expiresAt = now.Add(time.Duration(ttl) * time.Second)
+	}
+	entry, err := firewall.ParseEntry(source, reason, expiresAt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dnsctl firewall: skipping block: %v\n", err)
+		return
+	}
+	x.entries[eventKey(ev)] = entry
+}
+
+func eventKey(ev *pb.WatchBlocksResponse) string {
+	if b := ev.GetBlockedIp(); b != nil {
+		return "ip:" + b.GetIpAddress()
+	}
+	return "rule:" + ev.GetRule().GetId()
+}
+
+// write renders the entries to the output and runs the -exec command.
+func (x *firewallExport) write(now time.Time) error {
+	entries := make([]firewall.Entry, 0, len(x.entries))
+	for _, entry := range x.entries {
+		entries = append(entries, entry)
+	}
+	data, err := firewall.Render(x.format, x.name, entries, now)
+	if err != nil {
+		return err
+	}
+	if x.out == "-" {
+		_, err = x.stdout.Write(data)
+		return err
+	}
+	if err := firewall.WriteFile(x.out, data); err != nil {
+		return err
+	}
+	if x.reload == "" {
+		return nil
+	}
+	cmd := exec.Command("sh", "-c", x.reload)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", x.reload, err)
+	}
+	return nil
+}
+
+// watch rewrites the rule set whenever the blocks change, until ctx is done.
+// Lost streams are resumed from the last resume token received.
+func (x *firewallExport) watch(ctx context.Context, e *env, debounce time.Duration) error {
+	x.entries = make(map[string]firewall.Entry)
+	token := ""
+	backoff := time.Second
+	for {
+		last := token
+		err := x.follow(ctx, e, &token, debounce)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if token != last {
+			// The stream worked for a while
+			backoff = time.Second
+		}
+		fmt.Fprintf(os.Stderr, "dnsctl firewall: watch interrupted, retrying in %s: %v\n", backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+// follow applies the events of one WatchBlocks stream, writing the rule set
+// debounce after a change, so that bursts of changes are written once.
+func (x *firewallExport) follow(ctx context.Context, e *env, token *string, debounce time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := e.client.WatchBlocks(e.outgoing(ctx), &pb.WatchBlocksRequest{ResumeToken: *token})
+	if err != nil {
+		return err
+	}
+	events := make(chan *pb.WatchBlocksResponse)
+	failed := make(chan error, 1)
+	go func() {
+		for {
+			ev, err := stream.Recv()
+			if err != nil {
+				failed <- err
+				return
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var flush <-chan time.Time
+	x.snapshot = false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-failed:
+			if flush != nil {
+				if err := x.write(time.Now()); err != nil {
+					fmt.Fprintf(os.Stderr, "dnsctl firewall: failed to write the rule set: %v\n", err)
+				}
+			}
+			return err
+		case <-flush:
+			flush = nil
+			if err := x.write(time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "dnsctl firewall: failed to write the rule set: %v\n", err)
+				// Retry with the next change
+			}
+		case ev := <-events:
+			if ev.GetResumeToken() != "" {
+				*token = ev.GetResumeToken()
+			}
+			if x.apply(ev, time.Now()) && flush == nil {
+				flush = time.After(debounce)
+			}
+		}
+	}
+}
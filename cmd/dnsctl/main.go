@@ -0,0 +1,150 @@
+// Command dnsctl is the operator tool for the DNS-Stream-Analyzer gRPC server.
+//
+// Usage:
+//
+//	dnsctl [-addr host:port] [-tenant id] [-token t] [-o table|json|csv] [-timeout 5s] <command> [args]
+//
+// Run "dnsctl help" for the list of commands.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const defaultAddress = "localhost:50051" // Port published by compose.yml
+
+// env is the state shared by every command.
+type env struct {
+	client  pb.DnsServiceClient
+	out     io.Writer
+	format  string
+	timeout time.Duration
+	// tenant and token select the tenant of the requests, see
+	// internal/tenant.
+	tenant string
+	token  string
+}
+
+// context returns a context bounded by the -timeout flag.
+func (e *env) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(e.outgoing(context.Background()), e.timeout)
+}
+
+// outgoing returns ctx with the tenant metadata of the -tenant and -token
+// flags.
+func (e *env) outgoing(ctx context.Context) context.Context {
+	md := metadata.MD{}
+	if e.tenant != "" {
+		md.Set(tenant.MetadataKey, e.tenant)
+	}
+	if e.token != "" {
+		md.Set("authorization", "Bearer "+e.token)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+type command struct {
+	usage string
+	help  string
+	run   func(e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"block":     {"block [-reason text] [-ttl 1h] <ip>...", "Blacklist one or more IPs", runBlock},
+	"unblock":   {"unblock <ip>...", "Remove one or more IPs from the blacklist", runUnblock},
+	"list":      {"list", "List blacklisted IPs", runList},
+	"check":     {"check <ip>", "Tell whether an IP is blacklisted", runCheck},
+	"allow":     {"allow [-reason text] <ip|cidr|domain>...", "Protect IPs, CIDRs or domain suffixes from blocks", runAllow},
+	"disallow":  {"disallow <ip|cidr|domain>...", "Remove entries from the allowlist", runDisallow},
+	"allowlist": {"allowlist", "List the allowlist", runAllowlist},
+	"block-rule": {"block-rule [-domain d|*.d] [-type TXT] [-reason text] [-ttl 1h] [<ip|cidr>...]",
+		"Block sources for some domains or query types only", runBlockRule},
+	"unblock-rule": {"unblock-rule <id>...", "Remove block rules", runUnblockRule},
+	"block-rules":  {"block-rules", "List the block rules", runBlockRules},
+	"dga-train":    {"dga-train [-in path] [-out path]", "Train the DGA model of the consumer on a list of benign domains", runDGATrain},
+	"export":       {"export [-format jsonl|csv] [-file path]", "Back up the blacklist with reasons and remaining TTLs", runExport},
+	"firewall":     {"firewall [-format nftables|ipset|iptables|ip6tables] [-out path] [-watch] [-exec cmd]", "Render the blacklist as a firewall rule set", runFirewall},
+	"explain":      {"explain -ip <ip> -domain <domain> [-type A]", "Explain the verdict of a DNS request without sending it", runExplain},
+	"send":         {"send -ip <ip> -domain <domain> [-type A] [-rcode NXDOMAIN]", "Send a DNS request to the server", runSend},
+	"import":       {"import [-file path]", "Blacklist every IP of a file (one per line or CSV, - for stdin)", runImport},
+	"stats":        {"stats", "Show server counters", runStats},
+	"tail":         {"tail [-n count]", "Stream DNS requests processed by the server", runTail},
+	"watch":        {"watch [-resume token] [-n count]", "Stream the blocked IPs and block rules, then their changes", runWatch},
+	"restore":      {"restore [-format jsonl|csv] [-mode merge|replace] [-dry-run] [-file path]", "Import a blacklist backup, or show the changes it would make", runRestore},
+	"replay":       {"replay [-brokers b] [-topic t] [-n count] [-dry-run]", "Re-inject dead letters on their original topic", runReplay},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: dnsctl [flags] <command> [args]\n\nFlags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-45s %s\n", commands[name].usage, commands[name].help)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	addr := flag.String("addr", getEnv("DNSCTL_ADDR", defaultAddress), "gRPC server address (env DNSCTL_ADDR)")
+	format := flag.String("o", "table", "output format: table, json or csv")
+	timeout := flag.Duration("timeout", 5*time.Second, "timeout of each RPC")
+	tenantID := flag.String("tenant", getEnv("DNSCTL_TENANT", ""), "tenant of the requests, the default tenant if empty (env DNSCTL_TENANT)")
+	token := flag.String("token", getEnv("DNSCTL_TOKEN", ""), "bearer token authenticating the tenant (env DNSCTL_TOKEN)")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 || flag.Arg(0) == "help" {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "dnsctl: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dnsctl: failed to connect to %s: %v\n", *addr, err)
+		os.Exit(1)
+	}
+
+	e := &env{
+		client:  pb.NewDnsServiceClient(conn),
+		out:     os.Stdout,
+		format:  *format,
+		timeout: *timeout,
+		tenant:  *tenantID,
+		token:   *token,
+	}
+	err = cmd.run(e, flag.Args()[1:])
+	conn.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dnsctl %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/deadletter"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/firewall"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrinterFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"table", "ip_address    blocked\n192.168.1.70  true\n"},
+		{"json", "{\"blocked\":true,\"ip_address\":\"192.168.1.70\"}\n"},
+		{"csv", "ip_address,blocked\n192.168.1.70,true\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			p, err := newPrinter(&buf, tt.format, "ip_address", "blocked")
+			assert.NoError(t, err)
+			assert.NoError(t, p.Row("192.168.1.70", true))
+			assert.NoError(t, p.Flush())
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	_, err := newPrinter(&bytes.Buffer{}, "yaml")
+	assert.Error(t, err)
+}
+
+func TestReadIPs(t *testing.T) {
+	input := `ip_address,reason
+# comment
+192.168.1.70,scanner
+
+10.0.0.1
+`
+	ips, err := readIPs(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.70", "10.0.0.1"}, ips)
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	memory := bus.NewMemory(1)
+	setup := memory.Publisher()
+	for _, ip := range []string{"192.168.1.70", "10.0.0.1"} {
+		original := &bus.Message{Topic: "myTopic", Key: []byte(ip), Value: []byte("IP: " + ip)}
+		require.NoError(t, setup.Publish(deadletter.New("myTopic.dlq", original, "server", errors.New("broker down"))))
+	}
+	// A dead letter without its original topic is reported, not replayed.
+	require.NoError(t, setup.Publish(&bus.Message{Topic: "myTopic.dlq", Key: []byte("10.0.0.9")}))
+
+	events := memory.Subscriber("detectors")
+	require.NoError(t, events.Subscribe([]string{"myTopic"}))
+	replay := func(dryRun bool) string {
+		subscriber := memory.Subscriber("dnsctl-replay", bus.ManualCommit())
+		defer subscriber.Close()
+		require.NoError(t, subscriber.Subscribe([]string{"myTopic.dlq"}))
+		var buf bytes.Buffer
+		e := &env{out: &buf, format: "csv", timeout: time.Second}
+		opts := replayOptions{wait: 50 * time.Millisecond, timeout: time.Second, dryRun: dryRun}
+		require.NoError(t, replayDeadLetters(e, subscriber, memory.Publisher(), opts))
+		return buf.String()
+	}
+
+	// A dry run lists the dead letters and leaves them in place.
+	out := replay(true)
+	assert.Contains(t, out, "myTopic,0,0,1,server,broker down,dry-run")
+	_, err := events.Read(50 * time.Millisecond)
+	assert.ErrorIs(t, err, bus.ErrTimeout)
+
+	assert.Contains(t, out, ",,,0,,,malformed")
+	out = replay(false)
+	assert.Equal(t, 4, strings.Count(out, "\n"))
+	assert.Contains(t, out, "replayed")
+	assert.Contains(t, out, ",,,0,,,malformed")
+	for _, ip := range []string{"192.168.1.70", "10.0.0.1"} {
+		msg, err := events.Read(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, ip, string(msg.Key))
+		assert.Equal(t, 1, deadletter.Attempts(msg))
+	}
+
+	// Replayed dead letters were committed.
+	assert.Equal(t, "original_topic,original_partition,original_offset,attempts,source,error,status\n", replay(false))
+}
+
+func TestFirewallExport(t *testing.T) {
+	now := time.Now()
+	x := &firewallExport{format: firewall.FormatIptables, name: "bl", out: filepath.Join(t.TempDir(), "bl.rules")}
+	ip := func(t pb.BlockEventType, addr string) *pb.WatchBlocksResponse {
+		return &pb.WatchBlocksResponse{Type: t, BlockedIp: &pb.BlockedIp{IpAddress: addr, TtlSeconds: -1}}
+	}
+	rule := func(t pb.BlockEventType, r *pb.BlockRule) *pb.WatchBlocksResponse {
+		r.TtlSeconds = -1
+		return &pb.WatchBlocksResponse{Type: t, Rule: r}
+	}
+	read := func() string {
+		data, err := os.ReadFile(x.out)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.False(t, x.apply(ip(pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT, "10.0.0.1"), now))
+	// Rules scoped to domains or query types cannot be enforced by a
+	// firewall
+	assert.False(t, x.apply(rule(pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT, &pb.BlockRule{Id: "dns", Source: "10.1.0.0/16", DomainPattern: "*.evil.com"}), now))
+	assert.True(t, x.apply(&pb.WatchBlocksResponse{Type: pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT_END}, now))
+	require.NoError(t, x.write(now))
+	assert.Contains(t, read(), "-A BL -s 10.0.0.1/32 -j DROP\n")
+	assert.NotContains(t, read(), "10.1.0.0/16")
+
+	assert.True(t, x.apply(rule(pb.BlockEventType_BLOCK_EVENT_TYPE_ADDED, &pb.BlockRule{Id: "net", Source: "192.168.0.0/16"}), now))
+	assert.True(t, x.apply(ip(pb.BlockEventType_BLOCK_EVENT_TYPE_REMOVED, "10.0.0.1"), now))
+	assert.False(t, x.apply(rule(pb.BlockEventType_BLOCK_EVENT_TYPE_EXPIRED, &pb.BlockRule{Id: "dns"}), now))
+	require.NoError(t, x.write(now))
+	assert.Contains(t, read(), "-A BL -s 192.168.0.0/16 -j DROP\n")
+	assert.NotContains(t, read(), "10.0.0.1")
+
+	// A new snapshot replaces the blocks known
+	x.apply(ip(pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT, "10.0.0.2"), now)
+	x.apply(&pb.WatchBlocksResponse{Type: pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT_END}, now)
+	require.NoError(t, x.write(now))
+	assert.Contains(t, read(), "10.0.0.2/32")
+	assert.NotContains(t, read(), "192.168.0.0/16")
+}
+
+func TestBlocklistFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format, file string
+		want         pb.BlocklistFormat
+	}{
+		{"", "backup.jsonl", pb.BlocklistFormat_BLOCKLIST_FORMAT_JSONL},
+		{"", "backup.CSV", pb.BlocklistFormat_BLOCKLIST_FORMAT_CSV},
+		{"", "-", pb.BlocklistFormat_BLOCKLIST_FORMAT_JSONL},
+		{"csv", "-", pb.BlocklistFormat_BLOCKLIST_FORMAT_CSV},
+	} {
+		got, err := blocklistFormat(tt.format, tt.file)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%+v", tt)
+	}
+	_, err := blocklistFormat("xml", "-")
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// printer renders rows of a command result in one of the output formats.
+type printer interface {
+	Row(values ...any) error
+	Flush() error
+}
+
+// newPrinter returns a printer for format ("table", "json" or "csv") writing
+// rows with the given column names to w.
+func newPrinter(w io.Writer, format string, columns ...string) (printer, error) {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		p := &tablePrinter{w: tw}
+		return p, p.Row(toAny(columns)...)
+	case "json":
+		return &jsonPrinter{enc: json.NewEncoder(w), columns: columns}, nil
+	case "csv":
+		p := &csvPrinter{w: csv.NewWriter(w)}
+		return p, p.Row(toAny(columns)...)
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected table, json or csv", format)
+	}
+}
+
+func toAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+type tablePrinter struct {
+	w *tabwriter.Writer
+}
+
+func (p *tablePrinter) Row(values ...any) error {
+	for i, v := range values {
+		sep := "\t"
+		if i == len(values)-1 {
+			sep = "\n"
+		}
+		if _, err := fmt.Fprintf(p.w, "%v%s", v, sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *tablePrinter) Flush() error {
+	return p.w.Flush()
+}
+
+// jsonPrinter writes one JSON object per row (JSON Lines) so that streaming
+// commands such as tail can be piped into jq.
+type jsonPrinter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func (p *jsonPrinter) Row(values ...any) error {
+	obj := make(map[string]any, len(values))
+	for i, v := range values {
+		obj[p.columns[i]] = v
+	}
+	return p.enc.Encode(obj)
+}
+
+func (p *jsonPrinter) Flush() error {
+	return nil
+}
+
+type csvPrinter struct {
+	w *csv.Writer
+}
+
+func (p *csvPrinter) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = fmt.Sprint(v)
+	}
+	return p.w.Write(record)
+}
+
+func (p *csvPrinter) Flush() error {
+	p.w.Flush()
+	return p.w.Error()
+}
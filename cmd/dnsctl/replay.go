@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/deadletter"
+)
+
+func runReplay(e *env, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	brokers := fs.String("brokers", getEnv("KAFKA_BOOTSTRAP_SERVERS", "localhost:9092"), "Kafka brokers (env KAFKA_BOOTSTRAP_SERVERS)")
+	topic := fs.String("topic", "myTopic.dlq", "dead-letter topic")
+	group := fs.String("group", "dnsctl-replay", "consumer group recording the replayed dead letters")
+	opts := replayOptions{}
+	fs.IntVar(&opts.count, "n", 0, "stop after this many dead letters, 0 for all")
+	fs.DurationVar(&opts.wait, "wait", 5*time.Second, "stop when no dead letter arrives for this long")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "list the dead letters without replaying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts.timeout = e.timeout
+
+	subscriber, err := bus.NewKafkaSubscriber(*brokers, *group, bus.ManualCommit())
+	if err != nil {
+		return err
+	}
+	defer subscriber.Close()
+	if err := subscriber.Subscribe([]string{*topic}); err != nil {
+		return err
+	}
+	publisher, err := bus.NewKafkaPublisher(*brokers)
+	if err != nil {
+		return err
+	}
+	defer publisher.Close()
+
+	return replayDeadLetters(e, subscriber, publisher, opts)
+}
+
+type replayOptions struct {
+	count   int
+	wait    time.Duration
+	timeout time.Duration
+	dryRun  bool
+}
+
+// replayDeadLetters re-injects the dead letters read from subscriber on their
+// original topic. A dead letter is committed only once its event has been
+// delivered, so an interrupted replay resumes where it stopped.
+func replayDeadLetters(e *env, subscriber bus.Subscriber, publisher bus.Publisher, opts replayOptions) error {
+	p, err := newPrinter(e.out, e.format, "original_topic", "original_partition", "original_offset", "attempts", "source", "error", "status")
+	if err != nil {
+		return err
+	}
+	defer p.Flush()
+
+	for i := 0; opts.count == 0 || i < opts.count; i++ {
+		msg, err := subscriber.Read(opts.wait)
+		if err == bus.ErrTimeout {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		status := "dry-run"
+		switch {
+		case deadletter.Header(msg, deadletter.HeaderTopic) == "":
+			// Dead letters without an original topic cannot be replayed;
+			// they are reported and committed so that they do not stop
+			// this run nor the next ones.
+			status = "malformed"
+			if !opts.dryRun {
+				if err := subscriber.Commit(msg); err != nil {
+					return fmt.Errorf("dead letter %s[%d]@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+				}
+			}
+		case !opts.dryRun:
+			if err := replayOne(subscriber, publisher, msg, opts.timeout); err != nil {
+				return fmt.Errorf("dead letter %s[%d]@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+			}
+			status = "replayed"
+		}
+		err = p.Row(
+			deadletter.Header(msg, deadletter.HeaderTopic),
+			deadletter.Header(msg, deadletter.HeaderPartition),
+			deadletter.Header(msg, deadletter.HeaderOffset),
+			deadletter.Attempts(msg),
+			deadletter.Header(msg, deadletter.HeaderSource),
+			deadletter.Header(msg, deadletter.HeaderError),
+			status,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayOne publishes the event of the dead letter msg, waits for its
+// delivery and commits msg.
+func replayOne(subscriber bus.Subscriber, publisher bus.Publisher, msg *bus.Message, timeout time.Duration) error {
+	original, err := deadletter.Original(msg)
+	if err != nil {
+		return err
+	}
+	if err := publisher.Publish(original); err != nil {
+		return err
+	}
+	select {
+	case d := <-publisher.Deliveries():
+		if d.Err != nil {
+			return d.Err
+		}
+	case <-time.After(timeout):
+		return fmt.Errorf("no delivery report after %v", timeout)
+	}
+	return subscriber.Commit(msg)
+}
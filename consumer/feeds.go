@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ioc"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+// loadFeed reads the indicators of feed and swaps them in. On failure the
+// indicators read before stay active. It returns the content of the file,
+// nil if it cannot be read.
+func (c *consumer) loadFeed(feed ioc.Feed) ([]byte, error) {
+	data, err := os.ReadFile(feed.Path)
+	if err != nil {
+		stats.Add("feed_refresh_failed", 1)
+		log.Printf("Failed to read feed %s, keeping its previous indicators: %v", feed.Name, err)
+		return nil, err
+	}
+	indicators, skipped, err := ioc.Parse(bytes.NewReader(data), feed.Format)
+	if err != nil {
+		stats.Add("feed_refresh_failed", 1)
+		log.Printf("Rejected feed %s, keeping its previous indicators: %v", feed.Name, err)
+		return data, err
+	}
+	c.feeds.Set(feed, indicators, time.Now())
+	stats.Add("feed_refreshes", 1)
+	log.Printf("Loaded feed %s from %s: %d indicators, %d entries skipped", feed.Name, feed.Path, len(indicators), skipped)
+	return data, nil
+}
+
+// watchFeed reloads feed whenever the content of its file changes, checking
+// every feed.Refresh until ctx is done. last is the content loaded at
+// startup.
+func (c *consumer) watchFeed(ctx context.Context, feed ioc.Feed, last []byte) {
+	ticker := time.NewTicker(feed.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		data, err := os.ReadFile(feed.Path)
+		if err == nil && bytes.Equal(data, last) {
+			continue
+		}
+		last, _ = c.loadFeed(feed)
+	}
+}
+
+// matchFeeds blocks the source of req if it matches the indicators of a
+// feed, on behalf of the tenant id.
+func (c *consumer) matchFeeds(ctx context.Context, id string, req *pb.DnsRequest) {
+	if c.feeds == nil {
+		return
+	}
+	ip := req.GetIpAddress()
+	for _, m := range c.feeds.Match(req) {
+		stats.Add("feed_matches", 1)
+		block := &pb.BlockIpRequest{
+			IpAddress:  ip,
+			Reason:     m.BlockReason(),
+			TtlSeconds: int64(m.Feed.Duration.Seconds()),
+		}
+		if _, err := c.client.BlockIp(c.outgoing(ctx, id), block); err != nil {
+			stats.Add("block_requests_failed", 1)
+			log.Printf("Failed to send block IP request for tenant %s: %v", id, err)
+		} else {
+			stats.Add("block_requests", 1)
+			log.Printf("Sent block IP request for IP: %s (tenant %s, feed %s, indicator %s on %s)", ip, id, m.Feed.Name, m.Indicator.ID, m.Field)
+		}
+	}
+}
+
+// feedStats registers the statistics of the feeds in stats: the indicators,
+// load time and hit counts per indicator of each feed.
+func (c *consumer) feedStats() {
+	stats.Set("feeds", expvar.Func(func() any {
+		if c.feeds == nil {
+			return map[string]ioc.FeedStats{}
+		}
+		return c.feeds.Stats()
+	}))
+}
@@ -4,13 +4,23 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/deadletter"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dga"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/event"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ioc"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/rules"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
 	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
-	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
@@ -19,20 +29,165 @@ const (
 
 var topic string = "myTopic"
 
-func extractIP(message string) string {
-	// Extract IP from the message
-	// Assuming the message format is "IP: <ip>, Domain: <domain>, QueryType: <query_type>, Timestamp: <timestamp>"
-	parts := strings.Split(message, ",")
-	for _, part := range parts {
-		if strings.HasPrefix(part, "IP: ") {
-			return strings.TrimSpace(strings.TrimPrefix(part, "IP: "))
+// detectorRules returns the rules of the detector settings d: a rule
+// blocking the IPs ending with one of the malicious suffixes
+func detectorRules(d tenant.Detector) (*rules.Set, error) {
+	if len(d.MaliciousSuffixes) == 0 {
+		return rules.New(nil)
+	}
+	conds := make([]string, len(d.MaliciousSuffixes))
+	for i, suffix := range d.MaliciousSuffixes {
+		conds[i] = fmt.Sprintf("endsWith(ip, %s)", strconv.Quote(suffix))
+	}
+	return rules.New([]rules.Rule{{
+		ID:       "malicious-suffix",
+		Duration: d.BlockTTL,
+		Reason:   d.BlockReason,
+		When:     strings.Join(conds, " || "),
+	}})
+}
+
+// consumer analyzes the DNS requests read from the event bus and asks the
+// server to block malicious IPs
+type consumer struct {
+	subscriber bus.Subscriber
+	client     pb.DnsServiceClient
+	// deadLetters publishes the messages that cannot be parsed to
+	// deadLetterTopic
+	deadLetters     bus.Publisher
+	deadLetterTopic string
+	// tenants holds the detector settings and tokens of the tenants, nil
+	// when only the default tenant exists
+	tenants *tenant.Config
+	// rules are the detection rules of every tenant, swapped on reload.
+	// When nil, the rules of the detector settings of each tenant, kept in
+	// detectors, apply.
+	rules     atomic.Pointer[rules.Set]
+	detectors map[string]*rules.Set
+	// feeds holds the indicators of the threat intelligence feeds, nil
+	// when there are none
+	feeds *ioc.Matcher
+}
+
+// handle analyzes a single message
+func (c *consumer) handle(ctx context.Context, msg *bus.Message) {
+	fmt.Printf("Message on %s[%d]@%d: %s\n", msg.Topic, msg.Partition, msg.Offset, string(msg.Value))
+
+	// Analyze the message
+	req, err := event.Decode(msg.Value)
+	if err != nil {
+		c.deadLetter(msg, err)
+		return
+	}
+	id := messageTenant(msg)
+	c.matchFeeds(ctx, id, req)
+	set, err := c.ruleSet(id)
+	if err != nil {
+		log.Printf("Invalid detector settings for tenant %s: %v", id, err)
+		return
+	}
+	ip := req.GetIpAddress()
+	for _, rule := range set.Eval(id, req, eventTime(req)) {
+		stats.Add("rule_matches", 1)
+		if rule.Action != rules.ActionBlock {
+			log.Printf("Rule %s (%s) matched IP: %s (tenant %s)", rule.ID, rule.Severity, ip, id)
+			continue
+		}
+		// Send block request to the server, on behalf of the tenant
+		req := &pb.BlockIpRequest{
+			IpAddress:  ip,
+			Reason:     rule.BlockReason(),
+			TtlSeconds: int64(rule.Duration.Seconds()),
+		}
+		_, err := c.client.BlockIp(c.outgoing(ctx, id), req)
+		if err != nil {
+			stats.Add("block_requests_failed", 1)
+			log.Printf("Failed to send block IP request for tenant %s: %v", id, err)
+		} else {
+			stats.Add("block_requests", 1)
+			log.Printf("Sent block IP request for IP: %s (tenant %s, rule %s)", ip, id, rule.ID)
+		}
+	}
+}
+
+// ruleSet returns the detection rules of the tenant id
+func (c *consumer) ruleSet(id string) (*rules.Set, error) {
+	if set := c.rules.Load(); set != nil {
+		return set, nil
+	}
+	if set, ok := c.detectors[id]; ok {
+		return set, nil
+	}
+	set, err := detectorRules(c.tenants.Detector(id))
+	if err != nil {
+		return nil, err
+	}
+	if c.detectors == nil {
+		c.detectors = make(map[string]*rules.Set)
+	}
+	c.detectors[id] = set
+	return set, nil
+}
+
+// eventTime returns the time of req, now for events without a timestamp
+func eventTime(req *pb.DnsRequest) time.Time {
+	if req.GetTimestamp() > 0 {
+		return time.Unix(req.GetTimestamp(), 0)
+	}
+	return time.Now()
+}
+
+// messageTenant returns the tenant of msg, from its tenant header
+func messageTenant(msg *bus.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == tenant.HeaderKey && len(h.Value) > 0 {
+			return string(h.Value)
 		}
 	}
-	return ""
+	return tenant.Default
 }
 
-func isMalicious(ip string) bool {
-	return strings.HasSuffix(ip, "70")
+// outgoing returns ctx with the metadata selecting the tenant id on the
+// server, and its token if it has any
+func (c *consumer) outgoing(ctx context.Context, id string) context.Context {
+	md := metadata.Pairs(tenant.MetadataKey, id)
+	if t, ok := c.tenants.Lookup(id); ok && len(t.Tokens) > 0 {
+		md.Set("authorization", "Bearer "+t.Tokens[0])
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// deadLetter publishes msg, which failed with err, to the dead-letter topic
+func (c *consumer) deadLetter(msg *bus.Message, err error) {
+	stats.Add("dead_letters", 1)
+	log.Printf("Dead-lettering message %s[%d]@%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+	if err := c.deadLetters.Publish(deadletter.New(c.deadLetterTopic, msg, "consumer", err)); err != nil {
+		log.Printf("Failed to dead-letter message: %v", err)
+	}
+}
+
+// run reads and handles messages until ctx is done
+func (c *consumer) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		msg, err := c.subscriber.Read(time.Second)
+		if err == nil {
+			c.handle(ctx, msg)
+		} else if err != bus.ErrTimeout {
+			// The client will automatically try to recover from all errors.
+			// Timeout is not considered an error because it is raised by
+			// Read in absence of messages.
+			fmt.Printf("Consumer error: %v\n", err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
 }
 
 func main() {
@@ -40,22 +195,49 @@ func main() {
 	time.Sleep(10 * time.Second)
 
 	// Kafka consumer setup
-	c, err := kafka.NewConsumer(&kafka.ConfigMap{
-		"bootstrap.servers": "broker:9092",
-		"group.id":          "myGroup",
-		"auto.offset.reset": "earliest",
-	})
-
+	bootstrapServers := getEnv("KAFKA_BOOTSTRAP_SERVERS", "broker:9092")
+	subscriber, err := bus.NewKafkaSubscriber(bootstrapServers, "myGroup")
 	if err != nil {
 		panic(err)
 	}
+	defer subscriber.Close()
+
+	var tenants *tenant.Config
+	if path := getEnv("TENANTS_CONFIG", ""); path != "" {
+		if tenants, err = tenant.Load(path); err != nil {
+			log.Fatalf("Failed to load tenants: %v", err)
+		}
+	}
 
-	err = c.SubscribeTopics([]string{topic, "^aRegex.*[Tt]opic"}, nil)
+	// Subscribe to the shared topic and to the topics of the tenants
+	subscriptions := []string{topic, "^aRegex.*[Tt]opic"}
+	subscribed := map[string]bool{topic: true}
+	for _, id := range tenants.IDs() {
+		if t := tenants.Topic(id, topic); !subscribed[t] {
+			subscribed[t] = true
+			subscriptions = append(subscriptions, t)
+		}
+	}
+	err = subscriber.Subscribe(subscriptions)
 
 	if err != nil {
 		panic(err)
 	}
 
+	// Dead-letter producer setup
+	deadLetters, err := bus.NewKafkaPublisher(bootstrapServers)
+	if err != nil {
+		log.Fatalf("Failed to create dead-letter producer: %v", err)
+	}
+	defer deadLetters.Close()
+	go func() {
+		for d := range deadLetters.Deliveries() {
+			if d.Err != nil {
+				log.Printf("Dead letter lost: %v", d.Err)
+			}
+		}
+	}()
+
 	// Connect to the gRPC server
 	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -63,33 +245,71 @@ func main() {
 	}
 	defer conn.Close()
 
-	client := pb.NewDnsServiceClient(conn)
-
-	for {
-		// Kafka consumer test
-		msg, err := c.ReadMessage(time.Second)
-		if err == nil {
-			fmt.Printf("Message on %s: %s\n", msg.TopicPartition, string(msg.Value))
-
-			// Analyze the message
-			ip := extractIP(string(msg.Value))
-			if isMalicious(ip) {
-				// Send block request to the server
-				req := &pb.BlockIpRequest{IpAddress: ip}
-				_, err := client.BlockIp(context.Background(), req)
-				if err != nil {
-					log.Printf("Failed to send block IP request: %v", err)
-				} else {
-					log.Printf("Sent block IP request for IP: %s", ip)
-				}
+	c := &consumer{
+		subscriber:      subscriber,
+		client:          pb.NewDnsServiceClient(conn),
+		deadLetters:     deadLetters,
+		deadLetterTopic: getEnv("DEAD_LETTER_TOPIC", topic+".dlq"),
+		tenants:         tenants,
+	}
+	c.ruleStats()
+	c.feedStats()
+	if path := getEnv("DGA_MODEL", ""); path != "" {
+		model, err := dga.Load(path)
+		if err != nil {
+			log.Fatalf("Failed to load DGA model: %v", err)
+		}
+		dga.SetDefault(model)
+		log.Printf("Loaded the DGA model of %s, trained on %d domains", path, model.Labels)
+	}
+	ctx := context.Background()
+	if path := getEnv("IOC_FEEDS", ""); path != "" {
+		feeds, err := ioc.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("Failed to load feeds: %v", err)
+		}
+		// Feeds missing at startup are read on their next refresh
+		c.feeds = ioc.NewMatcher()
+		for _, feed := range feeds {
+			data, _ := c.loadFeed(feed)
+			if feed.Refresh > 0 {
+				go c.watchFeed(ctx, feed, data)
 			}
-		} else if !err.(kafka.Error).IsTimeout() {
-			// The client will automatically try to recover from all errors.
-			// Timeout is not considered an error because it is raised by
-			// ReadMessage in absence of messages.
-			fmt.Printf("Consumer error: %v (%v)\n", err, msg)
 		}
+	}
+	serveMetrics(getEnv("METRICS_ADDR", ":9102"))
 
-		time.Sleep(100 * time.Millisecond)
+	// Detection rules, reloaded when their file or topic changes
+	rulesFile, rulesTopic := getEnv("RULES_CONFIG", ""), getEnv("RULES_TOPIC", "")
+	if rulesFile != "" && rulesTopic != "" {
+		log.Fatalf("RULES_CONFIG and RULES_TOPIC are exclusive")
+	}
+	if rulesFile != "" {
+		data, err := os.ReadFile(rulesFile)
+		if err != nil {
+			log.Fatalf("Failed to read detection rules: %v", err)
+		}
+		if err := c.loadRules(data, rulesFile); err != nil {
+			log.Fatalf("Failed to load detection rules: %v", err)
+		}
+		interval, err := time.ParseDuration(getEnv("RULES_RELOAD_INTERVAL", "10s"))
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid RULES_RELOAD_INTERVAL")
+		}
+		go c.watchRulesFile(ctx, rulesFile, data, interval)
+	}
+	if rulesTopic != "" {
+		// Every consumer reads the whole topic, from the start, outside
+		// of any consumer group
+		rulesSubscriber, err := bus.NewKafkaReader(bootstrapServers)
+		if err != nil {
+			log.Fatalf("Failed to create detection rules consumer: %v", err)
+		}
+		defer rulesSubscriber.Close()
+		if err := rulesSubscriber.Subscribe([]string{rulesTopic}); err != nil {
+			log.Fatalf("Failed to subscribe to %s: %v", rulesTopic, err)
+		}
+		go c.watchRulesTopic(ctx, rulesSubscriber)
 	}
+	c.run(ctx)
 }
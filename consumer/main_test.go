@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/deadletter"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/event"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ioc"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/rules"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/simulate"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeClient records the BlockIp calls made by the consumer.
+type fakeClient struct {
+	pb.DnsServiceClient
+	mu       sync.Mutex
+	blocked  []string
+	requests []*pb.BlockIpRequest
+	metadata []metadata.MD
+}
+
+func (f *fakeClient) BlockIp(ctx context.Context, in *pb.BlockIpRequest, opts ...grpc.CallOption) (*pb.BlockIpResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocked = append(f.blocked, in.GetIpAddress())
+	f.requests = append(f.requests, in)
+	md, _ := metadata.FromOutgoingContext(ctx)
+	f.metadata = append(f.metadata, md)
+	return &pb.BlockIpResponse{Status: "success"}, nil
+}
+
+func (f *fakeClient) blockedIps() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.blocked...)
+}
+
+func TestConsumerBlocksMaliciousIps(t *testing.T) {
+	b := bus.NewMemory(3)
+	subscriber := b.Subscriber("myGroup")
+	require.NoError(t, subscriber.Subscribe([]string{topic}))
+	client := &fakeClient{}
+	c := &consumer{subscriber: subscriber, client: client, deadLetters: b.Publisher(), deadLetterTopic: "dlq"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.run(ctx)
+		close(done)
+	}()
+
+	publisher := b.Publisher()
+	for _, ip := range []string{"10.0.0.1", "192.168.1.70", "10.0.0.2"} {
+		value, err := event.Encode(&pb.DnsRequest{IpAddress: ip, Domain: "test.com", QueryType: pb.QueryType_QUERY_TYPE_A})
+		require.NoError(t, err)
+		require.NoError(t, publisher.Publish(&bus.Message{Topic: topic, Key: []byte(ip), Value: value}))
+	}
+	// Events of older servers are still understood
+	require.NoError(t, publisher.Publish(&bus.Message{
+		Topic: topic,
+		Key:   []byte("10.0.1.70"),
+		Value: []byte("IP: 10.0.1.70, Domain: test.com, QueryType: A, Timestamp: 0"),
+	}))
+
+	assert.Eventually(t, func() bool { return len(client.blockedIps()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	assert.ElementsMatch(t, []string{"192.168.1.70", "10.0.1.70"}, client.blockedIps())
+}
+
+func TestConsumerDeadLettersUnparseableMessages(t *testing.T) {
+	b := bus.NewMemory(1)
+	dlq := b.Subscriber("test")
+	require.NoError(t, dlq.Subscribe([]string{"dlq"}))
+	c := &consumer{client: &fakeClient{}, deadLetters: b.Publisher(), deadLetterTopic: "dlq"}
+
+	c.handle(context.Background(), &bus.Message{Topic: topic, Partition: 1, Offset: 7, Value: []byte("garbage")})
+
+	msg, err := dlq.Read(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "garbage", string(msg.Value))
+	assert.Equal(t, topic, deadletter.Header(msg, deadletter.HeaderTopic))
+	assert.Equal(t, "1", deadletter.Header(msg, deadletter.HeaderPartition))
+	assert.Equal(t, "7", deadletter.Header(msg, deadletter.HeaderOffset))
+	assert.Equal(t, `invalid event field "garbage"`, deadletter.Header(msg, deadletter.HeaderError))
+	assert.Equal(t, 1, deadletter.Attempts(msg))
+}
+
+func TestConsumerAppliesTenantDetectors(t *testing.T) {
+	tenants, err := tenant.New([]tenant.Tenant{
+		{ID: "payments", Tokens: []string{"payments-token"}, Detector: tenant.Detector{
+			MaliciousSuffixes: []string{"66"},
+			BlockTTL:          time.Hour,
+			BlockReason:       "payments detector",
+		}},
+	})
+	require.NoError(t, err)
+	client := &fakeClient{}
+	c := &consumer{client: client, tenants: tenants}
+
+	for _, m := range []struct {
+		ip, tenant string
+	}{
+		{"10.0.0.66", "payments"},
+		{"10.0.0.70", "payments"},
+		{"10.0.1.66", ""},
+		{"10.0.1.70", ""},
+	} {
+		value, err := event.Encode(&pb.DnsRequest{IpAddress: m.ip, Domain: "test.com"})
+		require.NoError(t, err)
+		msg := &bus.Message{Topic: topic, Value: value}
+		if m.tenant != "" {
+			msg.Headers = []bus.Header{{Key: tenant.HeaderKey, Value: []byte(m.tenant)}}
+		}
+		c.handle(context.Background(), msg)
+	}
+
+	assert.Equal(t, []string{"10.0.0.66", "10.0.1.70"}, client.blockedIps())
+	assert.Equal(t, "rule malicious-suffix (medium): payments detector", client.requests[0].GetReason())
+	assert.Equal(t, int64(3600), client.requests[0].GetTtlSeconds())
+	assert.Equal(t, []string{"payments"}, client.metadata[0].Get(tenant.MetadataKey))
+	assert.Equal(t, []string{"Bearer payments-token"}, client.metadata[0].Get("authorization"))
+	assert.Equal(t, []string{tenant.Default}, client.metadata[1].Get(tenant.MetadataKey))
+	assert.Empty(t, client.metadata[1].Get("authorization"))
+}
+
+func TestConsumerAppliesRules(t *testing.T) {
+	set, err := rules.Parse([]byte(`
+rules:
+  - id: txt-burst
+    severity: high
+    duration: 10m
+    reason: TXT burst
+    when: qtype == "TXT" && count(ip, 1m) >= 3
+  - id: lab
+    action: alert
+    when: inCIDR(ip, "10.9.0.0/16")
+  - id: payments-only
+    tenants: [payments]
+    when: domain == "pay.example.com"
+`))
+	require.NoError(t, err)
+	client := &fakeClient{}
+	c := &consumer{client: client}
+	c.rules.Store(set)
+
+	send := func(ip, domain string, qt pb.QueryType, id string) {
+		value, err := event.Encode(&pb.DnsRequest{IpAddress: ip, Domain: domain, QueryType: qt, Timestamp: 1700000000})
+		require.NoError(t, err)
+		msg := &bus.Message{Topic: topic, Value: value}
+		if id != "" {
+			msg.Headers = []bus.Header{{Key: tenant.HeaderKey, Value: []byte(id)}}
+		}
+		c.handle(context.Background(), msg)
+	}
+	// The rules replace the detector settings
+	send("10.0.0.70", "test.com", pb.QueryType_QUERY_TYPE_A, "")
+	for i := 0; i < 3; i++ {
+		send("10.0.0.1", "t.example.com", pb.QueryType_QUERY_TYPE_TXT, "")
+	}
+	send("10.9.0.1", "test.com", pb.QueryType_QUERY_TYPE_A, "")
+	send("10.0.0.2", "pay.example.com", pb.QueryType_QUERY_TYPE_A, "")
+	send("10.0.0.3", "pay.example.com", pb.QueryType_QUERY_TYPE_A, "payments")
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.3"}, client.blockedIps())
+	assert.Equal(t, "rule txt-burst (high): TXT burst", client.requests[0].GetReason())
+	assert.Equal(t, int64(600), client.requests[0].GetTtlSeconds())
+	assert.Equal(t, "rule payments-only (medium)", client.requests[1].GetReason())
+	assert.Equal(t, int64(0), client.requests[1].GetTtlSeconds())
+	assert.Equal(t, []string{"payments"}, client.metadata[1].Get(tenant.MetadataKey))
+}
+
+func TestConsumerReloadsRulesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	v1 := []byte("version: v1\nrules:\n  - id: a\n    when: endsWith(ip, \".1\")\n")
+	require.NoError(t, os.WriteFile(path, v1, 0o600))
+	c := &consumer{client: &fakeClient{}}
+	require.NoError(t, c.loadRules(v1, path))
+	assert.Equal(t, "v1", c.rulesVersion())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.watchRulesFile(ctx, path, v1, 10*time.Millisecond)
+
+	// Invalid rules are rejected, the previous ones stay active
+	require.NoError(t, os.WriteFile(path, []byte("version: v2\nrules:\n  - id: a\n    when: ip ==\n"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "v1", c.rulesVersion())
+
+	require.NoError(t, os.WriteFile(path, []byte("version: v3\nrules:\n  - id: b\n    when: endsWith(ip, \".2\")\n"), 0o600))
+	assert.Eventually(t, func() bool { return c.rulesVersion() == "v3" }, 2*time.Second, 10*time.Millisecond)
+	set, err := c.ruleSet(tenant.Default)
+	require.NoError(t, err)
+	assert.Equal(t, "b", set.Rules()[0].ID)
+}
+
+func TestConsumerReloadsRulesTopic(t *testing.T) {
+	b := bus.NewMemory(1)
+	sub := b.Subscriber("rules")
+	require.NoError(t, sub.Subscribe([]string{"rules"}))
+	c := &consumer{client: &fakeClient{}}
+	assert.Equal(t, "none", c.rulesVersion())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.watchRulesTopic(ctx, sub)
+
+	publisher := b.Publisher()
+	for _, value := range []string{
+		"version: v1\nrules: []\n",
+		"version: v2\nrules:\n  - id: a\n    when: nope\n",
+		"",
+	} {
+		require.NoError(t, publisher.Publish(&bus.Message{Topic: "rules", Key: []byte("rules"), Value: []byte(value)}))
+	}
+	assert.Eventually(t, func() bool { return c.rulesVersion() == "v1" }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "v1", c.rulesVersion())
+}
+
+func TestConsumerFlagsDGASources(t *testing.T) {
+	set, err := rules.Parse([]byte(`
+rules:
+  - id: dga-nxdomain
+    severity: high
+    when: rcode == "NXDOMAIN" && dga(domain) >= 0.6 && count(ip, 5m) >= 5
+`))
+	require.NoError(t, err)
+	client := &fakeClient{}
+	c := &consumer{client: client}
+	c.rules.Store(set)
+
+	nxdomain := pb.ResponseCode_RESPONSE_CODE_NXDOMAIN
+	send := func(ip, domain string) {
+		value, err := event.Encode(&pb.DnsRequest{IpAddress: ip, Domain: domain, Rcode: &nxdomain, Timestamp: 1700000000})
+		require.NoError(t, err)
+		c.handle(context.Background(), &bus.Message{Topic: topic, Value: value})
+	}
+	// Typos of benign names, and generated names
+	for _, d := range []string{"gooogle.com", "facebok.com", "wikipedai.org", "stackoverflwo.com", "amazom.com", "nytimse.com"} {
+		send("10.0.0.1", d)
+	}
+	for _, d := range []string{"xjw8qk2lpz7v.com", "qwhpzkvnrtdl.net", "4f8a9c2e1d7b.com", "vgbunhmrfdtre.biz", "uwpyvpqxbxkdfae.com"} {
+		send("10.0.0.2", d)
+	}
+	assert.Equal(t, []string{"10.0.0.2"}, client.blockedIps())
+}
+
+func TestConsumerDetectsTunnels(t *testing.T) {
+	set, err := rules.Load("../config/rules.example.yml")
+	require.NoError(t, err)
+	client := &fakeClient{}
+	c := &consumer{client: client}
+	c.rules.Store(set)
+
+	send := func(req *pb.DnsRequest) {
+		value, err := event.Encode(req)
+		require.NoError(t, err)
+		c.handle(context.Background(), &bus.Message{Topic: topic, Value: value})
+	}
+	// Two minutes of browsing and of a tunnel, from two sources
+	start := time.Unix(1700000000, 0)
+	tunnel, err := simulate.NewTunnel("10.0.0.5", "t.exfil.example", 1)
+	require.NoError(t, err)
+	for i := 0; i < 600; i++ {
+		at := start.Add(time.Duration(i) * 200 * time.Millisecond)
+		send(&pb.DnsRequest{
+			IpAddress: "10.0.0.6",
+			Domain:    fmt.Sprintf("%s.cdn%d.example.net", []string{"www", "api", "img", "static"}[i%4], i%40),
+			QueryType: pb.QueryType_QUERY_TYPE_A,
+			Timestamp: at.Unix(),
+		})
+		send(tunnel.Next(at))
+	}
+
+	blocked := client.blockedIps()
+	require.NotEmpty(t, blocked)
+	assert.Equal(t, "10.0.0.5", blocked[0])
+	assert.NotContains(t, blocked, "10.0.0.6")
+	assert.Equal(t, "rule dns-tunnel (high): possible DNS tunnel", client.requests[0].GetReason())
+	assert.Equal(t, int64(86400), client.requests[0].GetTtlSeconds())
+}
+
+func TestConsumerAggregatesPerSource(t *testing.T) {
+	set, err := rules.Load("../config/rules.example.yml")
+	require.NoError(t, err)
+	client := &fakeClient{}
+	c := &consumer{client: client}
+	c.rules.Store(set)
+
+	nxdomain := pb.ResponseCode_RESPONSE_CODE_NXDOMAIN
+	send := func(ip string, i int, at time.Time) {
+		req := &pb.DnsRequest{
+			IpAddress: ip,
+			Domain:    fmt.Sprintf("host%d.corp.example", i),
+			QueryType: pb.QueryType_QUERY_TYPE_A,
+			Timestamp: at.Unix(),
+		}
+		if i%10 < 7 {
+			req.Rcode = &nxdomain
+		}
+		value, err := event.Encode(req)
+		require.NoError(t, err)
+		c.handle(context.Background(), &bus.Message{Topic: topic, Value: value})
+	}
+	// Windows follow the timestamps of the events: the same failures spread
+	// over hours are not aggregated
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 120; i++ {
+		send("10.0.0.7", i, start.Add(time.Duration(i)*2*time.Second))
+		send("10.0.0.8", i, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	blocked := client.blockedIps()
+	require.NotEmpty(t, blocked)
+	assert.Equal(t, "10.0.0.7", blocked[0])
+	assert.NotContains(t, blocked, "10.0.0.8")
+	assert.Equal(t, "rule nxdomain-ratio (high): mostly failed lookups", client.requests[0].GetReason())
+	assert.Equal(t, int64(3600), client.requests[0].GetTtlSeconds())
+	assert.Positive(t, set.Keys())
+}
+
+func TestConsumerMatchesFeeds(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "c2.txt")
+	require.NoError(t, os.WriteFile(text, []byte("# C2\nc2.example\n"), 0o600))
+	stix := filepath.Join(dir, "cert.json")
+	require.NoError(t, os.WriteFile(stix, []byte(`{"type": "bundle", "objects": [
+  {"type": "indicator", "id": "indicator--1", "pattern_type": "stix", "pattern": "[ipv4-addr:value = '198.51.100.7']"}]}`), 0o600))
+	client := &fakeClient{}
+	c := &consumer{client: client, feeds: ioc.NewMatcher()}
+	textFeed := ioc.Feed{Name: "c2", Path: text, Format: ioc.FormatText, Refresh: 10 * time.Millisecond}
+	data, err := c.loadFeed(textFeed)
+	require.NoError(t, err)
+	_, err = c.loadFeed(ioc.Feed{Name: "cert", Path: stix, Format: ioc.FormatSTIX, Duration: time.Hour})
+	require.NoError(t, err)
+
+	send := func(ip, domain string, answers ...string) {
+		req := &pb.DnsRequest{IpAddress: ip, Domain: domain, QueryType: pb.QueryType_QUERY_TYPE_A}
+		for _, a := range answers {
+			req.Answers = append(req.Answers, &pb.Answer{Type: pb.QueryType_QUERY_TYPE_A, Data: a})
+		}
+		value, err := event.Encode(req)
+		require.NoError(t, err)
+		c.handle(context.Background(), &bus.Message{Topic: topic, Value: value})
+	}
+	send("10.0.0.1", "www.example.com")
+	send("10.0.0.2", "beacon.c2.example")
+	send("10.0.0.3", "cdn.example.net", "198.51.100.7")
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, client.blockedIps())
+	assert.Equal(t, "feed c2 indicator c2.example", client.requests[0].GetReason())
+	assert.Equal(t, int64(0), client.requests[0].GetTtlSeconds())
+	assert.Equal(t, "feed cert indicator indicator--1", client.requests[1].GetReason())
+	assert.Equal(t, int64(3600), client.requests[1].GetTtlSeconds())
+
+	// Feeds are read again on schedule, keeping the hit counts
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.watchFeed(ctx, textFeed, data)
+	require.NoError(t, os.WriteFile(text, []byte("c2.example\nother.example\n"), 0o600))
+	assert.Eventually(t, func() bool { return c.feeds.Stats()["c2"].Indicators == 2 }, 2*time.Second, 10*time.Millisecond)
+	send("10.0.0.4", "other.example")
+	send("10.0.0.5", "c2.example")
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}, client.blockedIps())
+	assert.Equal(t, map[string]int64{"c2.example": 2, "other.example": 1}, c.feeds.Stats()["c2"].Hits)
+	assert.Equal(t, map[string]int64{"indicator--1": 1}, c.feeds.Stats()["cert"].Hits)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/rules"
+)
+
+// loadRules validates the rules document data, read from source, and swaps
+// it in. Invalid rules are rejected and the current rules stay active.
+func (c *consumer) loadRules(data []byte, source string) error {
+	set, err := rules.Parse(data)
+	if err != nil {
+		log.Printf("Rejected the detection rules of %s, keeping version %s: %v", source, c.rulesVersion(), err)
+		stats.Add("rules_reload_failed", 1)
+		return err
+	}
+	set.Inherit(c.rules.Load())
+	c.rules.Store(set)
+	stats.Add("rules_reloads", 1)
+	log.Printf("Loaded version %s of the detection rules from %s: %d rules", set.Version(), source, len(set.Rules()))
+	return nil
+}
+
+// ruleStats registers the gauges of the active rules in stats.
+func (c *consumer) ruleStats() {
+	stats.Set("rules_version", expvar.Func(func() any { return c.rulesVersion() }))
+	stats.Set("rules_loaded", expvar.Func(func() any {
+		if set := c.rules.Load(); set != nil {
+			return int64(len(set.Rules()))
+		}
+		return int64(0)
+	}))
+	stats.Set("rule_counter_keys", expvar.Func(func() any {
+		if set := c.rules.Load(); set != nil {
+			return int64(set.Keys())
+		}
+		return int64(0)
+	}))
+	stats.Set("rule_counter_evictions", expvar.Func(func() any {
+		if set := c.rules.Load(); set != nil {
+			return set.Evictions()
+		}
+		return int64(0)
+	}))
+}
+
+// rulesVersion returns the version of the active rules, "none" when the
+// detector settings apply.
+func (c *consumer) rulesVersion() string {
+	if set := c.rules.Load(); set != nil {
+		return set.Version()
+	}
+	return "none"
+}
+
+// watchRulesFile reloads the rules of the file at path whenever its content
+// changes, checking every interval until ctx is done. last is the content
+// loaded at startup.
+func (c *consumer) watchRulesFile(ctx context.Context, path string, last []byte, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// Editors and config maps replace files, try again later
+			log.Printf("Failed to read the detection rules: %v", err)
+			continue
+		}
+		if bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+		c.loadRules(data, path)
+	}
+}
+
+// watchRulesTopic loads the rules documents read by sub, a subscriber of a
+// compacted topic, until ctx is done. Empty values, the tombstones of
+// compaction, are ignored.
+func (c *consumer) watchRulesTopic(ctx context.Context, sub bus.Subscriber) {
+	for ctx.Err() == nil {
+		msg, err := sub.Read(time.Second)
+		if err == bus.ErrTimeout {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to read the detection rules: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		if len(msg.Value) == 0 {
+			continue
+		}
+		c.loadRules(msg.Value, fmt.Sprintf("%s[%d]@%d", msg.Topic, msg.Partition, msg.Offset))
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"expvar"
+	"log"
+	"net/http"
+)
+
+// stats holds the consumer counters, served as JSON on /debug/vars of
+// METRICS_ADDR.
+var stats = expvar.NewMap("dns_consumer")
+
+// serveMetrics serves the expvar variables on addr, in the background.
+func serveMetrics(addr string) {
+	go func() {
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			log.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+}
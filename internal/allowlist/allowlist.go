@@ -0,0 +1,192 @@
+// Package allowlist matches IPs and domains against the allowlist entries of
+// the store, which protect them from being blocked.
+package allowlist
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dnsname"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+)
+
+// Kinds of allowlist entries.
+const (
+	KindIP     = "ip"
+	KindCIDR   = "cidr"
+	KindDomain = "domain"
+)
+
+// Parse returns the kind and the canonical form of an allowlist value: an IP
+// address, a CIDR, or a domain suffix matching the domain and its
+// subdomains.
+func Parse(value string) (kind, canonical string, err error) {
+	value = strings.TrimSpace(value)
+	if addr, err := ipaddr.Parse(value); err == nil {
+		return KindIP, addr.String(), nil
+	}
+	if prefix, err := ipaddr.ParsePrefix(value); err == nil {
+		return KindCIDR, prefix.String(), nil
+	}
+	domain, err := dnsname.Normalize(value)
+	if err != nil {
+		return "", "", fmt.Errorf("%q is neither an IP, a CIDR nor a domain", value)
+	}
+	return KindDomain, domain, nil
+}
+
+// List is an immutable set of allowlist entries.
+type List struct {
+	ips      map[netip.Addr]store.AllowEntry
+	prefixes []prefixEntry
+	domains  map[string]store.AllowEntry
+	entries  []store.AllowEntry
+}
+
+type prefixEntry struct {
+	prefix netip.Prefix
+	entry  store.AllowEntry
+}
+
+// New returns the list of entries. Entries whose value does not parse are
+// skipped.
+func New(entries []store.AllowEntry) *List {
+	l := &List{
+		ips:     make(map[netip.Addr]store.AllowEntry),
+		domains: make(map[string]store.AllowEntry),
+		entries: entries,
+	}
+	for _, e := range entries {
+		kind, value, err := Parse(e.Value)
+		if err != nil {
+			continue
+		}
+		switch kind {
+		case KindIP:
+			l.ips[netip.MustParseAddr(value)] = e
+		case KindCIDR:
+			l.prefixes = append(l.prefixes, prefixEntry{netip.MustParsePrefix(value), e})
+		case KindDomain:
+			l.domains[value] = e
+		}
+	}
+	return l
+}
+
+// Entries returns the entries of the list.
+func (l *List) Entries() []store.AllowEntry {
+	if l == nil {
+		return nil
+	}
+	return l.entries
+}
+
+// MatchIP returns the entry protecting ip, an IP or a CIDR containing it.
+func (l *List) MatchIP(ip string) (store.AllowEntry, bool) {
+	if l == nil {
+		return store.AllowEntry{}, false
+	}
+	addr, err := ipaddr.Parse(ip)
+	if err != nil {
+		return store.AllowEntry{}, false
+	}
+	if e, ok := l.ips[addr]; ok {
+		return e, true
+	}
+	for _, p := range l.prefixes {
+		if p.prefix.Contains(addr) {
+			return p.entry, true
+		}
+	}
+	return store.AllowEntry{}, false
+}
+
+// MatchPrefix returns the first entry protecting an address of prefix, an IP
+// or a CIDR: an IP it contains or a CIDR overlapping it.
+func (l *List) MatchPrefix(prefix string) (store.AllowEntry, bool) {
+	if l == nil {
+		return store.AllowEntry{}, false
+	}
+	p, err := ipaddr.ParsePrefix(prefix)
+	if err != nil {
+		addr, err := ipaddr.Parse(prefix)
+		if err != nil {
+			return store.AllowEntry{}, false
+		}
+		p = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	for _, e := range l.entries {
+		kind, value, err := Parse(e.Value)
+		if err != nil {
+			continue
+		}
+		switch kind {
+		case KindIP:
+			if p.Contains(netip.MustParseAddr(value)) {
+				return e, true
+			}
+		case KindCIDR:
+			if p.Overlaps(netip.MustParsePrefix(value)) {
+				return e, true
+			}
+		}
+	}
+	return store.AllowEntry{}, false
+}
+
+// MatchDomain returns the entry protecting domain, the domain itself or one
+// of its parent domains.
+func (l *List) MatchDomain(domain string) (store.AllowEntry, bool) {
+	if l == nil {
+		return store.AllowEntry{}, false
+	}
+	domain, err := dnsname.Normalize(domain)
+	if err != nil {
+		return store.AllowEntry{}, false
+	}
+	for domain != "" {
+		if e, ok := l.domains[domain]; ok {
+			return e, true
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	return store.AllowEntry{}, false
+}
+
+// Matches returns every entry protecting ip or domain: the IP, the CIDRs
+// containing it, the domain and its parent domains.
+func (l *List) Matches(ip, domain string) []store.AllowEntry {
+	if l == nil {
+		return nil
+	}
+	var matches []store.AllowEntry
+	if addr, err := ipaddr.Parse(ip); err == nil {
+		if e, ok := l.ips[addr]; ok {
+			matches = append(matches, e)
+		}
+		for _, p := range l.prefixes {
+			if p.prefix.Contains(addr) {
+				matches = append(matches, p.entry)
+			}
+		}
+	}
+	if domain, err := dnsname.Normalize(domain); err == nil {
+		for domain != "" {
+			if e, ok := l.domains[domain]; ok {
+				matches = append(matches, e)
+			}
+			_, parent, found := strings.Cut(domain, ".")
+			if !found {
+				break
+			}
+			domain = parent
+		}
+	}
+	return matches
+}
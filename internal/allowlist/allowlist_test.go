@@ -0,0 +1,102 @@
+package allowlist
+
+import (
+	"testing"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value     string
+		kind      string
+		canonical string
+	}{
+		{"192.168.1.1", KindIP, "192.168.1.1"},
+		{"::ffff:192.168.1.1", KindIP, "192.168.1.1"},
+		{"2001:DB8::1", KindIP, "2001:db8::1"},
+		{"10.1.2.3/8", KindCIDR, "10.0.0.0/8"},
+		{"2001:db8::/32", KindCIDR, "2001:db8::/32"},
+		{"::ffff:10.0.0.0/104", KindCIDR, "10.0.0.0/8"},
+		{"Example.COM.", KindDomain, "example.com"},
+		{"_dmarc.example.com", KindDomain, "_dmarc.example.com"},
+	}
+	for _, tt := range tests {
+		kind, canonical, err := Parse(tt.value)
+		if assert.NoError(t, err, tt.value) {
+			assert.Equal(t, tt.kind, kind, tt.value)
+			assert.Equal(t, tt.canonical, canonical, tt.value)
+		}
+	}
+
+	for _, value := range []string{"", "not a domain", "-bad.com", "a..b", "10.0.0.0/33"} {
+		_, _, err := Parse(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	l := New([]store.AllowEntry{
+		{Value: "192.168.1.1", Kind: KindIP},
+		{Value: "10.0.0.0/8", Kind: KindCIDR},
+		{Value: "2001:db8::/32", Kind: KindCIDR},
+		{Value: "example.com", Kind: KindDomain},
+	})
+
+	for ip, want := range map[string]string{
+		"192.168.1.1":        "192.168.1.1",
+		"::ffff:192.168.1.1": "192.168.1.1",
+		"10.20.30.40":        "10.0.0.0/8",
+		"2001:db8::1":        "2001:db8::/32",
+	} {
+		e, ok := l.MatchIP(ip)
+		if assert.True(t, ok, ip) {
+			assert.Equal(t, want, e.Value, ip)
+		}
+	}
+	for _, ip := range []string{"192.168.1.2", "11.0.0.1", "garbage"} {
+		_, ok := l.MatchIP(ip)
+		assert.False(t, ok, ip)
+	}
+
+	for _, domain := range []string{"example.com", "www.Example.com.", "a.b.example.com"} {
+		_, ok := l.MatchDomain(domain)
+		assert.True(t, ok, domain)
+	}
+	for _, domain := range []string{"notexample.com", "example.org", "com"} {
+		_, ok := l.MatchDomain(domain)
+		assert.False(t, ok, domain)
+	}
+
+	var values []string
+	for _, e := range l.Matches("10.0.0.1", "www.example.com") {
+		values = append(values, e.Value)
+	}
+	assert.Equal(t, []string{"10.0.0.0/8", "example.com"}, values)
+	assert.Empty(t, l.Matches("11.0.0.1", "example.org"))
+
+	for prefix, want := range map[string]string{
+		"192.168.1.0/24":  "192.168.1.1",
+		"10.1.2.3":        "10.0.0.0/8",
+		"10.1.0.0/16":     "10.0.0.0/8",
+		"0.0.0.0/0":       "192.168.1.1",
+		"2001:db8:1::/48": "2001:db8::/32",
+	} {
+		e, ok := l.MatchPrefix(prefix)
+		if assert.True(t, ok, prefix) {
+			assert.Equal(t, want, e.Value, prefix)
+		}
+	}
+	for _, prefix := range []string{"192.168.2.0/24", "11.0.0.0/8", "2001:db9::/32", "garbage"} {
+		_, ok := l.MatchPrefix(prefix)
+		assert.False(t, ok, prefix)
+	}
+
+	var empty *List
+	_, ok := empty.MatchIP("10.0.0.1")
+	assert.False(t, ok)
+	_, ok = empty.MatchPrefix("10.0.0.0/8")
+	assert.False(t, ok)
+	assert.Empty(t, empty.Matches("10.0.0.1", "example.com"))
+}
@@ -0,0 +1,266 @@
+// Package blockfile reads and writes blacklist entries as JSON Lines or CSV
+// files, to back up a blacklist or move it between stores, and computes the
+// changes an import makes to a blacklist.
+package blockfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+)
+
+// Formats of the files.
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// maxTTL is the longest TTL read from the files, in seconds. Longer TTLs
+// would overflow the expiry; entries meant to never expire have TTL -1.
+const maxTTL = 100 * 365 * 24 * 60 * 60
+
+// columns are the CSV columns, and the JSON Lines fields.
+var columns = []string{"ip_address", "reason", "created_at", "ttl_seconds"}
+
+// record is an entry as written in the files. The remaining TTL, rather than
+// the expiry, is written so that files are not tied to the clock of the
+// server; TTL is -1 for entries that never expire.
+type record struct {
+	IP        string `json:"ip_address"`
+	Reason    string `json:"reason,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	TTL       *int64 `json:"ttl_seconds,omitempty"`
+}
+
+func newRecord(e store.Entry, now time.Time) record {
+	ttl := int64(-1)
+	if !e.ExpiresAt.IsZero() {
+		// Round up, so that an imported block never ends early
+		ttl = max(int64((e.ExpiresAt.Sub(now)+time.Second-1)/time.Second), 1)
+	}
+	r := record{IP: e.IP, Reason: e.Reason, TTL: &ttl}
+	if !e.CreatedAt.IsZero() {
+		r.CreatedAt = e.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return r
+}
+
+// entry returns the entry of r, false if its TTL ran out.
+func (r record) entry(now time.Time) (store.Entry, bool, error) {
+	ip, err := ipaddr.Canonical(r.IP)
+	if err != nil {
+		return store.Entry{}, false, err
+	}
+	e := store.Entry{IP: ip, Reason: r.Reason, CreatedAt: now.UTC()}
+	if r.CreatedAt != "" {
+		if e.CreatedAt, err = time.Parse(time.RFC3339, r.CreatedAt); err != nil {
+			return store.Entry{}, false, fmt.Errorf("invalid created_at %q", r.CreatedAt)
+		}
+	}
+	if r.TTL != nil && *r.TTL >= 0 {
+		if *r.TTL == 0 {
+			return store.Entry{}, false, nil
+		}
+		if *r.TTL > maxTTL {
+			return store.Entry{}, false, fmt.Errorf("ttl_seconds %d out of range", *r.TTL)
+		}
+		e.ExpiresAt = now.Add(time.Duration(*r.TTL) * time.Second)
+	}
+	return e, true, nil
+}
+
+// Write writes the entries unexpired at now to w in the given format, sorted
+// by IP.
+func Write(w io.Writer, format string, entries []store.Entry, now time.Time) error {
+	entries = append([]store.Entry(nil), entries...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].IP < entries[j].IP })
+	switch format {
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if e.Expired(now) {
+				continue
+			}
+			if err := enc.Encode(newRecord(e, now)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Expired(now) {
+				continue
+			}
+			r := newRecord(e, now)
+			if err := cw.Write([]string{r.IP, r.Reason, r.CreatedAt, strconv.FormatInt(*r.TTL, 10)}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return unknownFormat(format)
+}
+
+// Read reads the entries of a file in the given format. Entries whose TTL
+// ran out are dropped. Errors give the line of the invalid entry.
+func Read(r io.Reader, format string, now time.Time) ([]store.Entry, error) {
+	var records []record
+	var lines []int
+	switch format {
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var rec record
+			if err := json.Unmarshal([]byte(text), &rec); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			records, lines = append(records, rec), append(lines, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.Comment = '#'
+		header, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		index := make(map[string]int)
+		for i, name := range header {
+			index[strings.TrimSpace(name)] = i
+		}
+		if _, ok := index["ip_address"]; !ok {
+			return nil, errors.New("line 1: missing ip_address column")
+		}
+		field := func(row []string, name string) string {
+			if i, ok := index[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		for {
+			row, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			line, _ := cr.FieldPos(0)
+			rec := record{IP: field(row, "ip_address"), Reason: field(row, "reason"), CreatedAt: field(row, "created_at")}
+			if ttl := field(row, "ttl_seconds"); ttl != "" {
+				v, err := strconv.ParseInt(ttl, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid ttl_seconds %q", line, ttl)
+				}
+				rec.TTL = &v
+			}
+			records, lines = append(records, rec), append(lines, line)
+		}
+	default:
+		return nil, unknownFormat(format)
+	}
+
+	entries := make([]store.Entry, 0, len(records))
+	seen := make(map[string]int)
+	for i, rec := range records {
+		e, ok, err := rec.entry(now)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lines[i], err)
+		}
+		if !ok {
+			continue
+		}
+		if first, dup := seen[e.IP]; dup {
+			return nil, fmt.Errorf("line %d: %s is already listed on line %d", lines[i], e.IP, first)
+		}
+		seen[e.IP] = lines[i]
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func unknownFormat(format string) error {
+	return fmt.Errorf("unknown blocklist format %q, expected %s or %s", format, FormatJSONL, FormatCSV)
+}
+
+// Actions of the changes.
+const (
+	ActionAdd    = "add"
+	ActionUpdate = "update"
+	ActionRemove = "remove"
+)
+
+// Change is a change an import makes to a blacklist.
+type Change struct {
+	Action string
+	Entry  store.Entry
+	// Previous is the entry replaced by an update.
+	Previous store.Entry
+}
+
+// Diff returns the changes importing the entries makes to the current ones,
+// sorted by IP, and the number of entries left unchanged. With replace, the
+// current entries missing from the import are removed. Expiries less than
+// two seconds apart are the same, since the files round TTLs up to the
+// second.
+func Diff(current, imported []store.Entry, replace bool) ([]Change, int) {
+	byIP := make(map[string]store.Entry, len(current))
+	for _, e := range current {
+		byIP[e.IP] = e
+	}
+	var changes []Change
+	unchanged := 0
+	for _, e := range imported {
+		prev, ok := byIP[e.IP]
+		delete(byIP, e.IP)
+		switch {
+		case !ok:
+			changes = append(changes, Change{Action: ActionAdd, Entry: e})
+		case same(prev, e):
+			unchanged++
+		default:
+			changes = append(changes, Change{Action: ActionUpdate, Entry: e, Previous: prev})
+		}
+	}
+	if replace {
+		for _, e := range byIP {
+			changes = append(changes, Change{Action: ActionRemove, Entry: e})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Entry.IP < changes[j].Entry.IP })
+	return changes, unchanged
+}
+
+func same(a, b store.Entry) bool {
+	if a.Reason != b.Reason || a.ExpiresAt.IsZero() != b.ExpiresAt.IsZero() {
+		return false
+	}
+	d := a.ExpiresAt.Sub(b.ExpiresAt)
+	return d < 2*time.Second && d > -2*time.Second
+}
@@ -0,0 +1,90 @@
+package blockfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+	entries := []store.Entry{
+		{IP: "10.0.0.2", Reason: "scan, then \"beacon\"", CreatedAt: created, ExpiresAt: now.Add(90 * time.Second)},
+		{IP: "10.0.0.1", CreatedAt: created},
+		{IP: "10.0.0.3", CreatedAt: created, ExpiresAt: now.Add(-time.Second)},
+	}
+
+	var jsonl bytes.Buffer
+	require.NoError(t, Write(&jsonl, FormatJSONL, entries, now))
+	assert.Equal(t, `{"ip_address":"10.0.0.1","created_at":"2023-12-31T23:00:00Z","ttl_seconds":-1}
+{"ip_address":"10.0.0.2","reason":"scan, then \"beacon\"","created_at":"2023-12-31T23:00:00Z","ttl_seconds":90}
+`, jsonl.String())
+
+	var csv bytes.Buffer
+	require.NoError(t, Write(&csv, FormatCSV, entries, now))
+	assert.Equal(t, `ip_address,reason,created_at,ttl_seconds
+10.0.0.1,,2023-12-31T23:00:00Z,-1
+10.0.0.2,"scan, then ""beacon""",2023-12-31T23:00:00Z,90
+`, csv.String())
+
+	// Files are read back relative to the time of the import
+	later := now.Add(time.Hour)
+	for format, data := range map[string]string{FormatJSONL: jsonl.String(), FormatCSV: csv.String()} {
+		got, err := Read(strings.NewReader(data), format, later)
+		require.NoError(t, err, format)
+		assert.Equal(t, []store.Entry{
+			{IP: "10.0.0.1", CreatedAt: created},
+			{IP: "10.0.0.2", Reason: "scan, then \"beacon\"", CreatedAt: created, ExpiresAt: later.Add(90 * time.Second)},
+		}, got, format)
+	}
+
+	// Columns may be missing or reordered, and IPs are canonicalized
+	got, err := Read(strings.NewReader("ttl_seconds,ip_address\n0,10.0.0.9\n,::ffff:10.0.0.1\n"), FormatCSV, now)
+	require.NoError(t, err)
+	assert.Equal(t, []store.Entry{{IP: "10.0.0.1", CreatedAt: now}}, got)
+
+	for format, data := range map[string]string{
+		FormatJSONL: "{\"ip_address\":\"10.0.0.1\"}\n{\"ip_address\":\"nope\"}\n",
+		FormatCSV:   "ip_address\n10.0.0.1\n10.0.0.1\n",
+		"xml":       "",
+	} {
+		_, err := Read(strings.NewReader(data), format, now)
+		assert.Error(t, err, format)
+	}
+	_, err = Read(strings.NewReader("reason\nscan\n"), FormatCSV, now)
+	assert.ErrorContains(t, err, "ip_address")
+
+	// TTLs that would overflow the expiry are rejected rather than wrapping
+	// around into the past
+	_, err = Read(strings.NewReader("ip_address,ttl_seconds\n10.0.0.1,9300000000\n"), FormatCSV, now)
+	assert.ErrorContains(t, err, "ttl_seconds")
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	current := []store.Entry{
+		{IP: "10.0.0.1", Reason: "same", ExpiresAt: now.Add(time.Hour)},
+		{IP: "10.0.0.2", Reason: "old"},
+		{IP: "10.0.0.3"},
+	}
+	imported := []store.Entry{
+		{IP: "10.0.0.1", Reason: "same", ExpiresAt: now.Add(time.Hour + time.Second)},
+		{IP: "10.0.0.2", Reason: "new"},
+		{IP: "10.0.0.4"},
+	}
+	changes, unchanged := Diff(current, imported, false)
+	assert.Equal(t, 1, unchanged)
+	require.Len(t, changes, 2)
+	assert.Equal(t, Change{Action: ActionUpdate, Entry: imported[1], Previous: current[1]}, changes[0])
+	assert.Equal(t, Change{Action: ActionAdd, Entry: imported[2]}, changes[1])
+
+	changes, _ = Diff(current, imported, true)
+	require.Len(t, changes, 3)
+	assert.Equal(t, Change{Action: ActionRemove, Entry: current[2]}, changes[1])
+}
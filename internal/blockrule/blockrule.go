@@ -0,0 +1,143 @@
+// Package blockrule matches DNS requests against scoped block rules, which
+// block the requests of a source for some domains or query types only.
+package blockrule
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dnsname"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+)
+
+// ParseSource returns the canonical form of a rule source: an IP, a CIDR, or
+// "" (or "*") for any source.
+func ParseSource(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" {
+		return "", nil
+	}
+	if addr, err := ipaddr.Parse(s); err == nil {
+		return addr.String(), nil
+	}
+	prefix, err := ipaddr.ParsePrefix(s)
+	if err != nil {
+		return "", fmt.Errorf("source %q is neither an IP nor a CIDR", s)
+	}
+	return prefix.String(), nil
+}
+
+// ParsePattern returns the canonical form of a domain pattern: a domain,
+// matching only itself, "*.<domain>", matching its subdomains, or "" (or
+// "*") for any domain.
+func ParsePattern(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" || p == "*" {
+		return "", nil
+	}
+	wildcard := strings.HasPrefix(p, "*.")
+	domain, err := dnsname.Normalize(strings.TrimPrefix(p, "*."))
+	if err != nil {
+		return "", fmt.Errorf("invalid domain pattern %q: %w", p, err)
+	}
+	if wildcard {
+		return "*." + domain, nil
+	}
+	return domain, nil
+}
+
+// ID returns the ID of the rule with the given canonical scope.
+func ID(source, pattern string, qtype uint32) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + pattern + "\x00" + strconv.FormatUint(uint64(qtype), 10)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Set is an immutable set of block rules, indexed by source.
+type Set struct {
+	ips      map[netip.Addr][]store.BlockRule
+	prefixes []prefixRule
+	any      []store.BlockRule
+	rules    []store.BlockRule
+}
+
+type prefixRule struct {
+	prefix netip.Prefix
+	rule   store.BlockRule
+}
+
+// New returns the set of rules. Rules whose source does not parse are
+// skipped.
+func New(rules []store.BlockRule) *Set {
+	s := &Set{ips: make(map[netip.Addr][]store.BlockRule), rules: rules}
+	for _, r := range rules {
+		if r.Source == "" {
+			s.any = append(s.any, r)
+		} else if addr, err := ipaddr.Parse(r.Source); err == nil {
+			s.ips[addr] = append(s.ips[addr], r)
+		} else if prefix, err := ipaddr.ParsePrefix(r.Source); err == nil {
+			s.prefixes = append(s.prefixes, prefixRule{prefix, r})
+		}
+	}
+	return s
+}
+
+// Rules returns the rules of the set.
+func (s *Set) Rules() []store.BlockRule {
+	if s == nil {
+		return nil
+	}
+	return s.rules
+}
+
+// Match returns the first rule of Matches.
+func (s *Set) Match(ip, domain string, qtype uint32, now time.Time) (store.BlockRule, bool) {
+	matches := s.Matches(ip, domain, qtype, now)
+	if len(matches) == 0 {
+		return store.BlockRule{}, false
+	}
+	return matches[0], true
+}
+
+// Matches returns the unexpired rules blocking a request from ip for domain
+// with the type qtype, the rules of the IP first, then those of its CIDRs and
+// those of any source. domain must be normalized.
+func (s *Set) Matches(ip, domain string, qtype uint32, now time.Time) []store.BlockRule {
+	if s == nil {
+		return nil
+	}
+	addr, err := ipaddr.Parse(ip)
+	if err != nil {
+		return nil
+	}
+	var matches []store.BlockRule
+	add := func(r store.BlockRule) {
+		if !r.Expired(now) && matchPattern(r.DomainPattern, domain) && (r.QueryType == 0 || r.QueryType == qtype) {
+			matches = append(matches, r)
+		}
+	}
+	for _, r := range s.ips[addr] {
+		add(r)
+	}
+	for _, p := range s.prefixes {
+		if p.prefix.Contains(addr) {
+			add(p.rule)
+		}
+	}
+	for _, r := range s.any {
+		add(r)
+	}
+	return matches
+}
+
+func matchPattern(pattern, domain string) bool {
+	if parent, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(domain, "."+parent)
+	}
+	return pattern == "" || pattern == domain
+}
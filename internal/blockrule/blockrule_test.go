@@ -0,0 +1,87 @@
+package blockrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                    "",
+		"*":                   "",
+		"::ffff:10.0.0.1":     "10.0.0.1",
+		"10.1.2.3/8":          "10.0.0.0/8",
+		"2001:DB8::/32":       "2001:db8::/32",
+		"192.168.1.70 ":       "192.168.1.70",
+		"::ffff:10.0.0.0/104": "10.0.0.0/8",
+	} {
+		got, err := ParseSource(in)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, want, got, in)
+		}
+	}
+	for _, in := range []string{"example.com", "10.0.0.0/33"} {
+		_, err := ParseSource(in)
+		assert.Error(t, err, in)
+	}
+
+	for in, want := range map[string]string{
+		"":                "",
+		"*":               "",
+		"C2.Example.com.": "c2.example.com",
+		"*.Example.COM":   "*.example.com",
+	} {
+		got, err := ParsePattern(in)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, want, got, in)
+		}
+	}
+	for _, in := range []string{"*example.com", "a.*.example.com", "-bad.com"} {
+		_, err := ParsePattern(in)
+		assert.Error(t, err, in)
+	}
+
+	assert.Equal(t, ID("10.0.0.1", "example.com", 16), ID("10.0.0.1", "example.com", 16))
+	assert.NotEqual(t, ID("10.0.0.1", "example.com", 16), ID("10.0.0.1", "example.com", 0))
+}
+
+func TestMatches(t *testing.T) {
+	now := time.Now()
+	rules := []store.BlockRule{
+		{ID: "c2", Source: "10.0.0.1", DomainPattern: "c2.example.com"},
+		{ID: "txt", Source: "10.0.0.0/8", QueryType: 16},
+		{ID: "sub", DomainPattern: "*.evil.com"},
+		{ID: "expired", Source: "10.0.0.1", ExpiresAt: now.Add(-time.Second)},
+	}
+	s := New(rules)
+
+	tests := []struct {
+		ip, domain string
+		qtype      uint32
+		want       []string
+	}{
+		{"10.0.0.1", "c2.example.com", 1, []string{"c2"}},
+		{"10.0.0.1", "c2.example.com", 16, []string{"c2", "txt"}},
+		{"10.0.0.1", "www.c2.example.com", 1, nil},
+		{"10.0.0.2", "example.com", 16, []string{"txt"}},
+		{"11.0.0.1", "example.com", 16, nil},
+		{"11.0.0.1", "a.evil.com", 1, []string{"sub"}},
+		{"11.0.0.1", "evil.com", 1, nil},
+		{"::ffff:10.0.0.1", "c2.example.com", 28, []string{"c2"}},
+		{"garbage", "c2.example.com", 1, nil},
+	}
+	for _, tt := range tests {
+		var ids []string
+		for _, r := range s.Matches(tt.ip, tt.domain, tt.qtype, now) {
+			ids = append(ids, r.ID)
+		}
+		assert.Equal(t, tt.want, ids, "%+v", tt)
+	}
+
+	var empty *Set
+	_, ok := empty.Match("10.0.0.1", "c2.example.com", 1, now)
+	assert.False(t, ok)
+}
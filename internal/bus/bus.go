@@ -0,0 +1,116 @@
+// Package bus abstracts the publish/subscribe event bus carrying DNS requests
+// from the server to the consumers. Kafka is the production implementation,
+// Memory is an in-process implementation with the same partitioning, key
+// ordering and consumer-group semantics for tests and single-node runs.
+package bus
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned by Subscriber.Read when no message arrived in time.
+var ErrTimeout = errors.New("bus: read timed out")
+
+// ErrClosed is returned when using a closed publisher or subscriber.
+var ErrClosed = errors.New("bus: closed")
+
+// ErrQueueFull is returned by Publisher.Publish when the queue of messages
+// waiting for delivery is full.
+var ErrQueueFull = errors.New("bus: publish queue full")
+
+// ErrUnavailable is returned by Publisher.Publish while the bus is known to be
+// unreachable.
+var ErrUnavailable = errors.New("bus: unavailable")
+
+// Header is a message header. Keys may repeat, as with Kafka headers.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is an event published on, or read from, a topic.
+type Message struct {
+	Topic string
+	// Partition and Offset are set on messages read from the bus and on
+	// successful delivery reports.
+	Partition int32
+	Offset    int64
+	// Key selects the partition, messages with the same key keep their order.
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Timestamp time.Time
+}
+
+// Delivery is the outcome of publishing a message.
+type Delivery struct {
+	Message *Message
+	// Err is nil if the message was delivered. Errors wrapping
+	// ErrUnavailable are transient, the bus could not be reached and the
+	// message can be published again later.
+	Err error
+}
+
+// Publisher publishes messages asynchronously.
+type Publisher interface {
+	// Publish enqueues m for delivery. An error means m was not enqueued, the
+	// outcome of the delivery itself is reported on Deliveries. ErrQueueFull
+	// and ErrUnavailable are transient, m can be published again later.
+	Publish(m *Message) error
+	// Deliveries returns the delivery reports, it must be drained.
+	Deliveries() <-chan Delivery
+	// Flush waits up to timeout for outstanding messages to be delivered and
+	// returns the number still outstanding.
+	Flush(timeout time.Duration) int
+	Close()
+}
+
+// Subscriber reads messages as a member of a consumer group. Each partition
+// is read by a single member of the group, and every group reads every
+// message.
+type Subscriber interface {
+	// Subscribe replaces the subscription. Topics starting with "^" are
+	// regular expressions matched against topic names.
+	Subscribe(topics []string) error
+	// Read returns the next message, or ErrTimeout if none arrived within
+	// timeout. Read offsets are committed automatically, unless the
+	// subscriber was created with ManualCommit.
+	Read(timeout time.Duration) (*Message, error)
+	// Commit marks m, and the messages before it in its partition, as
+	// processed by the group. It is only needed with ManualCommit.
+	Commit(m *Message) error
+	Close() error
+}
+
+type subscriberOptions struct {
+	manualCommit bool
+}
+
+// SubscriberOption configures a subscriber.
+type SubscriberOption func(*subscriberOptions)
+
+// ManualCommit makes the group offsets advance only on Commit, so that a
+// message read but not committed is read again by the group after a restart
+// or a rebalance.
+func ManualCommit() SubscriberOption {
+	return func(o *subscriberOptions) {
+		o.manualCommit = true
+	}
+}
+
+func applyOptions(opts []SubscriberOption) subscriberOptions {
+	var o subscriberOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+var (
+	_ Publisher  = (*KafkaPublisher)(nil)
+	_ Subscriber = (*KafkaSubscriber)(nil)
+	_ Subscriber = (*KafkaReader)(nil)
+	_ Publisher  = (*MemoryPublisher)(nil)
+	_ Subscriber = (*MemorySubscriber)(nil)
+)
@@ -0,0 +1,301 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// deliveriesBufferSize is the capacity of the delivery report channels.
+const deliveriesBufferSize = 1024
+
+// probeInterval is the period of the metadata requests checking whether the
+// brokers are back.
+const probeInterval = time.Second
+
+// KafkaPublisher is a Publisher backed by a Kafka producer. Once librdkafka
+// reports that every broker is down, Publish returns ErrUnavailable until a
+// metadata request or a delivery succeeds again.
+type KafkaPublisher struct {
+	producer   *kafka.Producer
+	deliveries chan Delivery
+	down       atomic.Bool
+	closed     chan struct{}
+}
+
+// NewKafkaPublisher returns a publisher producing to the given brokers.
+func NewKafkaPublisher(bootstrapServers string) (*KafkaPublisher, error) {
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": bootstrapServers})
+	if err != nil {
+		return nil, err
+	}
+	p := &KafkaPublisher{
+		producer:   producer,
+		deliveries: make(chan Delivery, deliveriesBufferSize),
+		closed:     make(chan struct{}),
+	}
+	go p.forwardEvents()
+	return p, nil
+}
+
+// forwardEvents turns producer delivery reports into Deliveries, and tracks
+// broker availability, until the producer is closed.
+func (p *KafkaPublisher) forwardEvents() {
+	defer close(p.deliveries)
+	for e := range p.producer.Events() {
+		switch ev := e.(type) {
+		case *kafka.Message:
+			if ev.TopicPartition.Error == nil {
+				p.down.Store(false)
+			}
+			p.deliveries <- Delivery{Message: fromKafka(ev), Err: deliveryError(ev.TopicPartition.Error)}
+		case kafka.Error:
+			if ev.Code() == kafka.ErrAllBrokersDown && !p.down.Swap(true) {
+				go p.probe()
+			}
+		}
+	}
+}
+
+// unreachable are the delivery error codes of brokers being down or
+// unreachable, or of a partition without a leader, rather than of messages
+// the brokers refuse.
+var unreachable = map[kafka.ErrorCode]bool{
+	kafka.ErrMsgTimedOut:                  true,
+	kafka.ErrTimedOut:                     true,
+	kafka.ErrTimedOutQueue:                true,
+	kafka.ErrTransport:                    true,
+	kafka.ErrAllBrokersDown:               true,
+	kafka.ErrBrokerNotAvailable:           true,
+	kafka.ErrLeaderNotAvailable:           true,
+	kafka.ErrNotLeaderForPartition:        true,
+	kafka.ErrRequestTimedOut:              true,
+	kafka.ErrNetworkException:             true,
+	kafka.ErrNotEnoughReplicas:            true,
+	kafka.ErrNotEnoughReplicasAfterAppend: true,
+}
+
+// deliveryError returns the error of a delivery report, wrapping
+// ErrUnavailable when the brokers could not be reached.
+func deliveryError(err error) error {
+	var kerr kafka.Error
+	if errors.As(err, &kerr) && unreachable[kerr.Code()] {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
+// probe requests the cluster metadata until it succeeds, which means the
+// brokers are reachable again.
+func (p *KafkaPublisher) probe() {
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+	for p.down.Load() {
+		select {
+		case <-p.closed:
+			return
+		case <-ticker.C:
+		}
+		if _, err := p.producer.GetMetadata(nil, false, int(probeInterval.Milliseconds())); err == nil {
+			p.down.Store(false)
+		}
+	}
+}
+
+func (p *KafkaPublisher) Publish(m *Message) error {
+	if p.down.Load() {
+		return ErrUnavailable
+	}
+	err := p.producer.Produce(toKafka(m), nil)
+	var kerr kafka.Error
+	if errors.As(err, &kerr) && kerr.Code() == kafka.ErrQueueFull {
+		return ErrQueueFull
+	}
+	return err
+}
+
+func (p *KafkaPublisher) Deliveries() <-chan Delivery {
+	return p.deliveries
+}
+
+func (p *KafkaPublisher) Flush(timeout time.Duration) int {
+	return p.producer.Flush(int(timeout.Milliseconds()))
+}
+
+func (p *KafkaPublisher) Close() {
+	close(p.closed)
+	p.producer.Close()
+}
+
+// KafkaSubscriber is a Subscriber backed by a Kafka consumer.
+type KafkaSubscriber struct {
+	consumer *kafka.Consumer
+}
+
+// NewKafkaSubscriber returns a subscriber in consumer group group, starting
+// from the earliest offset when the group has no committed offset.
+func NewKafkaSubscriber(bootstrapServers, group string, opts ...SubscriberOption) (*KafkaSubscriber, error) {
+	o := applyOptions(opts)
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers": bootstrapServers,
+		"group.id":          group,
+		"auto.offset.reset": "earliest",
+		// With manual commit, only the offsets stored by Commit are
+		// committed by the periodic auto commit and on Close.
+		"enable.auto.offset.store": !o.manualCommit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaSubscriber{consumer: consumer}, nil
+}
+
+func (s *KafkaSubscriber) Subscribe(topics []string) error {
+	return s.consumer.SubscribeTopics(topics, nil)
+}
+
+func (s *KafkaSubscriber) Read(timeout time.Duration) (*Message, error) {
+	return read(s.consumer, timeout)
+}
+
+func read(consumer *kafka.Consumer, timeout time.Duration) (*Message, error) {
+	msg, err := consumer.ReadMessage(timeout)
+	if err != nil {
+		if kerr, ok := err.(kafka.Error); ok && kerr.IsTimeout() {
+			return nil, ErrTimeout
+		}
+		return nil, err
+	}
+	return fromKafka(msg), nil
+}
+
+func (s *KafkaSubscriber) Commit(m *Message) error {
+	topic := m.Topic
+	_, err := s.consumer.StoreOffsets([]kafka.TopicPartition{{
+		Topic:     &topic,
+		Partition: m.Partition,
+		Offset:    kafka.Offset(m.Offset + 1),
+	}})
+	return err
+}
+
+func (s *KafkaSubscriber) Close() error {
+	return s.consumer.Close()
+}
+
+// KafkaReader is a Subscriber reading every partition of its topics from the
+// earliest offset, outside of any consumer group. Nothing is committed, so
+// it leaves no group behind on the brokers; it suits the small topics every
+// process reads in full, such as compacted ones.
+type KafkaReader struct {
+	consumer *kafka.Consumer
+	topics   []string
+	assigned bool
+}
+
+// NewKafkaReader returns a reader without topics.
+func NewKafkaReader(bootstrapServers string) (*KafkaReader, error) {
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers": bootstrapServers,
+		// Required by the client, but the group is never joined as the
+		// partitions are assigned, nor are offsets committed to it
+		"group.id":           "dns-stream-analyzer-reader",
+		"enable.auto.commit": false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaReader{consumer: consumer}, nil
+}
+
+// Subscribe replaces the topics read. Regular expressions are not
+// supported. The partitions are assigned on the next Read, which fails
+// until the topics exist.
+func (r *KafkaReader) Subscribe(topics []string) error {
+	for _, topic := range topics {
+		if strings.HasPrefix(topic, "^") {
+			return fmt.Errorf("topic patterns are not supported by the reader: %s", topic)
+		}
+	}
+	r.topics, r.assigned = topics, false
+	return nil
+}
+
+// assign assigns every partition of the topics, from the earliest offset.
+func (r *KafkaReader) assign() error {
+	var partitions []kafka.TopicPartition
+	for _, topic := range r.topics {
+		md, err := r.consumer.GetMetadata(&topic, false, 10000)
+		if err != nil {
+			return err
+		}
+		t := md.Topics[topic]
+		if t.Error.Code() != kafka.ErrNoError {
+			return fmt.Errorf("topic %s: %w", topic, t.Error)
+		}
+		if len(t.Partitions) == 0 {
+			return fmt.Errorf("topic %s has no partitions", topic)
+		}
+		for _, p := range t.Partitions {
+			partitions = append(partitions, kafka.TopicPartition{Topic: &topic, Partition: p.ID, Offset: kafka.OffsetBeginning})
+		}
+	}
+	if err := r.consumer.Assign(partitions); err != nil {
+		return err
+	}
+	r.assigned = true
+	return nil
+}
+
+func (r *KafkaReader) Read(timeout time.Duration) (*Message, error) {
+	if !r.assigned {
+		if err := r.assign(); err != nil {
+			return nil, err
+		}
+	}
+	return read(r.consumer, timeout)
+}
+
+// Commit does nothing, a reader starts from the earliest offset anyway.
+func (r *KafkaReader) Commit(m *Message) error {
+	return nil
+}
+
+func (r *KafkaReader) Close() error {
+	return r.consumer.Close()
+}
+
+func toKafka(m *Message) *kafka.Message {
+	topic := m.Topic
+	msg := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            m.Key,
+		Value:          m.Value,
+		Timestamp:      m.Timestamp,
+	}
+	for _, h := range m.Headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: h.Key, Value: h.Value})
+	}
+	return msg
+}
+
+func fromKafka(msg *kafka.Message) *Message {
+	m := &Message{
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Timestamp: msg.Timestamp,
+	}
+	if msg.TopicPartition.Topic != nil {
+		m.Topic = *msg.TopicPartition.Topic
+	}
+	for _, h := range msg.Headers {
+		m.Headers = append(m.Headers, Header{Key: h.Key, Value: h.Value})
+	}
+	return m
+}
@@ -0,0 +1,361 @@
+package bus
+
+import (
+	"hash/crc32"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process bus. Like Kafka, topics are split in partitions,
+// messages with the same key go to the same partition and keep their order,
+// each partition is read by a single member of a consumer group and each
+// group reads every message from the earliest offset. Topics are created on
+// first use with the default number of partitions.
+type Memory struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string]*memTopic
+	groups     map[string]*memGroup
+	// notify is closed, and replaced, when messages are appended or
+	// partitions are reassigned, to wake up blocked readers.
+	notify     chan struct{}
+	roundRobin int
+	// deliveryErr fails the deliveries of the messages published while it
+	// is set, see FailDeliveries.
+	deliveryErr error
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type memTopic struct {
+	logs [][]*Message
+}
+
+type memGroup struct {
+	members []*MemorySubscriber
+	// offsets are the committed offsets of the group.
+	offsets map[topicPartition]int64
+}
+
+// NewMemory returns an empty bus whose topics are created with partitions
+// partitions.
+func NewMemory(partitions int) *Memory {
+	if partitions < 1 {
+		partitions = 1
+	}
+	return &Memory{
+		partitions: partitions,
+		topics:     make(map[string]*memTopic),
+		groups:     make(map[string]*memGroup),
+		notify:     make(chan struct{}),
+	}
+}
+
+// CreateTopic creates topic with the given number of partitions if it does
+// not exist yet.
+func (m *Memory) CreateTopic(topic string, partitions int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.createTopicLocked(topic, partitions)
+}
+
+func (m *Memory) createTopicLocked(topic string, partitions int) *memTopic {
+	if t, ok := m.topics[topic]; ok {
+		return t
+	}
+	if partitions < 1 {
+		partitions = 1
+	}
+	t := &memTopic{logs: make([][]*Message, partitions)}
+	m.topics[topic] = t
+	for _, g := range m.groups {
+		g.rebalance(m.topics)
+	}
+	m.broadcastLocked()
+	return t
+}
+
+func (m *Memory) broadcastLocked() {
+	close(m.notify)
+	m.notify = make(chan struct{})
+}
+
+// FailDeliveries makes the messages published from now on fail with err in
+// their delivery reports, as Kafka reports messages it accepted but could not
+// deliver, rather than being appended to their topic. A nil err restores the
+// deliveries.
+func (m *Memory) FailDeliveries(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveryErr = err
+}
+
+// Publisher returns a new publisher on the bus.
+func (m *Memory) Publisher() *MemoryPublisher {
+	return &MemoryPublisher{bus: m, deliveries: make(chan Delivery, deliveriesBufferSize)}
+}
+
+// Subscriber returns a new subscriber member of consumer group group.
+func (m *Memory) Subscriber(group string, opts ...SubscriberOption) *MemorySubscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.groups[group]
+	if !ok {
+		g = &memGroup{offsets: make(map[topicPartition]int64)}
+		m.groups[group] = g
+	}
+	s := &MemorySubscriber{
+		bus:          m,
+		group:        g,
+		manualCommit: applyOptions(opts).manualCommit,
+		positions:    make(map[topicPartition]int64),
+	}
+	g.members = append(g.members, s)
+	return s
+}
+
+// publish appends a copy of msg to its topic and returns the copy.
+func (m *Memory) publish(msg *Message) *Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.createTopicLocked(msg.Topic, m.partitions)
+
+	var partition int
+	if msg.Key != nil {
+		partition = int(crc32.ChecksumIEEE(msg.Key) % uint32(len(t.logs)))
+	} else {
+		partition = m.roundRobin % len(t.logs)
+		m.roundRobin++
+	}
+	stored := *msg
+	stored.Partition = int32(partition)
+	stored.Offset = int64(len(t.logs[partition]))
+	if stored.Timestamp.IsZero() {
+		stored.Timestamp = time.Now()
+	}
+	t.logs[partition] = append(t.logs[partition], &stored)
+	m.broadcastLocked()
+	return &stored
+}
+
+// rebalance assigns every partition of the topics subscribed by the group to
+// exactly one of the members subscribed to the topic, round robin.
+func (g *memGroup) rebalance(topics map[string]*memTopic) {
+	for _, s := range g.members {
+		s.assigned = s.assigned[:0]
+		s.next = 0
+		// Members resume from the committed offsets, as after a Kafka
+		// rebalance
+		clear(s.positions)
+	}
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var eligible []*MemorySubscriber
+		for _, s := range g.members {
+			if s.matches(name) {
+				eligible = append(eligible, s)
+			}
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+		for p := range topics[name].logs {
+			s := eligible[p%len(eligible)]
+			s.assigned = append(s.assigned, topicPartition{topic: name, partition: int32(p)})
+		}
+	}
+}
+
+// MemoryPublisher is a Publisher on a Memory bus. Messages are delivered
+// synchronously by Publish, and so are their delivery reports.
+type MemoryPublisher struct {
+	bus        *Memory
+	mu         sync.Mutex
+	closed     bool
+	deliveries chan Delivery
+}
+
+func (p *MemoryPublisher) Publish(m *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	p.bus.mu.Lock()
+	err := p.bus.deliveryErr
+	p.bus.mu.Unlock()
+	d := Delivery{Message: m, Err: err}
+	if err == nil {
+		d.Message = p.bus.publish(m)
+	}
+	// Reports are dropped rather than blocking when nobody drains them.
+	select {
+	case p.deliveries <- d:
+	default:
+	}
+	return nil
+}
+
+func (p *MemoryPublisher) Deliveries() <-chan Delivery {
+	return p.deliveries
+}
+
+func (p *MemoryPublisher) Flush(timeout time.Duration) int {
+	return 0
+}
+
+func (p *MemoryPublisher) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.deliveries)
+	}
+}
+
+// MemorySubscriber is a Subscriber on a Memory bus.
+type MemorySubscriber struct {
+	bus      *Memory
+	group    *memGroup
+	topics   []string
+	patterns []*regexp.Regexp
+	assigned []topicPartition
+	next     int
+	closed   bool
+	// positions are the offsets of the next messages to read, they are
+	// committed on read unless manualCommit is set.
+	positions    map[topicPartition]int64
+	manualCommit bool
+}
+
+func (s *MemorySubscriber) matches(topic string) bool {
+	for _, t := range s.topics {
+		if t == topic {
+			return true
+		}
+	}
+	for _, re := range s.patterns {
+		if re.MatchString(topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemorySubscriber) Subscribe(topics []string) error {
+	var names []string
+	var patterns []*regexp.Regexp
+	for _, t := range topics {
+		if strings.HasPrefix(t, "^") {
+			re, err := regexp.Compile(t)
+			if err != nil {
+				return err
+			}
+			patterns = append(patterns, re)
+		} else {
+			names = append(names, t)
+		}
+	}
+
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.topics, s.patterns = names, patterns
+	s.group.rebalance(s.bus.topics)
+	s.bus.broadcastLocked()
+	return nil
+}
+
+func (s *MemorySubscriber) Read(timeout time.Duration) (*Message, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.bus.mu.Lock()
+		if s.closed {
+			s.bus.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if msg := s.nextLocked(); msg != nil {
+			s.bus.mu.Unlock()
+			return msg, nil
+		}
+		notify := s.bus.notify
+		s.bus.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return nil, ErrTimeout
+		}
+	}
+}
+
+// nextLocked returns the next unread message of the assigned partitions,
+// visiting them round robin so that one busy partition does not starve the
+// others.
+func (s *MemorySubscriber) nextLocked() *Message {
+	for i := range s.assigned {
+		idx := (s.next + i) % len(s.assigned)
+		tp := s.assigned[idx]
+		log := s.bus.topics[tp.topic].logs[tp.partition]
+		offset, ok := s.positions[tp]
+		if !ok {
+			offset = s.group.offsets[tp]
+		}
+		if offset < int64(len(log)) {
+			s.positions[tp] = offset + 1
+			if !s.manualCommit {
+				s.group.offsets[tp] = offset + 1
+			}
+			s.next = idx + 1
+			msg := *log[offset]
+			return &msg
+		}
+	}
+	return nil
+}
+
+func (s *MemorySubscriber) Commit(m *Message) error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	tp := topicPartition{topic: m.Topic, partition: m.Partition}
+	if m.Offset+1 > s.group.offsets[tp] {
+		s.group.offsets[tp] = m.Offset + 1
+	}
+	return nil
+}
+
+// Close leaves the consumer group, its partitions are reassigned to the
+// remaining members.
+func (s *MemorySubscriber) Close() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for i, member := range s.group.members {
+		if member == s {
+			s.group.members = append(s.group.members[:i], s.group.members[i+1:]...)
+			break
+		}
+	}
+	s.group.rebalance(s.bus.topics)
+	s.bus.broadcastLocked()
+	return nil
+}
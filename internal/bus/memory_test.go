@@ -0,0 +1,166 @@
+package bus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll reads messages until the subscriber has nothing left.
+func readAll(t *testing.T, s Subscriber) []*Message {
+	var msgs []*Message
+	for {
+		msg, err := s.Read(20 * time.Millisecond)
+		if err == ErrTimeout {
+			return msgs
+		}
+		require.NoError(t, err)
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestMemoryKeyOrdering(t *testing.T) {
+	b := NewMemory(3)
+	p := b.Publisher()
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("10.0.0.%d", i%5)
+		require.NoError(t, p.Publish(&Message{Topic: "dns", Key: []byte(key), Value: []byte(fmt.Sprint(i))}))
+	}
+
+	s := b.Subscriber("group")
+	require.NoError(t, s.Subscribe([]string{"dns"}))
+	msgs := readAll(t, s)
+	require.Len(t, msgs, 30)
+
+	partitions := map[string]int32{}
+	last := map[string]int{}
+	for _, msg := range msgs {
+		key := string(msg.Key)
+		if p, ok := partitions[key]; ok {
+			assert.Equal(t, p, msg.Partition, "key %s changed partition", key)
+		}
+		partitions[key] = msg.Partition
+		var i int
+		fmt.Sscan(string(msg.Value), &i)
+		if prev, ok := last[key]; ok {
+			assert.Greater(t, i, prev, "key %s out of order", key)
+		}
+		last[key] = i
+	}
+}
+
+func TestMemoryConsumerGroups(t *testing.T) {
+	b := NewMemory(4)
+	b.CreateTopic("dns", 4)
+	p := b.Publisher()
+
+	a1 := b.Subscriber("a")
+	a2 := b.Subscriber("a")
+	other := b.Subscriber("b")
+	for _, s := range []Subscriber{a1, a2, other} {
+		require.NoError(t, s.Subscribe([]string{"dns"}))
+	}
+
+	for i := 0; i < 40; i++ {
+		require.NoError(t, p.Publish(&Message{Topic: "dns", Key: []byte(fmt.Sprint(i))}))
+	}
+
+	fromA1, fromA2 := readAll(t, a1), readAll(t, a2)
+	assert.Len(t, append(fromA1, fromA2...), 40, "group a reads every message once")
+	assert.NotEmpty(t, fromA1)
+	assert.NotEmpty(t, fromA2)
+	seen := map[int32]bool{}
+	for _, msg := range fromA1 {
+		seen[msg.Partition] = true
+	}
+	for _, msg := range fromA2 {
+		assert.False(t, seen[msg.Partition], "partition %d read by both members", msg.Partition)
+	}
+	assert.Len(t, readAll(t, other), 40, "group b reads every message too")
+}
+
+func TestMemoryRebalanceOnClose(t *testing.T) {
+	b := NewMemory(2)
+	p := b.Publisher()
+	s1 := b.Subscriber("group")
+	s2 := b.Subscriber("group")
+	require.NoError(t, s1.Subscribe([]string{"dns"}))
+	require.NoError(t, s2.Subscribe([]string{"dns"}))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, p.Publish(&Message{Topic: "dns", Key: []byte(fmt.Sprint(i))}))
+	}
+	first := readAll(t, s1)
+	require.NoError(t, s2.Close())
+
+	// s1 takes over the partitions of s2 from the group's committed offsets.
+	rest := readAll(t, s1)
+	assert.Len(t, append(first, rest...), 10)
+
+	_, err := s2.Read(time.Millisecond)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestMemoryReadWaitsForPublish(t *testing.T) {
+	b := NewMemory(1)
+	s := b.Subscriber("group")
+	require.NoError(t, s.Subscribe([]string{"^dns-.*"}))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		b.Publisher().Publish(&Message{Topic: "dns-events", Value: []byte("hello")})
+	}()
+	msg, err := s.Read(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "dns-events", msg.Topic)
+	assert.Equal(t, "hello", string(msg.Value))
+	assert.False(t, msg.Timestamp.IsZero())
+}
+
+func TestMemoryDeliveries(t *testing.T) {
+	b := NewMemory(1)
+	p := b.Publisher()
+	require.NoError(t, p.Publish(&Message{Topic: "dns", Value: []byte("a")}))
+	d := <-p.Deliveries()
+	assert.NoError(t, d.Err)
+	assert.Equal(t, int64(0), d.Message.Offset)
+
+	// Failed deliveries are reported, and the messages are not appended
+	b.FailDeliveries(ErrUnavailable)
+	require.NoError(t, p.Publish(&Message{Topic: "dns", Value: []byte("b")}))
+	d = <-p.Deliveries()
+	assert.ErrorIs(t, d.Err, ErrUnavailable)
+	assert.Equal(t, "b", string(d.Message.Value))
+	b.FailDeliveries(nil)
+	s := b.Subscriber("g")
+	require.NoError(t, s.Subscribe([]string{"dns"}))
+	assert.Len(t, readAll(t, s), 1)
+
+	p.Close()
+	assert.ErrorIs(t, p.Publish(&Message{Topic: "dns"}), ErrClosed)
+}
+
+func TestMemoryManualCommit(t *testing.T) {
+	b := NewMemory(1)
+	p := b.Publisher()
+	for _, v := range []string{"a", "b", "c"} {
+		require.NoError(t, p.Publish(&Message{Topic: "dlq", Value: []byte(v)}))
+	}
+
+	s := b.Subscriber("replay", ManualCommit())
+	require.NoError(t, s.Subscribe([]string{"dlq"}))
+	msgs := readAll(t, s)
+	require.Len(t, msgs, 3)
+	require.NoError(t, s.Commit(msgs[0]))
+	require.NoError(t, s.Close())
+
+	// Only "a" was committed, the next member of the group resumes at "b"
+	s = b.Subscriber("replay", ManualCommit())
+	require.NoError(t, s.Subscribe([]string{"dlq"}))
+	msgs = readAll(t, s)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "b", string(msgs[0].Value))
+}
@@ -0,0 +1,85 @@
+// Package deadletter builds the messages of the dead-letter topic, which
+// keeps the events that could not be delivered or processed together with
+// headers describing the failure, and turns them back into the original
+// events for replay.
+package deadletter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+)
+
+// Headers added to dead letters.
+const (
+	HeaderTopic     = "dlq.original.topic"
+	HeaderPartition = "dlq.original.partition"
+	HeaderOffset    = "dlq.original.offset"
+	HeaderError     = "dlq.error"
+	// HeaderAttempts counts the failures of the event, it is kept when the
+	// event is replayed so that a new failure increments it.
+	HeaderAttempts = "dlq.attempts"
+	HeaderFailedAt = "dlq.failed.at"
+	// HeaderSource is the component that dead-lettered the event.
+	HeaderSource = "dlq.source"
+)
+
+const headerPrefix = "dlq."
+
+// New returns the dead letter, to publish on topic, of msg which failed
+// with err in component source.
+func New(topic string, msg *bus.Message, source string, err error) *bus.Message {
+	headers := withoutDeadLetterHeaders(msg.Headers)
+	headers = append(headers,
+		bus.Header{Key: HeaderTopic, Value: []byte(msg.Topic)},
+		bus.Header{Key: HeaderPartition, Value: []byte(strconv.Itoa(int(msg.Partition)))},
+		bus.Header{Key: HeaderOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		bus.Header{Key: HeaderError, Value: []byte(err.Error())},
+		bus.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(Attempts(msg) + 1))},
+		bus.Header{Key: HeaderFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+		bus.Header{Key: HeaderSource, Value: []byte(source)},
+	)
+	return &bus.Message{Topic: topic, Key: msg.Key, Value: msg.Value, Headers: headers}
+}
+
+// Original returns the event to re-inject for the dead letter msg, on its
+// original topic with its original key, value and headers.
+func Original(msg *bus.Message) (*bus.Message, error) {
+	topic := Header(msg, HeaderTopic)
+	if topic == "" {
+		return nil, fmt.Errorf("dead letter without %s header", HeaderTopic)
+	}
+	headers := withoutDeadLetterHeaders(msg.Headers)
+	headers = append(headers, bus.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(Attempts(msg)))})
+	return &bus.Message{Topic: topic, Key: msg.Key, Value: msg.Value, Headers: headers}, nil
+}
+
+// Attempts returns the number of times msg failed, 0 for a fresh event.
+func Attempts(msg *bus.Message) int {
+	n, _ := strconv.Atoi(Header(msg, HeaderAttempts))
+	return n
+}
+
+// Header returns the last value of header key of msg, or "".
+func Header(msg *bus.Message, key string) string {
+	value := ""
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			value = string(h.Value)
+		}
+	}
+	return value
+}
+
+func withoutDeadLetterHeaders(headers []bus.Header) []bus.Header {
+	var kept []bus.Header
+	for _, h := range headers {
+		if !strings.HasPrefix(h.Key, headerPrefix) {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
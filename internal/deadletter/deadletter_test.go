@@ -0,0 +1,49 @@
+package deadletter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAndOriginal(t *testing.T) {
+	msg := &bus.Message{
+		Topic:     "myTopic",
+		Partition: 2,
+		Offset:    41,
+		Key:       []byte("10.0.0.1"),
+		Value:     []byte("garbage"),
+		Headers:   []bus.Header{{Key: "trace", Value: []byte("abc")}},
+	}
+
+	dead := New("myTopic.dlq", msg, "consumer", errors.New("no IP address in message"))
+	assert.Equal(t, "myTopic.dlq", dead.Topic)
+	assert.Equal(t, msg.Key, dead.Key)
+	assert.Equal(t, msg.Value, dead.Value)
+	assert.Equal(t, "myTopic", Header(dead, HeaderTopic))
+	assert.Equal(t, "2", Header(dead, HeaderPartition))
+	assert.Equal(t, "41", Header(dead, HeaderOffset))
+	assert.Equal(t, "no IP address in message", Header(dead, HeaderError))
+	assert.Equal(t, "consumer", Header(dead, HeaderSource))
+	assert.Equal(t, 1, Attempts(dead))
+
+	replayed, err := Original(dead)
+	require.NoError(t, err)
+	assert.Equal(t, "myTopic", replayed.Topic)
+	assert.Equal(t, msg.Value, replayed.Value)
+	assert.Equal(t, "abc", Header(replayed, "trace"))
+	assert.Equal(t, "", Header(replayed, HeaderError))
+
+	// Failing again after the replay increments the attempts
+	dead = New("myTopic.dlq", replayed, "consumer", errors.New("still broken"))
+	assert.Equal(t, 2, Attempts(dead))
+	assert.Equal(t, "still broken", Header(dead, HeaderError))
+}
+
+func TestOriginalWithoutHeaders(t *testing.T) {
+	_, err := Original(&bus.Message{Topic: "myTopic.dlq"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+// Package dga scores domain names for algorithmic generation, as done by
+// malware looking for its command and control servers among thousands of
+// generated names. The registrable label of a name ("example" for
+// www.example.co.uk) is scored on its Shannon entropy, its longest run of
+// consonants, its ratio of digits and its likelihood under a character
+// trigram model of benign names.
+//
+// A model trained on the English words of benign.txt is bundled. Models are
+// trained offline with "dnsctl dga-train" on a list of domains, such as a
+// top sites list, or of words.
+package dga
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+//go:generate go run ../../cmd/dnsctl dga-train -in benign.txt -out model.json
+
+//go:embed model.json
+var bundled []byte
+
+// MinLength is the length under which labels are too short to be judged,
+// they score 0.
+const MinLength = 6
+
+// alphabet is the number of symbols a trigram can end with: letters,
+// digits, hyphen and the end of the label.
+const alphabet = 26 + 10 + 1 + 1
+
+// Model is a character trigram model of benign labels. Labels are padded
+// with "^^" and "$", so that trigrams also capture how labels start and end.
+type Model struct {
+	// Trigrams counts the trigrams of the training labels.
+	Trigrams map[string]uint32 `json:"trigrams"`
+	// Labels is the number of training labels.
+	Labels int `json:"labels"`
+
+	// bigrams counts the contexts of the trigrams.
+	bigrams map[string]uint32
+}
+
+var defaultModel atomic.Pointer[Model]
+
+func init() {
+	m, err := Read(bytes.NewReader(bundled))
+	if err != nil {
+		panic("dga: invalid bundled model: " + err.Error())
+	}
+	defaultModel.Store(m)
+}
+
+// Default returns the model used by Score, the bundled one unless replaced
+// with SetDefault.
+func Default() *Model {
+	return defaultModel.Load()
+}
+
+// SetDefault replaces the model used by Score.
+func SetDefault(m *Model) {
+	defaultModel.Store(m)
+}
+
+// Train returns the model of the domains listed in r, one per line. Lines
+// may also be CSV records ending with the domain, as in top sites lists
+// ("1,google.com"), or bare labels, such as the words of a word list. Empty
+// lines and lines starting with # are skipped.
+func Train(r io.Reader) (*Model, error) {
+	m := &Model{Trigrams: make(map[string]uint32)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.LastIndexByte(line, ','); i >= 0 {
+			line = strings.TrimSpace(line[i+1:])
+		}
+		label := Label(line)
+		if !strings.Contains(line, ".") {
+			label = bareLabel(line)
+		}
+		if label == "" {
+			continue
+		}
+		m.Labels++
+		padded := "^^" + label + "$"
+		for i := 0; i+3 <= len(padded); i++ {
+			m.Trigrams[padded[i:i+3]]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if m.Labels == 0 {
+		return nil, fmt.Errorf("no domains to train on")
+	}
+	m.index()
+	return m, nil
+}
+
+// Read reads a model written by Write.
+func Read(r io.Reader) (*Model, error) {
+	m := &Model{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("invalid DGA model: %w", err)
+	}
+	if m.Labels == 0 || len(m.Trigrams) == 0 {
+		return nil, fmt.Errorf("invalid DGA model: no trigrams")
+	}
+	m.index()
+	return m, nil
+}
+
+// Load reads the model of the file at path.
+func Load(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Write writes m as JSON.
+func (m *Model) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+func (m *Model) index() {
+	m.bigrams = make(map[string]uint32)
+	for t, n := range m.Trigrams {
+		m.bigrams[t[:2]] += n
+	}
+}
+
+// Likelihood returns the mean log2 probability of the characters of label
+// under m, with add-one smoothing: about -5.25 for random characters, closer
+// to 0 for labels that look like the training ones.
+func (m *Model) Likelihood(label string) float64 {
+	padded := "^^" + label + "$"
+	sum := 0.0
+	n := 0
+	for i := 0; i+3 <= len(padded); i++ {
+		t := padded[i : i+3]
+		sum += math.Log2(float64(m.Trigrams[t]+1) / float64(m.bigrams[t[:2]]+alphabet))
+		n++
+	}
+	return sum / float64(n)
+}
+
+// Label returns the registrable label of domain, lower case: "example" for
+// www.example.co.uk. It is empty for names without one, such as public
+// suffixes.
+func Label(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	registered, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return ""
+	}
+	label, _, _ := strings.Cut(registered, ".")
+	return label
+}
+
+// bareLabel returns s in lower case if it is made of letters, digits and
+// hyphens only, and an empty string otherwise.
+func bareLabel(s string) string {
+	s = strings.ToLower(s)
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return ""
+		}
+	}
+	return s
+}
+
+// Features are the statistics of a label.
+type Features struct {
+	Label string `json:"label"`
+	// Entropy is the Shannon entropy of the characters, in bits.
+	Entropy float64 `json:"entropy"`
+	// ConsonantRun is the length of the longest run of consonants.
+	ConsonantRun int `json:"consonant_run"`
+	// DigitRatio is the share of digits.
+	DigitRatio float64 `json:"digit_ratio"`
+	// Likelihood is given by Model.Likelihood.
+	Likelihood float64 `json:"likelihood"`
+}
+
+// Analyze returns the features of the registrable label of domain.
+func (m *Model) Analyze(domain string) Features {
+	f := Features{Label: Label(domain)}
+	if f.Label == "" {
+		return f
+	}
+	counts := make(map[rune]int)
+	run, digits := 0, 0
+	for _, c := range f.Label {
+		counts[c]++
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+			run = 0
+		case c >= 'a' && c <= 'z' && !strings.ContainsRune("aeiouy", c):
+			run++
+			f.ConsonantRun = max(f.ConsonantRun, run)
+		default:
+			run = 0
+		}
+	}
+	n := float64(len(f.Label))
+	for _, k := range counts {
+		p := float64(k) / n
+		f.Entropy -= p * math.Log2(p)
+	}
+	f.DigitRatio = float64(digits) / n
+	f.Likelihood = m.Likelihood(f.Label)
+	return f
+}
+
+// Score returns how likely the features are those of a generated label,
+// from 0 to 1: most generated labels score above 0.6, most benign ones below
+// 0.4. Labels shorter than MinLength, and internationalized labels, whose
+// punycode form does not look like words, score 0.
+func (f Features) Score() float64 {
+	if len(f.Label) < MinLength || strings.HasPrefix(f.Label, "xn--") {
+		return 0
+	}
+	// Random labels have entropies close to log2 of their length, or of
+	// the alphabet for long ones, words fall a bit below
+	maxEntropy := math.Log2(math.Min(float64(len(f.Label)), 36))
+	entropy := clamp((f.Entropy/maxEntropy - 0.85) / 0.12)
+	consonants := clamp(float64(f.ConsonantRun-3) / 4)
+	digits := clamp(f.DigitRatio / 0.4)
+	// Benign labels average about -4 bits per character, random ones -5.5
+	// to -6
+	likelihood := clamp((-f.Likelihood - 4.3) / 1.2)
+	return 0.15*entropy + 0.15*consonants + 0.15*digits + 0.55*likelihood
+}
+
+// Score returns the score of domain under m, see Features.Score.
+func (m *Model) Score(domain string) float64 {
+	return m.Analyze(domain).Score()
+}
+
+// Score returns the score of domain under the default model.
+func Score(domain string) float64 {
+	return Default().Score(domain)
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,69 @@
+package dga
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabel(t *testing.T) {
+	for in, want := range map[string]string{
+		"www.example.co.uk": "example",
+		"Example.COM.":      "example",
+		"a.b.c.example.org": "example",
+		"co.uk":             "",
+		"localhost":         "",
+		"":                  "",
+	} {
+		assert.Equal(t, want, Label(in), in)
+	}
+}
+
+func TestTrain(t *testing.T) {
+	m, err := Train(strings.NewReader("# top sites\n1,google.com\n2,www.github.com\n\nwikipedia.org\nco.uk\nGrub\nnot a word\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, m.Labels)
+	assert.EqualValues(t, 3, m.Trigrams["^^g"])
+	assert.EqualValues(t, 2, m.Trigrams["ub$"])
+	assert.Greater(t, m.Likelihood("google"), m.Likelihood("xkqzvw"))
+
+	var buf bytes.Buffer
+	require.NoError(t, m.Write(&buf))
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, m.Likelihood("github"), read.Likelihood("github"))
+
+	_, err = Train(strings.NewReader("# nothing\n"))
+	assert.Error(t, err)
+	_, err = Read(strings.NewReader(`{"labels": 0}`))
+	assert.Error(t, err)
+}
+
+func TestScore(t *testing.T) {
+	for _, d := range []string{
+		"google.com", "facebook.com", "stackoverflow.com", "bankofamerica.com",
+		"theweathernetwork.com", "nationalgeographic.com", "letsencrypt.org",
+		"cloudflare-dns.com", "mybestshop.net", "1password.com",
+		// Too short, or internationalized
+		"x7k.com", "xn--bcher-kva.de",
+	} {
+		assert.Less(t, Score(d), 0.4, d)
+	}
+	for _, d := range []string{
+		"xjw8qk2lpz7v.com", "qwhpzkvnrtdl.net", "4f8a9c2e1d7b.com",
+		"ocufxsuwpwqg.ru", "vgbunhmrfdtre.biz", "uwpyvpqxbxkdfae.com",
+		"www.1q2w3e4r5t6y.info",
+	} {
+		assert.Greater(t, Score(d), 0.6, d)
+	}
+
+	f := Default().Analyze("www.a1b2xyzq.com")
+	assert.Equal(t, "a1b2xyzq", f.Label)
+	// y counts as a vowel
+	assert.Equal(t, 2, f.ConsonantRun)
+	assert.Equal(t, 0.25, f.DigitRatio)
+	assert.Equal(t, 3.0, f.Entropy)
+}
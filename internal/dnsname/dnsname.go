@@ -0,0 +1,70 @@
+// Package dnsname validates and normalizes the domain names received by the
+// server.
+package dnsname
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	// MaxLength is the maximum length of a name in presentation format,
+	// without the trailing dot (RFC 1035 limits names to 255 octets on the
+	// wire).
+	MaxLength = 253
+	// MaxLabelLength is the maximum length of a label.
+	MaxLabelLength = 63
+)
+
+// profile maps Unicode names to their lower case punycode form. Underscores
+// are allowed, as in service labels like _dmarc.
+var profile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.StrictDomainName(false),
+)
+
+// Normalize returns the canonical form of domain: ASCII, lower case, Unicode
+// labels converted to punycode, without the trailing dot. It fails if a label
+// is empty or longer than 63 characters, if the name is longer than 253
+// characters, or if it has characters other than letters, digits, hyphens
+// and underscores.
+func Normalize(domain string) (string, error) {
+	name := strings.TrimSuffix(strings.TrimSpace(domain), ".")
+	if name == "" {
+		return "", fmt.Errorf("empty domain name")
+	}
+	ascii, err := profile.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid domain name %q: %v", domain, err)
+	}
+	ascii = strings.ToLower(ascii)
+	if len(ascii) > MaxLength {
+		return "", fmt.Errorf("invalid domain name %q: longer than %d characters", domain, MaxLength)
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if err := checkLabel(label); err != nil {
+			return "", fmt.Errorf("invalid domain name %q: %v", domain, err)
+		}
+	}
+	return ascii, nil
+}
+
+func checkLabel(label string) error {
+	switch {
+	case label == "":
+		return fmt.Errorf("empty label")
+	case len(label) > MaxLabelLength:
+		return fmt.Errorf("label %q longer than %d characters", label, MaxLabelLength)
+	case label[0] == '-' || label[len(label)-1] == '-':
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	for _, c := range label {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Errorf("label %q has invalid character %q", label, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package dnsname
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"example.com":                    "example.com",
+		"WWW.Example.COM.":               "www.example.com",
+		" example.com ":                  "example.com",
+		"_dmarc.example.com":             "_dmarc.example.com",
+		"bücher.example":                 "xn--bcher-kva.example",
+		"BÜCHER.example":                 "xn--bcher-kva.example",
+		"xn--bcher-kva.example":          "xn--bcher-kva.example",
+		"例え.テスト":                         "xn--r8jz45g.xn--zckzah",
+		"localhost":                      "localhost",
+		strings.Repeat("a", 63) + ".com": strings.Repeat("a", 63) + ".com",
+	} {
+		got, err := Normalize(in)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, want, got, in)
+		}
+	}
+
+	long := strings.Repeat(strings.Repeat("a", 63)+".", 4) + "com"
+	for _, in := range []string{
+		"",
+		".",
+		"a..b",
+		".example.com",
+		strings.Repeat("a", 64) + ".com",
+		long,
+		"-bad.com",
+		"bad-.com",
+		"exa mple.com",
+		"exa$mple.com",
+		"exa/mple.com",
+	} {
+		_, err := Normalize(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,77 @@
+// Package event encodes the DNS request events published by the server on
+// the event bus and decodes them in the consumers.
+//
+// Events are pb.DnsRequest messages in protobuf JSON, with the proto field
+// names, so that they stay readable with the Kafka console tools and carry
+// every telemetry field. Decode also accepts the text events published by
+// older servers:
+//
+//	IP: <ip>, Domain: <domain>, QueryType: <query type>, Timestamp: <unix>
+package event
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/qtype"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// ContentType is the value of the content-type header of encoded events.
+const ContentType = "application/vnd.dns-request+json"
+
+var (
+	marshal   = protojson.MarshalOptions{UseProtoNames: true}
+	unmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// Encode returns the event of req.
+func Encode(req *pb.DnsRequest) ([]byte, error) {
+	return marshal.Marshal(req)
+}
+
+// Decode parses an event, in JSON or in the legacy text format.
+func Decode(value []byte) (*pb.DnsRequest, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+		req := &pb.DnsRequest{}
+		if err := unmarshal.Unmarshal(value, req); err != nil {
+			return nil, fmt.Errorf("invalid event: %w", err)
+		}
+		return req, nil
+	}
+	return decodeText(string(value))
+}
+
+// decodeText parses a legacy text event.
+func decodeText(value string) (*pb.DnsRequest, error) {
+	req := &pb.DnsRequest{}
+	for _, field := range strings.Split(value, ", ") {
+		name, v, ok := strings.Cut(field, ": ")
+		if !ok {
+			return nil, fmt.Errorf("invalid event field %q", field)
+		}
+		switch name {
+		case "IP":
+			req.IpAddress = v
+		case "Domain":
+			req.Domain = v
+		case "QueryType":
+			// Older servers forwarded any string, keep unknown types
+			// rather than dropping the event
+			req.QueryType, _ = qtype.Parse(v)
+		case "Timestamp":
+			ts, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid event timestamp %q", v)
+			}
+			req.Timestamp = ts
+		}
+	}
+	if req.GetIpAddress() == "" {
+		return nil, fmt.Errorf("no IP address in event")
+	}
+	return req, nil
+}
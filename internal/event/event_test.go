@@ -0,0 +1,61 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	req := &pb.DnsRequest{
+		IpAddress: "10.0.0.1",
+		Domain:    "example.com",
+		QueryType: pb.QueryType_QUERY_TYPE_A,
+		Timestamp: 1700000000,
+		Rcode:     pb.ResponseCode_RESPONSE_CODE_NXDOMAIN.Enum(),
+		Answers: []*pb.Answer{
+			{Type: pb.QueryType_QUERY_TYPE_A, Data: "93.184.216.34", Ttl: 300},
+			{Type: pb.QueryType(65534), Data: "\\# 0", Ttl: 60},
+		},
+		ClientPort:       53000,
+		Transport:        pb.Transport_TRANSPORT_DOH,
+		QuerySize:        40,
+		ResponseSize:     512,
+		EdnsClientSubnet: "203.0.113.0/24",
+		SensorId:         "resolver-1",
+	}
+	value, err := Encode(req)
+	require.NoError(t, err)
+	assert.Contains(t, string(value), `"ip_address"`)
+
+	got, err := Decode(value)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(req, got), "got %v", got)
+
+	// NOERROR is distinguished from a missing response code
+	value, err = Encode(&pb.DnsRequest{IpAddress: "10.0.0.1", Rcode: pb.ResponseCode_RESPONSE_CODE_NOERROR.Enum()})
+	require.NoError(t, err)
+	got, err = Decode(value)
+	require.NoError(t, err)
+	assert.True(t, got.Rcode != nil)
+	got, err = Decode([]byte(`{"ip_address": "10.0.0.1"}`))
+	require.NoError(t, err)
+	assert.Nil(t, got.Rcode)
+}
+
+func TestDecodeLegacyText(t *testing.T) {
+	got, err := Decode([]byte("IP: 192.168.1.70, Domain: test.com, QueryType: AAAA, Timestamp: 42"))
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.70", got.GetIpAddress())
+	assert.Equal(t, "test.com", got.GetDomain())
+	assert.Equal(t, pb.QueryType_QUERY_TYPE_AAAA, got.GetQueryType())
+	assert.Equal(t, int64(42), got.GetTimestamp())
+
+	for _, value := range []string{"", "garbage", "Domain: test.com", "IP: 10.0.0.1, Timestamp: soon", `{"ip_address": 1}`} {
+		_, err := Decode([]byte(value))
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,314 @@
+// Package firewall renders the blacklist as the rule sets of Linux
+// firewalls, so that the blocks decided by the server are also enforced at
+// the network level.
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+)
+
+// Formats of the rendered rule sets.
+const (
+	// FormatNftables is an nft -f script replacing a table with one set
+	// per address family and the chain dropping their sources.
+	FormatNftables = "nftables"
+	// FormatIpset is an ipset restore file filling one hash:net set per
+	// address family, swapped in atomically.
+	FormatIpset = "ipset"
+	// FormatIptables and FormatIp6tables are iptables-restore --noflush
+	// scripts replacing a chain dropping the IPv4, or IPv6, sources.
+	FormatIptables  = "iptables"
+	FormatIp6tables = "ip6tables"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{FormatNftables, FormatIpset, FormatIptables, FormatIp6tables}
+
+// DefaultName is the name of the nftables table, of the ipset sets (with a
+// _v4 or _v6 suffix) and, in upper case, of the iptables chain.
+const DefaultName = "dns_blocklist"
+
+// Entry is a blocked IP, or a blocked CIDR.
+type Entry struct {
+	Prefix netip.Prefix
+	Reason string
+	// ExpiresAt is zero when the block never expires.
+	ExpiresAt time.Time
+}
+
+// ParseEntry returns the entry of an IP or a CIDR.
+func ParseEntry(source, reason string, expiresAt time.Time) (Entry, error) {
+	if addr, err := ipaddr.Parse(source); err == nil {
+		return Entry{Prefix: netip.PrefixFrom(addr, addr.BitLen()), Reason: reason, ExpiresAt: expiresAt}, nil
+	}
+	prefix, err := ipaddr.ParsePrefix(source)
+	if err != nil {
+		return Entry{}, fmt.Errorf("%q is neither an IP nor a CIDR", source)
+	}
+	return Entry{Prefix: prefix, Reason: reason, ExpiresAt: expiresAt}, nil
+}
+
+// Render returns the rule set of the entries unexpired at now in the given
+// format. name defaults to DefaultName.
+func Render(format, name string, entries []Entry, now time.Time) ([]byte, error) {
+	if name == "" {
+		name = DefaultName
+	}
+	v4, v6 := prepare(entries, now)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Blocklist generated by DNS-Stream-Analyzer, do not edit.\n")
+	switch format {
+	case FormatNftables:
+		renderNftables(&b, name, v4, v6, now)
+	case FormatIpset:
+		renderIpset(&b, name, v4, v6, now)
+	case FormatIptables:
+		renderIptables(&b, strings.ToUpper(name), v4)
+	case FormatIp6tables:
+		renderIptables(&b, strings.ToUpper(name), v6)
+	default:
+		return nil, fmt.Errorf("unknown firewall format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+	return b.Bytes(), nil
+}
+
+// prepare drops the expired entries, and those covered by an entry lasting
+// at least as long, and returns the others sorted by family.
+func prepare(entries []Entry, now time.Time) (v4, v6 []Entry) {
+	var live []Entry
+	for _, e := range entries {
+		if e.Prefix.IsValid() && (e.ExpiresAt.IsZero() || now.Before(e.ExpiresAt)) {
+			live = append(live, e)
+		}
+	}
+	// Wider prefixes first, so that covering entries come before those
+	// they cover
+	sort.Slice(live, func(i, j int) bool {
+		if live[i].Prefix.Bits() != live[j].Prefix.Bits() {
+			return live[i].Prefix.Bits() < live[j].Prefix.Bits()
+		}
+		return live[i].Prefix.Addr().Less(live[j].Prefix.Addr())
+	})
+	var kept []Entry
+	for _, e := range live {
+		covered := false
+		for _, k := range kept {
+			if k.Prefix.Contains(e.Prefix.Addr()) && k.Prefix.Bits() <= e.Prefix.Bits() && outlasts(k, e) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, e)
+		}
+	}
+	sortByAddr(kept)
+	for _, e := range kept {
+		if e.Prefix.Addr().Is4() {
+			v4 = append(v4, e)
+		} else {
+			v6 = append(v6, e)
+		}
+	}
+	return v4, v6
+}
+
+// sortByAddr sorts the entries by address, then by prefix length.
+func sortByAddr(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if a, b := entries[i].Prefix.Addr(), entries[j].Prefix.Addr(); a != b {
+			return a.Less(b)
+		}
+		return entries[i].Prefix.Bits() < entries[j].Prefix.Bits()
+	})
+}
+
+// disjoint returns the entries split so that no two of them overlap, for
+// the interval sets of nftables which reject overlapping elements. Every
+// address keeps the longest block of the entries covering it: the parts
+// of a CIDR covered by an entry outliving it are carved out of the CIDR.
+func disjoint(entries []Entry) []Entry {
+	longest := append([]Entry(nil), entries...)
+	sort.SliceStable(longest, func(i, j int) bool {
+		return outlasts(longest[i], longest[j]) && !outlasts(longest[j], longest[i])
+	})
+	var out []Entry
+	for _, e := range longest {
+		parts := []netip.Prefix{e.Prefix.Masked()}
+		for _, o := range out {
+			var rest []netip.Prefix
+			for _, p := range parts {
+				rest = append(rest, subtract(p, o.Prefix)...)
+			}
+			parts = rest
+		}
+		for _, p := range parts {
+			out = append(out, Entry{Prefix: p, Reason: e.Reason, ExpiresAt: e.ExpiresAt})
+		}
+	}
+	sortByAddr(out)
+	return out
+}
+
+// subtract returns the prefixes covering the addresses of p outside q.
+func subtract(p, q netip.Prefix) []netip.Prefix {
+	if !p.Overlaps(q) {
+		return []netip.Prefix{p}
+	}
+	if q.Bits() <= p.Bits() {
+		return nil
+	}
+	// Split p in halves, one of which holds q
+	bits := p.Bits() + 1
+	high := p.Addr().AsSlice()
+	high[p.Bits()/8] |= 0x80 >> (p.Bits() % 8)
+	addr, _ := netip.AddrFromSlice(high)
+	return append(subtract(netip.PrefixFrom(p.Addr(), bits), q), subtract(netip.PrefixFrom(addr, bits), q)...)
+}
+
+// outlasts tells whether a expires no earlier than b.
+func outlasts(a, b Entry) bool {
+	return a.ExpiresAt.IsZero() || (!b.ExpiresAt.IsZero() && !a.ExpiresAt.Before(b.ExpiresAt))
+}
+
+// timeout returns the remaining seconds of the entry, 0 if it never
+// expires.
+func timeout(e Entry, now time.Time) int64 {
+	if e.ExpiresAt.IsZero() {
+		return 0
+	}
+	// Round up, so that a block never ends before the server's
+	sec := int64((e.ExpiresAt.Sub(now) + time.Second - 1) / time.Second)
+	return max(sec, 1)
+}
+
+// comment returns the reason of the entry as a double quoted string, safe in
+// every format.
+func comment(e Entry) string {
+	r := strings.Map(func(c rune) rune {
+		if c == '"' || c == '\\' || c < ' ' || c == 0x7f {
+			return -1
+		}
+		return c
+	}, e.Reason)
+	// ipset and iptables comments are limited to 255 bytes
+	if len(r) > 200 {
+		r = strings.ToValidUTF8(r[:200], "")
+	}
+	return `"` + r + `"`
+}
+
+func renderNftables(b *bytes.Buffer, name string, v4, v6 []Entry, now time.Time) {
+	v4, v6 = disjoint(v4), disjoint(v6)
+	// Declaring the table before deleting it makes the deletion succeed
+	// on the first run; nft -f applies the whole script atomically
+	fmt.Fprintf(b, "table inet %s\ndelete table inet %s\n\n", name, name)
+	fmt.Fprintf(b, "table inet %s {\n", name)
+	for _, set := range []struct {
+		name, typ string
+		entries   []Entry
+	}{{"blocked_v4", "ipv4_addr", v4}, {"blocked_v6", "ipv6_addr", v6}} {
+		fmt.Fprintf(b, "\tset %s {\n\t\ttype %s\n\t\tflags interval, timeout\n", set.name, set.typ)
+		if len(set.entries) > 0 {
+			fmt.Fprintf(b, "\t\telements = {\n")
+			for i, e := range set.entries {
+				fmt.Fprintf(b, "\t\t\t%s", e.Prefix.Masked())
+				if t := timeout(e, now); t > 0 {
+					fmt.Fprintf(b, " timeout %ds", t)
+				}
+				if e.Reason != "" {
+					fmt.Fprintf(b, " comment %s", comment(e))
+				}
+				if i < len(set.entries)-1 {
+					b.WriteString(",")
+				}
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(b, "\t\t}\n")
+		}
+		fmt.Fprintf(b, "\t}\n\n")
+	}
+	fmt.Fprintf(b, "\tchain input {\n\t\ttype filter hook input priority filter; policy accept;\n")
+	fmt.Fprintf(b, "\t\tip saddr @blocked_v4 drop\n\t\tip6 saddr @blocked_v6 drop\n\t}\n}\n")
+}
+
+func renderIpset(b *bytes.Buffer, name string, v4, v6 []Entry, now time.Time) {
+	// The sets are filled under a temporary name and swapped with the
+	// live ones, so that they are never seen partially filled. The file is
+	// meant for ipset -exist restore.
+	for _, set := range []struct {
+		suffix, family string
+		entries        []Entry
+	}{{"_v4", "inet", v4}, {"_v6", "inet6", v6}} {
+		live, tmp := name+set.suffix, name+set.suffix+"_tmp"
+		for _, n := range []string{live, tmp} {
+			fmt.Fprintf(b, "create %s hash:net family %s timeout 0 comment\n", n, set.family)
+		}
+		fmt.Fprintf(b, "flush %s\n", tmp)
+		for _, e := range set.entries {
+			fmt.Fprintf(b, "add %s %s", tmp, e.Prefix.Masked())
+			if t := timeout(e, now); t > 0 {
+				fmt.Fprintf(b, " timeout %d", t)
+			}
+			if e.Reason != "" {
+				fmt.Fprintf(b, " comment %s", comment(e))
+			}
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "swap %s %s\ndestroy %s\n", tmp, live, tmp)
+	}
+}
+
+func renderIptables(b *bytes.Buffer, chain string, entries []Entry) {
+	// Under iptables-restore --noflush, declaring the chain flushes it and
+	// the table is replaced atomically on COMMIT. iptables rules cannot
+	// expire, the expiry is only recorded in the comment.
+	fmt.Fprintf(b, "*filter\n:%s - [0:0]\n", chain)
+	for _, e := range entries {
+		fmt.Fprintf(b, "-A %s -s %s", chain, e.Prefix.Masked())
+		c := strings.Trim(comment(e), `"`)
+		if !e.ExpiresAt.IsZero() {
+			c = strings.TrimSpace(c + " expires " + e.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+		if c != "" {
+			fmt.Fprintf(b, ` -m comment --comment "%s"`, c)
+		}
+		fmt.Fprintf(b, " -j DROP\n")
+	}
+	fmt.Fprintf(b, "COMMIT\n")
+}
+
+// WriteFile replaces the file at path with data atomically: readers, such as
+// a firewall reloading it, see either the previous rule set or the new one.
+func WriteFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,155 @@
+package firewall
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var entries []Entry
+	for _, e := range []struct {
+		source, reason string
+		ttl            time.Duration
+	}{
+		{"10.0.0.1", "scan", time.Hour},
+		{"10.0.0.0/8", `bad "subnet"`, 0},
+		{"192.168.1.70", "", 90 * time.Second},
+		{"2001:db8::1", "v6", 0},
+		{"172.16.0.1", "expired", -time.Second},
+	} {
+		var expiresAt time.Time
+		if e.ttl != 0 {
+			expiresAt = now.Add(e.ttl)
+		}
+		entry, err := ParseEntry(e.source, e.reason, expiresAt)
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	_, err := ParseEntry("example.com", "", time.Time{})
+	assert.Error(t, err)
+
+	// 10.0.0.1 is covered by 10.0.0.0/8, which never expires
+	nft, err := Render(FormatNftables, "", entries, now)
+	require.NoError(t, err)
+	assert.Equal(t, `# Blocklist generated by DNS-Stream-Analyzer, do not edit.
+table inet dns_blocklist
+delete table inet dns_blocklist
+
+table inet dns_blocklist {
+	set blocked_v4 {
+		type ipv4_addr
+		flags interval, timeout
+		elements = {
+			10.0.0.0/8 comment "bad subnet",
+			192.168.1.70/32 timeout 90s
+		}
+	}
+
+	set blocked_v6 {
+		type ipv6_addr
+		flags interval, timeout
+		elements = {
+			2001:db8::1/128 comment "v6"
+		}
+	}
+
+	chain input {
+		type filter hook input priority filter; policy accept;
+		ip saddr @blocked_v4 drop
+		ip6 saddr @blocked_v6 drop
+	}
+}
+`, string(nft))
+
+	ipset, err := Render(FormatIpset, "bl", entries, now)
+	require.NoError(t, err)
+	assert.Equal(t, `# Blocklist generated by DNS-Stream-Analyzer, do not edit.
+create bl_v4 hash:net family inet timeout 0 comment
+create bl_v4_tmp hash:net family inet timeout 0 comment
+flush bl_v4_tmp
+add bl_v4_tmp 10.0.0.0/8 comment "bad subnet"
+add bl_v4_tmp 192.168.1.70/32 timeout 90
+swap bl_v4_tmp bl_v4
+destroy bl_v4_tmp
+create bl_v6 hash:net family inet6 timeout 0 comment
+create bl_v6_tmp hash:net family inet6 timeout 0 comment
+flush bl_v6_tmp
+add bl_v6_tmp 2001:db8::1/128 comment "v6"
+swap bl_v6_tmp bl_v6
+destroy bl_v6_tmp
+`, string(ipset))
+
+	iptables, err := Render(FormatIptables, "", entries, now)
+	require.NoError(t, err)
+	assert.Equal(t, `# Blocklist generated by DNS-Stream-Analyzer, do not edit.
+*filter
+:DNS_BLOCKLIST - [0:0]
+-A DNS_BLOCKLIST -s 10.0.0.0/8 -m comment --comment "bad subnet" -j DROP
+-A DNS_BLOCKLIST -s 192.168.1.70/32 -m comment --comment "expires 2024-01-01T00:01:30Z" -j DROP
+COMMIT
+`, string(iptables))
+
+	ip6tables, err := Render(FormatIp6tables, "", entries, now)
+	require.NoError(t, err)
+	assert.Contains(t, string(ip6tables), "-A DNS_BLOCKLIST -s 2001:db8::1/128 -m comment --comment \"v6\" -j DROP\n")
+	assert.NotContains(t, string(ip6tables), "10.0.0.0/8")
+
+	// An entry outliving the CIDR covering it is kept
+	longer, err := ParseEntry("10.0.0.2", "", time.Time{})
+	require.NoError(t, err)
+	short, err := ParseEntry("10.0.0.0/8", "", now.Add(time.Minute))
+	require.NoError(t, err)
+	v4, _ := prepare([]Entry{longer, short}, now)
+	assert.Len(t, v4, 2)
+
+	// nftables rejects overlapping elements, so the CIDR is split around
+	// the entry outliving it
+	var overlapping []Entry
+	for _, e := range []struct {
+		source string
+		ttl    time.Duration
+	}{
+		{"10.0.0.0/30", time.Minute},
+		{"10.0.0.2", 0},
+		{"10.0.0.1", time.Hour},
+		{"10.0.0.0/31", 30 * time.Second},
+	} {
+		var expiresAt time.Time
+		if e.ttl != 0 {
+			expiresAt = now.Add(e.ttl)
+		}
+		entry, err := ParseEntry(e.source, "", expiresAt)
+		require.NoError(t, err)
+		overlapping = append(overlapping, entry)
+	}
+	nft, err = Render(FormatNftables, "", overlapping, now)
+	require.NoError(t, err)
+	assert.Contains(t, string(nft), `		elements = {
+			10.0.0.0/32 timeout 60s,
+			10.0.0.1/32 timeout 3600s,
+			10.0.0.2/32,
+			10.0.0.3/32 timeout 60s
+		}
+`)
+
+	_, err = Render("pf", "", entries, now)
+	assert.Error(t, err)
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.nft")
+	require.NoError(t, WriteFile(path, []byte("first")))
+	require.NoError(t, WriteFile(path, []byte("second")))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,245 @@
+package ioc
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// parseText reads one indicator per line. Empty lines and comments, from #
+// to the end of the line, are skipped.
+func parseText(r io.Reader) ([]Indicator, int, error) {
+	var indicators []Indicator
+	skipped := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		ind, err := newIndicator("", "", line)
+		if err != nil {
+			skipped++
+			continue
+		}
+		indicators = append(indicators, ind)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return indicators, skipped, nil
+}
+
+// parseCSV reads records with a header naming the value column, value or
+// indicator, and optionally the type and id columns. Lines starting with #
+// are skipped.
+func parseCSV(r io.Reader) ([]Indicator, int, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	cols := map[string]int{"id": -1, "type": -1, "value": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "indicator" {
+			name = "value"
+		}
+		if _, ok := cols[name]; ok {
+			cols[name] = i
+		}
+	}
+	if cols["value"] < 0 {
+		return nil, 0, fmt.Errorf("no value column in the CSV header %q", strings.Join(header, ","))
+	}
+	field := func(record []string, col string) string {
+		if i := cols[col]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var indicators []Indicator
+	skipped := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return indicators, skipped, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		typ := strings.ToLower(field(record, "type"))
+		switch typ {
+		case "domain-name", "hostname":
+			typ = TypeDomain
+		case "ipv4-addr", "ipv6-addr", "ip-src", "ip-dst":
+			typ = ""
+		}
+		ind, err := newIndicator(field(record, "id"), typ, field(record, "value"))
+		if err != nil {
+			skipped++
+			continue
+		}
+		indicators = append(indicators, ind)
+	}
+}
+
+// comparison matches the comparisons of STIX patterns this package
+// supports, and captures the object type and the value.
+var comparison = regexp.MustCompile(`(domain-name|ipv4-addr|ipv6-addr):value\s*(?:=|ISSUBSET)\s*'((?:[^'\\]|\\.)*)'`)
+
+// parseSTIX reads the indicators of a STIX 2.1 bundle. Indicators revoked,
+// expired at now or with patterns combining observations with AND or
+// FOLLOWEDBY are skipped; each value compared with = or ISSUBSET in the
+// others, joined with OR, is an indicator with the ID of the STIX one.
+func parseSTIX(r io.Reader, now time.Time) ([]Indicator, int, error) {
+	var bundle struct {
+		Type    string `json:"type"`
+		Objects []struct {
+			Type        string    `json:"type"`
+			ID          string    `json:"id"`
+			Pattern     string    `json:"pattern"`
+			PatternType string    `json:"pattern_type"`
+			Revoked     bool      `json:"revoked"`
+			ValidUntil  time.Time `json:"valid_until"`
+		} `json:"objects"`
+	}
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, 0, fmt.Errorf("invalid STIX bundle: %w", err)
+	}
+	if bundle.Type != "bundle" {
+		return nil, 0, errors.New("invalid STIX bundle: not a bundle")
+	}
+	var indicators []Indicator
+	skipped := 0
+	for _, o := range bundle.Objects {
+		if o.Type != "indicator" {
+			continue
+		}
+		if o.Revoked || (!o.ValidUntil.IsZero() && !o.ValidUntil.After(now)) ||
+			(o.PatternType != "" && o.PatternType != "stix") ||
+			strings.Contains(o.Pattern, " AND ") || strings.Contains(o.Pattern, "FOLLOWEDBY") {
+			skipped++
+			continue
+		}
+		matches := comparison.FindAllStringSubmatch(o.Pattern, -1)
+		if len(matches) == 0 {
+			skipped++
+			continue
+		}
+		for _, m := range matches {
+			typ := ""
+			if m[1] == "domain-name" {
+				typ = TypeDomain
+			}
+			value := strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(m[2])
+			ind, err := newIndicator(o.ID, typ, value)
+			if err != nil {
+				skipped++
+				continue
+			}
+			indicators = append(indicators, ind)
+		}
+	}
+	return indicators, skipped, nil
+}
+
+// mispAttribute is an attribute of a MISP event.
+type mispAttribute struct {
+	ID      string `json:"id"`
+	UUID    string `json:"uuid"`
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+	ToIDs   bool   `json:"to_ids"`
+	Deleted bool   `json:"deleted"`
+}
+
+// parseMISP reads the attributes of a MISP event, as exported in JSON, or
+// of a list of events. The attributes of its objects are read too; those
+// not flagged for detection (to_ids) or deleted are skipped. Their ID is
+// their UUID.
+func parseMISP(r io.Reader) ([]Indicator, int, error) {
+	type event struct {
+		Event struct {
+			Attribute []mispAttribute `json:"Attribute"`
+			Object    []struct {
+				Attribute []mispAttribute `json:"Attribute"`
+			} `json:"Object"`
+		} `json:"Event"`
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	var events []event
+	if err := json.Unmarshal(data, &events); err != nil {
+		var e event
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, 0, fmt.Errorf("invalid MISP event: %w", err)
+		}
+		events = []event{e}
+	}
+
+	var indicators []Indicator
+	skipped := 0
+	add := func(a mispAttribute) {
+		if !a.ToIDs || a.Deleted {
+			skipped++
+			return
+		}
+		id := a.UUID
+		if id == "" {
+			id = a.ID
+		}
+		var parts []string
+		var types []string
+		switch a.Type {
+		case "domain", "hostname":
+			parts, types = []string{a.Value}, []string{TypeDomain}
+		case "ip-src", "ip-dst":
+			parts, types = []string{a.Value}, []string{""}
+		case "domain|ip", "hostname|ip":
+			domain, ip, _ := strings.Cut(a.Value, "|")
+			parts, types = []string{domain, ip}, []string{TypeDomain, ""}
+		case "ip-src|port", "ip-dst|port":
+			ip, _, _ := strings.Cut(a.Value, "|")
+			parts, types = []string{ip}, []string{""}
+		default:
+			skipped++
+			return
+		}
+		for i, value := range parts {
+			ind, err := newIndicator(id, types[i], value)
+			if err != nil {
+				skipped++
+				continue
+			}
+			indicators = append(indicators, ind)
+		}
+	}
+	for _, e := range events {
+		for _, a := range e.Event.Attribute {
+			add(a)
+		}
+		for _, o := range e.Event.Object {
+			for _, a := range o.Attribute {
+				add(a)
+			}
+		}
+	}
+	return indicators, skipped, nil
+}
@@ -0,0 +1,184 @@
+// Package ioc reads indicators of compromise, the domains, IP addresses and
+// networks listed by threat intelligence feeds, and matches DNS requests
+// against them.
+//
+// Feeds are local files in one of four formats:
+//
+//   - FormatText, one indicator per line, its value being its ID;
+//   - FormatCSV, with a header naming the value column and optionally the
+//     type and id columns;
+//   - FormatSTIX, a STIX 2.1 bundle whose indicators have patterns on
+//     domain-name, ipv4-addr or ipv6-addr values;
+//   - FormatMISP, a MISP event whose domain, hostname and ip-src or ip-dst
+//     attributes are flagged for detection (to_ids).
+package ioc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dnsname"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the feeds.
+const (
+	FormatText = "text"
+	FormatCSV  = "csv"
+	FormatSTIX = "stix"
+	FormatMISP = "misp"
+)
+
+// Types of the indicators.
+const (
+	TypeDomain = "domain"
+	TypeIP     = "ip"
+	TypeCIDR   = "cidr"
+)
+
+// Indicator is an indicator of compromise.
+type Indicator struct {
+	// ID identifies the indicator in its feed. Indicators may share an ID,
+	// as the values of a STIX pattern do.
+	ID string
+	// Type is TypeDomain, TypeIP or TypeCIDR.
+	Type string
+	// Value is the normalized domain, address or network. A domain also
+	// covers its subdomains.
+	Value string
+}
+
+// newIndicator returns the indicator of value, normalized. typ may be empty
+// to infer it from the value, and id to use the value.
+func newIndicator(id, typ, value string) (Indicator, error) {
+	value = strings.TrimSpace(value)
+	if typ == "" {
+		switch {
+		case strings.Contains(value, "/"):
+			typ = TypeCIDR
+		case strings.Contains(value, ":"):
+			typ = TypeIP
+		default:
+			if _, err := ipaddr.Parse(value); err == nil {
+				typ = TypeIP
+			} else {
+				typ = TypeDomain
+			}
+		}
+	}
+	switch typ {
+	case TypeDomain:
+		d, err := dnsname.Normalize(value)
+		if err != nil {
+			return Indicator{}, err
+		}
+		value = d
+	case TypeIP:
+		addr, err := ipaddr.Parse(value)
+		if err != nil {
+			return Indicator{}, err
+		}
+		value = addr.String()
+	case TypeCIDR:
+		prefix, err := ipaddr.ParsePrefix(value)
+		if err != nil {
+			return Indicator{}, err
+		}
+		if prefix.IsSingleIP() {
+			typ, value = TypeIP, prefix.Addr().String()
+		} else {
+			value = prefix.String()
+		}
+	default:
+		return Indicator{}, fmt.Errorf("unsupported indicator type %q", typ)
+	}
+	if id == "" {
+		id = value
+	}
+	return Indicator{ID: id, Type: typ, Value: value}, nil
+}
+
+// Parse reads the indicators of a feed in format. It also returns the
+// number of entries skipped, because their value is invalid or of a type
+// other than domains, addresses and networks.
+func Parse(r io.Reader, format string) ([]Indicator, int, error) {
+	switch format {
+	case FormatText:
+		return parseText(r)
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatSTIX:
+		return parseSTIX(r, time.Now())
+	case FormatMISP:
+		return parseMISP(r)
+	}
+	return nil, 0, fmt.Errorf("unknown feed format %q", format)
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+
+// Feed is the configuration of a feed.
+type Feed struct {
+	// Name identifies the feed in the blocks it makes: 1 to 63 lowercase
+	// letters, digits, dots, hyphens and underscores.
+	Name string `yaml:"name"`
+	// Path is the file of the feed.
+	Path string `yaml:"path"`
+	// Format is FormatText, FormatCSV, FormatSTIX or FormatMISP.
+	Format string `yaml:"format"`
+	// Refresh is the interval between two reads of the file, 0 reads it
+	// once.
+	Refresh time.Duration `yaml:"refresh"`
+	// Duration is the duration of the blocks, 0 blocks until unblocked.
+	Duration time.Duration `yaml:"duration"`
+}
+
+// LoadConfig reads the feeds of the YAML file at path.
+func LoadConfig(path string) ([]Feed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	feeds, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return feeds, nil
+}
+
+// ParseConfig reads and validates the feeds of a YAML document.
+func ParseConfig(data []byte) ([]Feed, error) {
+	var file struct {
+		Feeds []Feed `yaml:"feeds"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, f := range file.Feeds {
+		if !namePattern.MatchString(f.Name) {
+			return nil, fmt.Errorf("invalid feed name %q", f.Name)
+		}
+		if seen[f.Name] {
+			return nil, fmt.Errorf("feed %s declared twice", f.Name)
+		}
+		seen[f.Name] = true
+		if f.Path == "" {
+			return nil, fmt.Errorf("feed %s: missing path", f.Name)
+		}
+		switch f.Format {
+		case FormatText, FormatCSV, FormatSTIX, FormatMISP:
+		default:
+			return nil, fmt.Errorf("feed %s: unknown format %q, expected %s, %s, %s or %s", f.Name, f.Format, FormatText, FormatCSV, FormatSTIX, FormatMISP)
+		}
+		if f.Refresh < 0 || f.Duration < 0 {
+			return nil, fmt.Errorf("feed %s: negative duration", f.Name)
+		}
+	}
+	return file.Feeds, nil
+}
@@ -0,0 +1,199 @@
+package ioc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseText(t *testing.T) {
+	indicators, skipped, err := Parse(strings.NewReader(`
+# C2 servers
+Evil.example.
+198.51.100.7  # seen 2024-06-01
+203.0.113.0/24
+2001:db8::1/128
+http://bad
+`), FormatText)
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, []Indicator{
+		{ID: "evil.example", Type: TypeDomain, Value: "evil.example"},
+		{ID: "198.51.100.7", Type: TypeIP, Value: "198.51.100.7"},
+		{ID: "203.0.113.0/24", Type: TypeCIDR, Value: "203.0.113.0/24"},
+		{ID: "2001:db8::1", Type: TypeIP, Value: "2001:db8::1"},
+	}, indicators)
+}
+
+func TestParseCSV(t *testing.T) {
+	indicators, skipped, err := Parse(strings.NewReader(`id,type,indicator,comment
+# exported 2024-06-01
+ioc-1,domain,evil.example,phishing
+ioc-2,ipv4-addr,198.51.100.7,c2
+ioc-3,url,http://bad/x,ignored
+,,10.0.0.0/8,no id
+`), FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, []Indicator{
+		{ID: "ioc-1", Type: TypeDomain, Value: "evil.example"},
+		{ID: "ioc-2", Type: TypeIP, Value: "198.51.100.7"},
+		{ID: "10.0.0.0/8", Type: TypeCIDR, Value: "10.0.0.0/8"},
+	}, indicators)
+
+	_, _, err = Parse(strings.NewReader("id,name\n1,evil.example\n"), FormatCSV)
+	assert.Error(t, err)
+}
+
+func TestParseSTIX(t *testing.T) {
+	indicators, skipped, err := Parse(strings.NewReader(`{
+  "type": "bundle",
+  "id": "bundle--1",
+  "objects": [
+    {"type": "identity", "id": "identity--1", "name": "CERT"},
+    {"type": "indicator", "id": "indicator--a", "pattern_type": "stix",
+     "pattern": "[domain-name:value = 'evil.example']"},
+    {"type": "indicator", "id": "indicator--b", "pattern_type": "stix",
+     "pattern": "[ipv4-addr:value = '198.51.100.7' OR ipv4-addr:value ISSUBSET '203.0.113.0/24']"},
+    {"type": "indicator", "id": "indicator--c", "pattern_type": "stix", "revoked": true,
+     "pattern": "[domain-name:value = 'revoked.example']"},
+    {"type": "indicator", "id": "indicator--d", "pattern_type": "stix", "valid_until": "2000-01-01T00:00:00Z",
+     "pattern": "[domain-name:value = 'expired.example']"},
+    {"type": "indicator", "id": "indicator--e", "pattern_type": "stix",
+     "pattern": "[domain-name:value = 'a.example'] AND [ipv4-addr:value = '192.0.2.1']"},
+    {"type": "indicator", "id": "indicator--f", "pattern_type": "sigma", "pattern": "title: x"},
+    {"type": "indicator", "id": "indicator--g", "pattern_type": "stix",
+     "pattern": "[url:value = 'http://bad/x']"}
+  ]
+}`), FormatSTIX)
+	require.NoError(t, err)
+	assert.Equal(t, 5, skipped)
+	assert.Equal(t, []Indicator{
+		{ID: "indicator--a", Type: TypeDomain, Value: "evil.example"},
+		{ID: "indicator--b", Type: TypeIP, Value: "198.51.100.7"},
+		{ID: "indicator--b", Type: TypeCIDR, Value: "203.0.113.0/24"},
+	}, indicators)
+
+	_, _, err = Parse(strings.NewReader(`{"type": "indicator"}`), FormatSTIX)
+	assert.Error(t, err)
+}
+
+func TestParseMISP(t *testing.T) {
+	indicators, skipped, err := Parse(strings.NewReader(`{
+  "Event": {
+    "info": "Phishing campaign",
+    "Attribute": [
+      {"uuid": "5f1c-1", "type": "domain", "value": "evil.example", "to_ids": true},
+      {"uuid": "5f1c-2", "type": "ip-dst", "value": "198.51.100.7", "to_ids": true},
+      {"uuid": "5f1c-3", "type": "ip-src", "value": "192.0.2.1", "to_ids": false},
+      {"uuid": "5f1c-4", "type": "md5", "value": "d41d8cd98f00b204e9800998ecf8427e", "to_ids": true},
+      {"uuid": "5f1c-5", "type": "hostname", "value": "gone.example", "to_ids": true, "deleted": true}
+    ],
+    "Object": [
+      {"name": "domain-ip", "Attribute": [
+        {"uuid": "5f1c-6", "type": "domain|ip", "value": "c2.example|203.0.113.9", "to_ids": true},
+        {"uuid": "5f1c-7", "type": "ip-dst|port", "value": "203.0.113.10|443", "to_ids": true}
+      ]}
+    ]
+  }
+}`), FormatMISP)
+	require.NoError(t, err)
+	assert.Equal(t, 3, skipped)
+	assert.Equal(t, []Indicator{
+		{ID: "5f1c-1", Type: TypeDomain, Value: "evil.example"},
+		{ID: "5f1c-2", Type: TypeIP, Value: "198.51.100.7"},
+		{ID: "5f1c-6", Type: TypeDomain, Value: "c2.example"},
+		{ID: "5f1c-6", Type: TypeIP, Value: "203.0.113.9"},
+		{ID: "5f1c-7", Type: TypeIP, Value: "203.0.113.10"},
+	}, indicators)
+
+	// Lists of events are read too
+	indicators, _, err = Parse(strings.NewReader(`[{"Event": {"Attribute": [
+      {"uuid": "a", "type": "domain", "value": "one.example", "to_ids": true}]}},
+    {"Event": {"Attribute": [
+      {"uuid": "b", "type": "domain", "value": "two.example", "to_ids": true}]}}]`), FormatMISP)
+	require.NoError(t, err)
+	assert.Len(t, indicators, 2)
+
+	_, _, err = Parse(strings.NewReader(`not json`), FormatMISP)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feeds.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+feeds:
+  - name: cert
+    path: /feeds/cert.json
+    format: stix
+    refresh: 1h
+    duration: 24h
+`), 0o600))
+	feeds, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Feed{{Name: "cert", Path: "/feeds/cert.json", Format: FormatSTIX, Refresh: time.Hour, Duration: 24 * time.Hour}}, feeds)
+
+	for _, doc := range []string{
+		"feeds: [{name: Cert, path: a, format: text}]",
+		"feeds: [{name: a, path: a, format: text}, {name: a, path: b, format: text}]",
+		"feeds: [{name: a, format: text}]",
+		"feeds: [{name: a, path: a, format: xml}]",
+		"feeds: [{name: a, path: a, format: text, refresh: -1s}]",
+	} {
+		_, err := ParseConfig([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+	_, _, err = Parse(strings.NewReader(""), "xml")
+	assert.Error(t, err)
+}
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher()
+	cert := Feed{Name: "cert", Duration: time.Hour}
+	now := time.Unix(1700000000, 0)
+	m.Set(cert, []Indicator{
+		{ID: "a", Type: TypeDomain, Value: "evil.example"},
+		{ID: "b", Type: TypeIP, Value: "198.51.100.7"},
+		{ID: "c", Type: TypeCIDR, Value: "203.0.113.0/24"},
+	}, now)
+	m.Set(Feed{Name: "misp"}, []Indicator{{ID: "x", Type: TypeDomain, Value: "www.evil.example"}}, now)
+
+	match := func(req *pb.DnsRequest) []string {
+		var got []string
+		for _, mt := range m.Match(req) {
+			got = append(got, mt.Feed.Name+"/"+mt.Indicator.ID+"/"+mt.Field)
+		}
+		return got
+	}
+	assert.Empty(t, match(&pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "example.com"}))
+	assert.Empty(t, match(&pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "notevil.example"}))
+	assert.Equal(t, []string{"cert/a/domain"}, match(&pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "A.Evil.Example."}))
+	assert.Equal(t, []string{"cert/a/domain", "misp/x/domain"}, match(&pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "www.evil.example"}))
+	assert.Equal(t, []string{"cert/b/ip"}, match(&pb.DnsRequest{IpAddress: "198.51.100.7", Domain: "evil.example"}))
+	assert.Equal(t, []string{"cert/c/ip"}, match(&pb.DnsRequest{IpAddress: "203.0.113.200"}))
+	assert.Equal(t, []string{"cert/b/answer"}, match(&pb.DnsRequest{
+		IpAddress: "10.0.0.1",
+		Domain:    "cdn.example.com",
+		Answers:   []*pb.Answer{{Type: pb.QueryType_QUERY_TYPE_CNAME, Data: "cdn.example.net"}, {Type: pb.QueryType_QUERY_TYPE_A, Data: "198.51.100.7"}},
+	}))
+
+	mt := m.Match(&pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "evil.example"})
+	require.Len(t, mt, 1)
+	assert.Equal(t, "feed cert indicator a", mt[0].BlockReason())
+	assert.Equal(t, time.Hour, mt[0].Feed.Duration)
+
+	stats := m.Stats()
+	assert.Equal(t, FeedStats{Indicators: 3, LoadedAt: now, Hits: map[string]int64{"a": 3, "b": 2, "c": 1}}, stats["cert"])
+	assert.Equal(t, map[string]int64{"x": 1}, stats["misp"].Hits)
+
+	// A refresh keeps the hit counts of the indicators kept
+	m.Set(cert, []Indicator{{ID: "a", Type: TypeDomain, Value: "evil.example"}}, now.Add(time.Hour))
+	assert.Equal(t, FeedStats{Indicators: 1, LoadedAt: now.Add(time.Hour), Hits: map[string]int64{"a": 3}}, m.Stats()["cert"])
+	assert.Empty(t, match(&pb.DnsRequest{IpAddress: "198.51.100.7"}))
+}
@@ -0,0 +1,181 @@
+package ioc
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+// Match is an indicator matched by a request.
+type Match struct {
+	Feed      Feed
+	Indicator Indicator
+	// Field is the field of the request that matched: "ip", "domain" or
+	// "answer".
+	Field string
+}
+
+// BlockReason returns the reason of the blocks of m, citing the feed and the
+// indicator: "feed <name> indicator <id>".
+func (m Match) BlockReason() string {
+	return fmt.Sprintf("feed %s indicator %s", m.Feed.Name, m.Indicator.ID)
+}
+
+// index holds the indicators of a feed.
+type index struct {
+	feed     Feed
+	loadedAt time.Time
+	size     int
+	domains  map[string]Indicator
+	ips      map[netip.Addr]Indicator
+	prefixes []netip.Prefix
+	networks []Indicator
+	// hits counts the matches of the indicators, by ID.
+	hits map[string]*atomic.Int64
+}
+
+// Matcher matches requests against the indicators of feeds. It is safe for
+// concurrent use.
+type Matcher struct {
+	mu      sync.RWMutex
+	names   []string
+	indexes map[string]*index
+}
+
+// NewMatcher returns a matcher without feeds.
+func NewMatcher() *Matcher {
+	return &Matcher{indexes: make(map[string]*index)}
+}
+
+// Set replaces the indicators of feed, loaded at now. The hit counts of the
+// indicators kept are kept.
+func (m *Matcher) Set(feed Feed, indicators []Indicator, now time.Time) {
+	idx := &index{
+		feed:     feed,
+		loadedAt: now,
+		size:     len(indicators),
+		domains:  make(map[string]Indicator),
+		ips:      make(map[netip.Addr]Indicator),
+		hits:     make(map[string]*atomic.Int64),
+	}
+	m.mu.RLock()
+	prev := m.indexes[feed.Name]
+	m.mu.RUnlock()
+	for _, ind := range indicators {
+		switch ind.Type {
+		case TypeDomain:
+			idx.domains[ind.Value] = ind
+		case TypeIP:
+			idx.ips[netip.MustParseAddr(ind.Value)] = ind
+		case TypeCIDR:
+			idx.prefixes = append(idx.prefixes, netip.MustParsePrefix(ind.Value))
+			idx.networks = append(idx.networks, ind)
+		}
+		if _, ok := idx.hits[ind.ID]; ok {
+			continue
+		}
+		if prev != nil && prev.hits[ind.ID] != nil {
+			idx.hits[ind.ID] = prev.hits[ind.ID]
+		} else {
+			idx.hits[ind.ID] = new(atomic.Int64)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.indexes[feed.Name]; !ok {
+		m.names = append(m.names, feed.Name)
+	}
+	m.indexes[feed.Name] = idx
+}
+
+// Match returns the indicators matched by the source address, the domain or
+// the answers of req, at most one per feed, in the order the feeds were
+// first set, and counts their hits.
+func (m *Matcher) Match(req *pb.DnsRequest) []Match {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var matches []Match
+	for _, name := range m.names {
+		idx := m.indexes[name]
+		ind, field, ok := idx.matchIP(req.GetIpAddress())
+		if !ok {
+			ind, ok = idx.matchDomain(req.GetDomain())
+			field = "domain"
+		}
+		for _, a := range req.GetAnswers() {
+			if ok {
+				break
+			}
+			if ind, _, ok = idx.matchIP(a.GetData()); !ok {
+				ind, ok = idx.matchDomain(a.GetData())
+			}
+			field = "answer"
+		}
+		if ok {
+			idx.hits[ind.ID].Add(1)
+			matches = append(matches, Match{Feed: idx.feed, Indicator: ind, Field: field})
+		}
+	}
+	return matches
+}
+
+func (idx *index) matchIP(s string) (Indicator, string, bool) {
+	addr, err := ipaddr.Parse(s)
+	if err != nil {
+		return Indicator{}, "", false
+	}
+	if ind, ok := idx.ips[addr]; ok {
+		return ind, "ip", true
+	}
+	for i, p := range idx.prefixes {
+		if p.Contains(addr) {
+			return idx.networks[i], "ip", true
+		}
+	}
+	return Indicator{}, "", false
+}
+
+// matchDomain matches domain and its parent domains.
+func (idx *index) matchDomain(domain string) (Indicator, bool) {
+	d := strings.TrimSuffix(strings.ToLower(domain), ".")
+	for d != "" {
+		if ind, ok := idx.domains[d]; ok {
+			return ind, true
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+	return Indicator{}, false
+}
+
+// FeedStats are the statistics of a feed.
+type FeedStats struct {
+	Indicators int       `json:"indicators"`
+	LoadedAt   time.Time `json:"loaded_at"`
+	// Hits are the hit counts of the indicators matched at least once, by
+	// ID.
+	Hits map[string]int64 `json:"hits"`
+}
+
+// Stats returns the statistics of the feeds, by name.
+func (m *Matcher) Stats() map[string]FeedStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := make(map[string]FeedStats, len(m.indexes))
+	for name, idx := range m.indexes {
+		s := FeedStats{Indicators: idx.size, LoadedAt: idx.loadedAt, Hits: make(map[string]int64)}
+		for id, n := range idx.hits {
+			if v := n.Load(); v > 0 {
+				s.Hits[id] = v
+			}
+		}
+		stats[name] = s
+	}
+	return stats
+}
@@ -0,0 +1,47 @@
+// Package ipaddr parses the IP addresses received by the server into a
+// canonical form, so that every spelling of an address maps to the same
+// blacklist key.
+package ipaddr
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Parse parses an IPv4 or IPv6 address. IPv4-mapped IPv6 addresses are
+// mapped to their IPv4 address. Zones, and IPv4 octets with leading zeros
+// (which some parsers read as octal), are rejected.
+func Parse(s string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid IP address %q", s)
+	}
+	if addr.Zone() != "" {
+		return netip.Addr{}, fmt.Errorf("invalid IP address %q: zones are not supported", s)
+	}
+	return addr.Unmap(), nil
+}
+
+// Canonical returns the canonical form of the address s: dotted decimal for
+// IPv4, RFC 5952 for IPv6.
+func Canonical(s string) (string, error) {
+	addr, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+// ParsePrefix parses a CIDR and returns it masked. IPv4-mapped IPv6 prefixes
+// are mapped to their IPv4 prefix.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q", s)
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
@@ -0,0 +1,48 @@
+package ipaddr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	for in, want := range map[string]string{
+		"192.168.1.70":          "192.168.1.70",
+		" 10.0.0.1 ":            "10.0.0.1",
+		"::ffff:192.168.1.70":   "192.168.1.70",
+		"::FFFF:c0a8:146":       "192.168.1.70",
+		"2001:DB8::1":           "2001:db8::1",
+		"2001:0db8:0:0:0:0:0:1": "2001:db8::1",
+		"::1":                   "::1",
+	} {
+		got, err := Canonical(in)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, want, got, in)
+		}
+	}
+
+	for _, in := range []string{"", "garbage", "192.168.001.070", "256.0.0.1", "10.0.0", "fe80::1%eth0", "10.0.0.0/8"} {
+		_, err := Canonical(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	for in, want := range map[string]string{
+		"10.1.2.3/8":          "10.0.0.0/8",
+		"2001:DB8::1/32":      "2001:db8::/32",
+		"::ffff:10.0.0.0/104": "10.0.0.0/8",
+		"192.168.1.70/32":     "192.168.1.70/32",
+	} {
+		got, err := ParsePrefix(in)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, want, got.String(), in)
+		}
+	}
+
+	for _, in := range []string{"", "10.0.0.1", "10.0.0.0/33", "garbage/8"} {
+		_, err := ParsePrefix(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,48 @@
+// Package qtype converts DNS query types between pb.QueryType and their
+// textual form: the IANA mnemonic ("A", "TXT", "NSAP-PTR"), or the RFC 3597
+// generic form "TYPE<n>" for types without a mnemonic.
+package qtype
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+const enumPrefix = "QUERY_TYPE_"
+
+// Parse returns the query type of s, a mnemonic in any case, "TYPE<n>" or a
+// bare number.
+func Parse(s string) (pb.QueryType, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "*" {
+		return pb.QueryType_QUERY_TYPE_ANY, nil
+	}
+	if v, ok := pb.QueryType_value[enumPrefix+strings.ReplaceAll(s, "-", "_")]; ok && v != 0 {
+		return pb.QueryType(v), nil
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "TYPE"), 10, 16)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("invalid query type %q", s)
+	}
+	return pb.QueryType(n), nil
+}
+
+// String returns the textual form of t, "UNKNOWN" for the zero value.
+func String(t pb.QueryType) string {
+	if t == pb.QueryType_QUERY_TYPE_UNKNOWN {
+		return "UNKNOWN"
+	}
+	if name, ok := pb.QueryType_name[int32(t)]; ok {
+		return strings.ReplaceAll(strings.TrimPrefix(name, enumPrefix), "_", "-")
+	}
+	return "TYPE" + strconv.Itoa(int(t))
+}
+
+// Valid reports whether t is a 16-bit RR type number. The zero value is
+// valid, it stands for an unknown type.
+func Valid(t pb.QueryType) bool {
+	return t >= 0 && t <= 65535
+}
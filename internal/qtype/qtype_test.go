@@ -0,0 +1,53 @@
+package qtype
+
+import (
+	"testing"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]pb.QueryType{
+		"A":         pb.QueryType_QUERY_TYPE_A,
+		"aaaa":      pb.QueryType_QUERY_TYPE_AAAA,
+		" TXT ":     pb.QueryType_QUERY_TYPE_TXT,
+		"NSAP-PTR":  pb.QueryType_QUERY_TYPE_NSAP_PTR,
+		"*":         pb.QueryType_QUERY_TYPE_ANY,
+		"TYPE65":    pb.QueryType_QUERY_TYPE_HTTPS,
+		"type65534": pb.QueryType(65534),
+		"16":        pb.QueryType_QUERY_TYPE_TXT,
+	} {
+		got, err := Parse(in)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, want, got, in)
+		}
+	}
+	for _, in := range []string{"", "UNKNOWN", "BOGUS", "TYPE0", "TYPE65536", "-1"} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "A", String(pb.QueryType_QUERY_TYPE_A))
+	assert.Equal(t, "NSAP-PTR", String(pb.QueryType_QUERY_TYPE_NSAP_PTR))
+	assert.Equal(t, "TYPE65534", String(pb.QueryType(65534)))
+	assert.Equal(t, "UNKNOWN", String(pb.QueryType_QUERY_TYPE_UNKNOWN))
+
+	for name, v := range pb.QueryType_value {
+		if v == 0 {
+			continue
+		}
+		got, err := Parse(String(pb.QueryType(v)))
+		assert.NoError(t, err, name)
+		assert.Equal(t, pb.QueryType(v), got, name)
+	}
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid(pb.QueryType_QUERY_TYPE_UNKNOWN))
+	assert.True(t, Valid(pb.QueryType(65535)))
+	assert.False(t, Valid(pb.QueryType(65536)))
+	assert.False(t, Valid(pb.QueryType(-1)))
+}
@@ -0,0 +1,308 @@
+// Package rpz publishes the blocks as a DNS Response Policy Zone, served by
+// zone transfer, so that recursive resolvers (BIND, Unbound, Knot Resolver)
+// enforce them natively.
+//
+// The blocked domains, the block rules of any source for a domain pattern,
+// become QNAME triggers. The blocked clients, the blacklisted IPs and the
+// block rules of an IP or a CIDR for every domain, become rpz-client-ip
+// triggers, which BIND supports. Rules combining a source with a domain, or
+// scoped to a query type, cannot be expressed in RPZ and are left out.
+package rpz
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/miekg/dns"
+)
+
+// Policy actions of the triggers.
+const (
+	// ActionNXDOMAIN answers that the domain does not exist.
+	ActionNXDOMAIN = "nxdomain"
+	// ActionNODATA answers that the domain has no record of the type.
+	ActionNODATA = "nodata"
+	// ActionDrop drops the query without answering.
+	ActionDrop = "drop"
+)
+
+// historySize is the number of changes kept for IXFR. Secondaries with an
+// older serial get the full zone.
+const historySize = 100
+
+// ttl is the TTL of the records of the zone. The SOA timers make
+// secondaries without NOTIFY poll for changes every minute.
+const ttl = 60
+
+// change is the difference between two versions of the zone.
+type change struct {
+	from, to       uint32
+	deleted, added []dns.RR
+}
+
+// Zone is a versioned Response Policy Zone.
+type Zone struct {
+	origin string
+	target string
+
+	mu      sync.RWMutex
+	serial  uint32
+	records map[string]dns.RR
+	// history holds the last changes, oldest first.
+	history []change
+}
+
+// NewZone returns an empty zone named origin, whose triggers apply action.
+// serial is the serial of the empty zone, it must grow across restarts for
+// secondaries to pick the zone up again: the Unix time is a good choice.
+func NewZone(origin, action string, serial uint32) (*Zone, error) {
+	if _, ok := dns.IsDomainName(origin); !ok || origin == "" || origin == "." {
+		return nil, fmt.Errorf("invalid RPZ zone name %q", origin)
+	}
+	var target string
+	switch action {
+	case ActionNXDOMAIN:
+		target = "."
+	case ActionNODATA:
+		target = "*."
+	case ActionDrop:
+		target = "rpz-drop."
+	default:
+		return nil, fmt.Errorf("unknown RPZ action %q, expected %s, %s or %s", action, ActionNXDOMAIN, ActionNODATA, ActionDrop)
+	}
+	return &Zone{
+		origin:  dns.CanonicalName(origin),
+		target:  target,
+		serial:  serial,
+		records: make(map[string]dns.RR),
+	}, nil
+}
+
+// Origin returns the name of the zone, fully qualified.
+func (z *Zone) Origin() string {
+	return z.origin
+}
+
+// Serial returns the serial of the current version of the zone.
+func (z *Zone) Serial() uint32 {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return z.serial
+}
+
+// Update replaces the triggers of the zone with those of the unexpired
+// blacklist entries and block rules, and bumps the serial if they changed.
+// It tells whether they did.
+func (z *Zone) Update(entries []store.Entry, rules []store.BlockRule, now time.Time) bool {
+	records := make(map[string]dns.RR)
+	add := func(name string) {
+		rr := &dns.CNAME{
+			Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: ttl},
+			Target: z.target,
+		}
+		records[rr.String()] = rr
+	}
+	for _, e := range entries {
+		if e.Expired(now) {
+			continue
+		}
+		if addr, err := ipaddr.Parse(e.IP); err == nil {
+			add(clientIPName(netip.PrefixFrom(addr, addr.BitLen()), z.origin))
+		}
+	}
+	for _, r := range rules {
+		if r.Expired(now) || r.QueryType != 0 {
+			continue
+		}
+		switch {
+		case r.Source == "" && r.DomainPattern != "":
+			add(dns.Fqdn(r.DomainPattern) + z.origin)
+		case r.Source != "" && r.DomainPattern == "":
+			if prefix, err := sourcePrefix(r.Source); err == nil {
+				add(clientIPName(prefix, z.origin))
+			}
+		}
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	var c change
+	for key, rr := range z.records {
+		if _, ok := records[key]; !ok {
+			c.deleted = append(c.deleted, rr)
+		}
+	}
+	for key, rr := range records {
+		if _, ok := z.records[key]; !ok {
+			c.added = append(c.added, rr)
+		}
+	}
+	if len(c.deleted) == 0 && len(c.added) == 0 {
+		return false
+	}
+	sortRRs(c.deleted)
+	sortRRs(c.added)
+	c.from, c.to = z.serial, nextSerial(z.serial)
+	z.serial, z.records = c.to, records
+	z.history = append(z.history, c)
+	if len(z.history) > historySize {
+		z.history = append([]change(nil), z.history[len(z.history)-historySize:]...)
+	}
+	return true
+}
+
+// SOA returns the SOA record of the current version of the zone.
+func (z *Zone) SOA() *dns.SOA {
+	return z.soa(z.Serial())
+}
+
+func (z *Zone) soa(serial uint32) *dns.SOA {
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: z.origin, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: ttl},
+		Ns:      "localhost.",
+		Mbox:    "hostmaster.localhost.",
+		Serial:  serial,
+		Refresh: 60,
+		Retry:   30,
+		Expire:  86400,
+		Minttl:  ttl,
+	}
+}
+
+// NS returns the NS record of the zone, which RPZ zones need although no
+// resolver queries it.
+func (z *Zone) NS() *dns.NS {
+	return &dns.NS{
+		Hdr: dns.RR_Header{Name: z.origin, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: ttl},
+		Ns:  "localhost.",
+	}
+}
+
+// AXFR returns the records of a full transfer of the zone, starting and
+// ending with its SOA.
+func (z *Zone) AXFR() []dns.RR {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return z.axfr()
+}
+
+func (z *Zone) axfr() []dns.RR {
+	soa := z.soa(z.serial)
+	rrs := make([]dns.RR, 0, len(z.records)+3)
+	rrs = append(rrs, soa, z.NS())
+	records := make([]dns.RR, 0, len(z.records))
+	for _, rr := range z.records {
+		records = append(records, rr)
+	}
+	sortRRs(records)
+	rrs = append(rrs, records...)
+	return append(rrs, soa)
+}
+
+// IXFR returns the records of an incremental transfer from the version
+// serial (RFC 1995): the current SOA alone if serial is current, the changes
+// since serial if they are known, the full zone otherwise.
+func (z *Zone) IXFR(serial uint32) []dns.RR {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	current := z.soa(z.serial)
+	if serial == z.serial {
+		return []dns.RR{current}
+	}
+	first := -1
+	for i, c := range z.history {
+		if c.from == serial {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return z.axfr()
+	}
+	rrs := []dns.RR{current}
+	for _, c := range z.history[first:] {
+		rrs = append(rrs, z.soa(c.from))
+		rrs = append(rrs, c.deleted...)
+		rrs = append(rrs, z.soa(c.to))
+		rrs = append(rrs, c.added...)
+	}
+	return append(rrs, current)
+}
+
+// nextSerial returns the serial following s, in serial number arithmetic
+// (RFC 1982), skipping 0 which some secondaries treat specially.
+func nextSerial(s uint32) uint32 {
+	if s++; s == 0 {
+		s = 1
+	}
+	return s
+}
+
+// sourcePrefix parses the source of a block rule.
+func sourcePrefix(source string) (netip.Prefix, error) {
+	if addr, err := ipaddr.Parse(source); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	return ipaddr.ParsePrefix(source)
+}
+
+// clientIPName returns the rpz-client-ip trigger name of prefix: the prefix
+// length then the address reversed, by octets for IPv4 and by 16 bit groups
+// for IPv6, with "zz" for the longest run of zero groups.
+func clientIPName(prefix netip.Prefix, origin string) string {
+	return prefixLabels(prefix.Masked()) + ".rpz-client-ip." + origin
+}
+
+func prefixLabels(prefix netip.Prefix) string {
+	addr := prefix.Addr()
+	labels := []string{strconv.Itoa(prefix.Bits())}
+	if addr.Is4() {
+		b := addr.As4()
+		for i := 3; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(b[i])))
+		}
+		return strings.Join(labels, ".")
+	}
+	b := addr.As16()
+	var groups [8]uint16
+	for i := range groups {
+		groups[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	// The longest run of at least two zero groups, the first one on ties
+	runStart, runLen := -1, 1
+	for i := 0; i < 8; {
+		if groups[i] != 0 {
+			i++
+			continue
+		}
+		j := i
+		for j < 8 && groups[j] == 0 {
+			j++
+		}
+		if j-i > runLen {
+			runStart, runLen = i, j-i
+		}
+		i = j
+	}
+	for i := 7; i >= 0; i-- {
+		if runStart >= 0 && i >= runStart && i < runStart+runLen {
+			if i == runStart {
+				labels = append(labels, "zz")
+			}
+			continue
+		}
+		labels = append(labels, strconv.FormatUint(uint64(groups[i]), 16))
+	}
+	return strings.Join(labels, ".")
+}
+
+func sortRRs(rrs []dns.RR) {
+	sort.Slice(rrs, func(i, j int) bool { return rrs[i].Header().Name < rrs[j].Header().Name })
+}
@@ -0,0 +1,199 @@
+package rpz
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPName(t *testing.T) {
+	for in, want := range map[string]string{
+		"192.168.1.70/32":   "32.70.1.168.192.rpz-client-ip.rpz.test.",
+		"10.0.0.0/8":        "8.0.0.0.10.rpz-client-ip.rpz.test.",
+		"2001:db8::1/128":   "128.1.zz.db8.2001.rpz-client-ip.rpz.test.",
+		"2001:db8::/32":     "32.zz.db8.2001.rpz-client-ip.rpz.test.",
+		"2001:0:0:1::1/128": "128.1.zz.1.0.0.2001.rpz-client-ip.rpz.test.",
+	} {
+		assert.Equal(t, want, clientIPName(netip.MustParsePrefix(in), "rpz.test."), in)
+	}
+}
+
+// names returns the owner names of the CNAME records of rrs.
+func names(rrs []dns.RR) []string {
+	var out []string
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == dns.TypeCNAME {
+			out = append(out, rr.Header().Name)
+		}
+	}
+	return out
+}
+
+func TestZone(t *testing.T) {
+	now := time.Now()
+	_, err := NewZone("rpz.test", "redirect", 1)
+	assert.Error(t, err)
+	z, err := NewZone("RPZ.test", ActionNXDOMAIN, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "rpz.test.", z.Origin())
+
+	entries := []store.Entry{
+		{IP: "10.0.0.1"},
+		{IP: "10.0.0.2", ExpiresAt: now.Add(-time.Second)},
+	}
+	rules := []store.BlockRule{
+		{ID: "domain", DomainPattern: "*.evil.com"},
+		{ID: "net", Source: "192.168.0.0/16"},
+		// Not expressible in RPZ
+		{ID: "scoped", Source: "10.0.0.3", DomainPattern: "c2.example.com"},
+		{ID: "txt", DomainPattern: "example.org", QueryType: 16},
+	}
+	assert.True(t, z.Update(entries, rules, now))
+	assert.False(t, z.Update(entries, rules, now))
+	assert.EqualValues(t, 101, z.Serial())
+
+	axfr := z.AXFR()
+	assert.Equal(t, dns.TypeSOA, axfr[0].Header().Rrtype)
+	assert.Equal(t, dns.TypeSOA, axfr[len(axfr)-1].Header().Rrtype)
+	assert.Equal(t, []string{
+		"*.evil.com.rpz.test.",
+		"16.0.0.168.192.rpz-client-ip.rpz.test.",
+		"32.1.0.0.10.rpz-client-ip.rpz.test.",
+	}, names(axfr))
+	assert.Equal(t, "*.evil.com.rpz.test.\t60\tIN\tCNAME\t.", axfr[2].String())
+
+	// IXFR from the previous serial sends the difference
+	assert.True(t, z.Update(entries[:1], rules[:1], now))
+	ixfr := z.IXFR(101)
+	require.Len(t, ixfr, 5)
+	assert.EqualValues(t, 102, ixfr[0].(*dns.SOA).Serial)
+	assert.EqualValues(t, 101, ixfr[1].(*dns.SOA).Serial)
+	assert.Equal(t, "16.0.0.168.192.rpz-client-ip.rpz.test.", ixfr[2].Header().Name)
+	assert.EqualValues(t, 102, ixfr[3].(*dns.SOA).Serial)
+	assert.EqualValues(t, 102, ixfr[4].(*dns.SOA).Serial)
+
+	// Up to date, or unknown, serials
+	assert.Len(t, z.IXFR(102), 1)
+	assert.Equal(t, z.AXFR(), z.IXFR(7))
+	assert.Len(t, z.IXFR(100), 10)
+}
+
+func TestHandler(t *testing.T) {
+	z, err := NewZone("rpz.test.", ActionDrop, 1)
+	require.NoError(t, err)
+	z.Update([]store.Entry{{IP: "10.0.0.1"}}, nil, time.Now())
+	h, err := NewHandler([]string{"127.0.0.0/8", ""})
+	require.NoError(t, err)
+	h.Add(z)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &dns.Server{Listener: listener, Handler: h}
+	go srv.ActivateAndServe()
+	defer srv.Shutdown()
+	addr := listener.Addr().String()
+
+	transfer := func(m *dns.Msg) []dns.RR {
+		envelopes, err := (&dns.Transfer{}).In(m, addr)
+		require.NoError(t, err)
+		var rrs []dns.RR
+		for env := range envelopes {
+			require.NoError(t, env.Error)
+			rrs = append(rrs, env.RR...)
+		}
+		return rrs
+	}
+
+	m := new(dns.Msg)
+	m.SetAxfr("rpz.test.")
+	rrs := transfer(m)
+	assert.Equal(t, []string{"32.1.0.0.10.rpz-client-ip.rpz.test."}, names(rrs))
+	assert.Equal(t, "rpz-drop.", rrs[2].(*dns.CNAME).Target)
+
+	z.Update([]store.Entry{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}}, nil, time.Now())
+	m = new(dns.Msg)
+	m.SetIxfr("rpz.test.", 2, "localhost.", "hostmaster.localhost.")
+	rrs = transfer(m)
+	assert.Equal(t, []string{"32.2.0.0.10.rpz-client-ip.rpz.test."}, names(rrs))
+	assert.EqualValues(t, 3, rrs[0].(*dns.SOA).Serial)
+
+	c := &dns.Client{Net: "tcp"}
+	q := new(dns.Msg)
+	q.SetQuestion("rpz.test.", dns.TypeSOA)
+	resp, _, err := c.Exchange(q, addr)
+	require.NoError(t, err)
+	require.Len(t, resp.Answer, 1)
+	assert.EqualValues(t, 3, resp.Answer[0].(*dns.SOA).Serial)
+
+	// The server is not a resolver, nor does it serve other zones
+	for _, name := range []string{"32.1.0.0.10.rpz-client-ip.rpz.test.", "example.com."} {
+		q.SetQuestion(name, dns.TypeA)
+		resp, _, err = c.Exchange(q, addr)
+		require.NoError(t, err)
+		assert.Equal(t, dns.RcodeRefused, resp.Rcode)
+	}
+
+	// Transfers are refused outside the allowed CIDRs
+	h.allow = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	q.SetAxfr("rpz.test.")
+	resp, _, err = c.Exchange(q, addr)
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeRefused, resp.Rcode)
+}
+
+// closedWriter is a response writer whose client closed the connection
+// after reading the first messages.
+type closedWriter struct {
+	dns.ResponseWriter
+	written int
+	closed  bool
+}
+
+func (w *closedWriter) WriteMsg(m *dns.Msg) error {
+	if w.written == 2 {
+		return errors.New("write: broken pipe")
+	}
+	w.written++
+	return nil
+}
+
+func (w *closedWriter) TsigTimersOnly(bool) {}
+
+func (w *closedWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestTransferClosedByClient(t *testing.T) {
+	z, err := NewZone("rpz.test.", ActionDrop, 1)
+	require.NoError(t, err)
+	var entries []store.Entry
+	for i := range 2000 {
+		entries = append(entries, store.Entry{IP: fmt.Sprintf("10.0.%d.%d", i/256, i%256)})
+	}
+	z.Update(entries, nil, time.Now())
+	m := new(dns.Msg)
+	m.SetAxfr("rpz.test.")
+
+	w := &closedWriter{}
+	done := make(chan struct{})
+	go func() {
+		transfer(w, m, z.AXFR())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the transfer did not stop when the client closed the connection")
+	}
+	assert.Equal(t, 2, w.written)
+	assert.True(t, w.closed)
+}
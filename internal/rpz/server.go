@@ -0,0 +1,173 @@
+package rpz
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/miekg/dns"
+)
+
+// envelopeSize is the number of records per message of a transfer.
+const envelopeSize = 500
+
+// Handler serves zones by transfer: it answers the SOA and NS queries of
+// their apex, and AXFR and IXFR requests over TCP from the allowed clients.
+// Other queries are refused, it is not a resolver.
+type Handler struct {
+	allow []netip.Prefix
+
+	mu    sync.RWMutex
+	zones map[string]*Zone
+}
+
+// NewHandler returns a handler serving transfers to the clients of the
+// allowed CIDRs.
+func NewHandler(allow []string) (*Handler, error) {
+	h := &Handler{zones: make(map[string]*Zone)}
+	for _, cidr := range allow {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		prefix, err := ipaddr.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		h.allow = append(h.allow, prefix)
+	}
+	return h, nil
+}
+
+// Add serves z.
+func (h *Handler) Add(z *Zone) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.zones[z.Origin()] = z
+}
+
+func (h *Handler) zone(name string) *Zone {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.zones[dns.CanonicalName(name)]
+}
+
+// allowed tells whether the client at addr may transfer the zones.
+func (h *Handler) allowed(addr net.Addr) bool {
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := ap.Addr().Unmap()
+	for _, prefix := range h.allow {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeDNS implements dns.Handler.
+func (h *Handler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+	if r.Opcode != dns.OpcodeQuery || len(r.Question) != 1 {
+		m.Rcode = dns.RcodeNotImplemented
+		w.WriteMsg(m)
+		return
+	}
+	q := r.Question[0]
+	z := h.zone(q.Name)
+	if z == nil {
+		m.Rcode = dns.RcodeRefused
+		w.WriteMsg(m)
+		return
+	}
+	_, tcp := w.RemoteAddr().(*net.TCPAddr)
+	switch q.Qtype {
+	case dns.TypeSOA:
+		m.Answer = []dns.RR{z.SOA()}
+	case dns.TypeNS:
+		m.Answer = []dns.RR{z.NS()}
+	case dns.TypeAXFR, dns.TypeIXFR:
+		if !h.allowed(w.RemoteAddr()) {
+			m.Rcode = dns.RcodeRefused
+			break
+		}
+		if !tcp {
+			// The transfer may not fit, the SOA tells the secondary to
+			// retry over TCP (RFC 1995 section 2)
+			m.Answer = []dns.RR{z.SOA()}
+			break
+		}
+		rrs := z.AXFR()
+		if q.Qtype == dns.TypeIXFR {
+			if serial, ok := clientSerial(r); ok {
+				rrs = z.IXFR(serial)
+			}
+		}
+		transfer(w, r, rrs)
+		return
+	default:
+		m.Rcode = dns.RcodeRefused
+	}
+	w.WriteMsg(m)
+}
+
+// clientSerial returns the serial of the SOA in the authority section of an
+// IXFR request.
+func clientSerial(r *dns.Msg) (uint32, bool) {
+	for _, rr := range r.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Serial, true
+		}
+	}
+	return 0, false
+}
+
+func transfer(w dns.ResponseWriter, r *dns.Msg, rrs []dns.RR) {
+	ch := make(chan *dns.Envelope)
+	tr := &dns.Transfer{WriteTimeout: 30 * time.Second}
+	done := make(chan error, 1)
+	go func() {
+		done <- tr.Out(w, r, ch)
+	}()
+	defer w.Close()
+	for len(rrs) > 0 {
+		n := min(envelopeSize, len(rrs))
+		select {
+		case ch <- &dns.Envelope{RR: rrs[:n]}:
+		case <-done:
+			// Out stopped reading ch on a write error, the client is
+			// gone
+			return
+		}
+		rrs = rrs[n:]
+	}
+	close(ch)
+	<-done
+}
+
+// Notify sends a NOTIFY of z to the secondaries at addrs, so that they
+// transfer the new version without waiting for the SOA refresh timer.
+func Notify(z *Zone, addrs []string) []error {
+	var errs []error
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		m := new(dns.Msg)
+		m.SetNotify(z.Origin())
+		m.Answer = []dns.RR{z.SOA()}
+		c := &dns.Client{Timeout: 5 * time.Second}
+		if _, _, err := c.Exchange(m, addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
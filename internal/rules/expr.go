@@ -0,0 +1,878 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dga"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/qtype"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"golang.org/x/net/publicsuffix"
+)
+
+// kind is the static type of an expression.
+type kind int
+
+const (
+	kindBool kind = iota
+	kindNumber
+	kindString
+	kindList
+)
+
+func (k kind) String() string {
+	return [...]string{"bool", "number", "string", "list"}[k]
+}
+
+// env is the event an expression is evaluated on.
+type env struct {
+	tenant string
+	req    *pb.DnsRequest
+	now    time.Time
+}
+
+// node is a compiled expression. Values are bool, float64, string or
+// []string, as given by kind.
+type node interface {
+	kind() kind
+	eval(e *env) any
+}
+
+// fields are the event fields expressions refer to by name.
+var fields = map[string]struct {
+	kind kind
+	get  func(e *env) any
+}{
+	"ip":     {kindString, func(e *env) any { return e.req.GetIpAddress() }},
+	"domain": {kindString, func(e *env) any { return e.req.GetDomain() }},
+	"labels": {kindList, func(e *env) any {
+		if e.req.GetDomain() == "" {
+			return []string(nil)
+		}
+		return strings.Split(e.req.GetDomain(), ".")
+	}},
+	"qtype": {kindString, func(e *env) any { return qtype.String(e.req.GetQueryType()) }},
+	"rcode": {kindString, func(e *env) any {
+		if e.req.Rcode == nil {
+			return ""
+		}
+		return strings.TrimPrefix(e.req.GetRcode().String(), "RESPONSE_CODE_")
+	}},
+	"transport": {kindString, func(e *env) any {
+		if e.req.GetTransport() == pb.Transport_TRANSPORT_UNKNOWN {
+			return ""
+		}
+		return strings.ToLower(strings.TrimPrefix(e.req.GetTransport().String(), "TRANSPORT_"))
+	}},
+	"answers": {kindList, func(e *env) any {
+		data := make([]string, len(e.req.GetAnswers()))
+		for i, a := range e.req.GetAnswers() {
+			data[i] = a.GetData()
+		}
+		return data
+	}},
+	"port":          {kindNumber, func(e *env) any { return float64(e.req.GetClientPort()) }},
+	"query_size":    {kindNumber, func(e *env) any { return float64(e.req.GetQuerySize()) }},
+	"response_size": {kindNumber, func(e *env) any { return float64(e.req.GetResponseSize()) }},
+	"ecs":           {kindString, func(e *env) any { return e.req.GetEdnsClientSubnet() }},
+	"sensor":        {kindString, func(e *env) any { return e.req.GetSensorId() }},
+	"tenant":        {kindString, func(e *env) any { return e.tenant }},
+	"registered": {kindString, func(e *env) any {
+		registered, _ := registered(e.req.GetDomain())
+		return registered
+	}},
+	"subdomain": {kindString, func(e *env) any {
+		_, sub := registered(e.req.GetDomain())
+		return sub
+	}},
+}
+
+// registered splits domain into its registered domain, its public suffix
+// and the label before it, and the subdomain before that: "example.co.uk"
+// and "a.b" for a.b.example.co.uk. Names without a registered domain are
+// returned whole as their registered domain.
+func registered(domain string) (string, string) {
+	reg, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return domain, ""
+	}
+	return reg, strings.TrimSuffix(strings.TrimSuffix(domain, reg), ".")
+}
+
+// entropy returns the Shannon entropy of the characters of s, dots
+// excluded, in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, c := range s {
+		if c != '.' {
+			counts[c]++
+			n++
+		}
+	}
+	h := 0.0
+	for _, k := range counts {
+		p := float64(k) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+type literal struct {
+	k kind
+	v any
+}
+
+func (n literal) kind() kind      { return n.k }
+func (n literal) eval(e *env) any { return n.v }
+
+type fieldNode struct {
+	k   kind
+	get func(e *env) any
+}
+
+func (n fieldNode) kind() kind      { return n.k }
+func (n fieldNode) eval(e *env) any { return n.get(e) }
+
+type listNode []node
+
+func (n listNode) kind() kind { return kindList }
+func (n listNode) eval(e *env) any {
+	out := make([]string, len(n))
+	for i, item := range n {
+		out[i] = item.eval(e).(string)
+	}
+	return out
+}
+
+type notNode struct{ x node }
+
+func (n notNode) kind() kind      { return kindBool }
+func (n notNode) eval(e *env) any { return !n.x.eval(e).(bool) }
+
+// andNode and orNode short-circuit, unless both operands hold counters:
+// counters compared with each other all see the event.
+type andNode struct {
+	x, y  node
+	eager bool
+}
+
+func (n andNode) kind() kind { return kindBool }
+func (n andNode) eval(e *env) any {
+	if n.eager {
+		x, y := n.x.eval(e).(bool), n.y.eval(e).(bool)
+		return x && y
+	}
+	return n.x.eval(e).(bool) && n.y.eval(e).(bool)
+}
+
+type orNode struct {
+	x, y  node
+	eager bool
+}
+
+func (n orNode) kind() kind { return kindBool }
+func (n orNode) eval(e *env) any {
+	if n.eager {
+		x, y := n.x.eval(e).(bool), n.y.eval(e).(bool)
+		return x || y
+	}
+	return n.x.eval(e).(bool) || n.y.eval(e).(bool)
+}
+
+// counts reports whether n holds a counter.
+func counts(n node) bool {
+	switch n := n.(type) {
+	case counterNode:
+		return true
+	case listNode:
+		for _, item := range n {
+			if counts(item) {
+				return true
+			}
+		}
+	case notNode:
+		return counts(n.x)
+	case andNode:
+		return counts(n.x) || counts(n.y)
+	case orNode:
+		return counts(n.x) || counts(n.y)
+	case compareNode:
+		return counts(n.x) || counts(n.y)
+	case inNode:
+		return counts(n.x) || counts(n.list)
+	case matchesNode:
+		return counts(n.x)
+	case indexNode:
+		return counts(n.list) || counts(n.index)
+	case cidrNode:
+		return counts(n.x)
+	case callNode:
+		for _, a := range n.args {
+			if counts(a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type compareNode struct {
+	op   string
+	x, y node
+}
+
+func (n compareNode) kind() kind { return kindBool }
+func (n compareNode) eval(e *env) any {
+	x, y := n.x.eval(e), n.y.eval(e)
+	switch n.op {
+	case "==":
+		return x == y
+	case "!=":
+		return x != y
+	}
+	a, b := x.(float64), y.(float64)
+	switch n.op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	}
+	return a >= b
+}
+
+type inNode struct{ x, list node }
+
+func (n inNode) kind() kind { return kindBool }
+func (n inNode) eval(e *env) any {
+	x := n.x.eval(e).(string)
+	for _, item := range n.list.eval(e).([]string) {
+		if item == x {
+			return true
+		}
+	}
+	return false
+}
+
+type matchesNode struct {
+	x  node
+	re *regexp.Regexp
+}
+
+func (n matchesNode) kind() kind      { return kindBool }
+func (n matchesNode) eval(e *env) any { return n.re.MatchString(n.x.eval(e).(string)) }
+
+type indexNode struct{ list, index node }
+
+func (n indexNode) kind() kind { return kindString }
+func (n indexNode) eval(e *env) any {
+	list := n.list.eval(e).([]string)
+	i := int(n.index.eval(e).(float64))
+	if i < 0 {
+		i += len(list)
+	}
+	if i < 0 || i >= len(list) {
+		return ""
+	}
+	return list[i]
+}
+
+type cidrNode struct {
+	x        node
+	prefixes []netip.Prefix
+}
+
+func (n cidrNode) kind() kind { return kindBool }
+func (n cidrNode) eval(e *env) any {
+	addr, err := netip.ParseAddr(n.x.eval(e).(string))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range n.prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+type callNode struct {
+	k    kind
+	args []node
+	f    func(args []any) any
+}
+
+func (n callNode) kind() kind { return n.k }
+func (n callNode) eval(e *env) any {
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.eval(e)
+	}
+	return n.f(args)
+}
+
+// counterNode is a windowed counter, by key:
+//
+//   - count(key, window), the number of events in the window;
+//   - distinct(key, value, window), the number of distinct values;
+//   - sum(key, value, window) and avg(key, value, window), the sum and the
+//     mean of a number;
+//   - ratio(key, condition, window), the share of the events meeting the
+//     condition, from 0 to 1.
+//
+// Keys are strings, or lists of strings such as [ip, registered]. Windows
+// slide unless followed by tumbling, as in count(ip, 1m, tumbling). Only the
+// events reaching the counter are counted: in `qtype == "TXT" && count(ip,
+// 1m) > 10` it counts the TXT queries of the source.
+type counterNode struct {
+	fn         string
+	key, value node
+	w          *window
+}
+
+func (n counterNode) kind() kind { return kindNumber }
+func (n counterNode) eval(e *env) any {
+	key := e.tenant
+	switch k := n.key.eval(e).(type) {
+	case string:
+		key += "\x00" + k
+	case []string:
+		key += "\x00" + strings.Join(k, "\x00")
+	}
+	switch n.fn {
+	case "count":
+		return float64(n.w.count(key, e.now))
+	case "distinct":
+		return float64(n.w.distinct(key, n.value.eval(e).(string), e.now))
+	}
+	var v float64
+	if n.fn == "ratio" {
+		if n.value.eval(e).(bool) {
+			v = 1
+		}
+	} else {
+		v = n.value.eval(e).(float64)
+	}
+	count, sum := n.w.add(key, v, e.now)
+	if n.fn == "avg" || n.fn == "ratio" {
+		if count == 0 {
+			return 0.0
+		}
+		return sum / float64(count)
+	}
+	return sum
+}
+
+// windowed are the functions taking a window.
+var windowed = map[string]bool{"count": true, "distinct": true, "sum": true, "avg": true, "ratio": true}
+
+// functions are the pure functions of expressions, by name.
+var functions = map[string]struct {
+	args []kind
+	k    kind
+	f    func(args []any) any
+}{
+	"lower": {[]kind{kindString}, kindString, func(a []any) any { return strings.ToLower(a[0].(string)) }},
+	"startsWith": {[]kind{kindString, kindString}, kindBool, func(a []any) any {
+		return strings.HasPrefix(a[0].(string), a[1].(string))
+	}},
+	"endsWith": {[]kind{kindString, kindString}, kindBool, func(a []any) any {
+		return strings.HasSuffix(a[0].(string), a[1].(string))
+	}},
+	"contains": {[]kind{kindString, kindString}, kindBool, func(a []any) any {
+		return strings.Contains(a[0].(string), a[1].(string))
+	}},
+	"entropy": {[]kind{kindString}, kindNumber, func(a []any) any { return entropy(a[0].(string)) }},
+	// dga scores a name for algorithmic generation, from 0 to 1
+	"dga": {[]kind{kindString}, kindNumber, func(a []any) any { return dga.Score(a[0].(string)) }},
+}
+
+// token is a token of an expression.
+type token struct {
+	// typ is "ident", "string", "number", "duration", "eof", or the
+	// operator itself.
+	typ  string
+	text string
+	pos  int
+}
+
+// operators are the operator tokens, longest first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", i+1)
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d", i+1)
+			}
+			tokens = append(tokens, token{"string", s, i})
+			i = j + 1
+		case c == '-' || c == '.' || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '.' || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			typ := "number"
+			for j < len(src) && unicode.IsLetter(rune(src[j])) {
+				typ = "duration"
+				j++
+			}
+			tokens = append(tokens, token{typ, src[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{"ident", src[i:j], i})
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i+1)
+			}
+			tokens = append(tokens, token{op, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{"eof", "", len(src)}), nil
+}
+
+// parser compiles an expression by recursive descent:
+//
+//	or      = and { "||" and }
+//	and     = compare { "&&" compare }
+//	compare = unary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) unary
+//	          | "in" unary | "matches" string ]
+//	unary   = "!" unary | postfix
+//	postfix = primary { "[" or "]" }
+//	primary = literal | field | call | "[" [ or { "," or } ] "]" | "(" or ")"
+type parser struct {
+	tokens []token
+	pos    int
+	// windows are the windows of the counters.
+	windows []*window
+}
+
+// compile returns the boolean expression src and the windows of its
+// counters.
+func compile(src string) (node, []*window, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.or()
+	if err != nil {
+		return nil, nil, err
+	}
+	if t := p.peek(); t.typ != "eof" {
+		return nil, nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	if n.kind() != kindBool {
+		return nil, nil, fmt.Errorf("the expression is a %s, not a bool", n.kind())
+	}
+	return n, p.windows, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != "eof" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(typ string) bool {
+	if p.peek().typ == typ {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(typ string) (token, error) {
+	t := p.next()
+	if t.typ != typ {
+		if t.typ == "eof" {
+			return t, p.errorf(t, "expected %q, got the end of the expression", typ)
+		}
+		return t, p.errorf(t, "expected %q, got %q", typ, t.text)
+	}
+	return t, nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", t.pos+1, fmt.Sprintf(format, args...))
+}
+
+// want returns an error unless n is of kind k.
+func (p *parser) want(t token, n node, k kind, what string) error {
+	if n.kind() != k {
+		return p.errorf(t, "%s must be a %s, not a %s", what, k, n.kind())
+	}
+	return nil
+}
+
+func (p *parser) or() (node, error) {
+	x, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); p.accept("||"); t = p.peek() {
+		y, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.want(t, x, kindBool, "the operands of ||"); err != nil {
+			return nil, err
+		}
+		if err := p.want(t, y, kindBool, "the operands of ||"); err != nil {
+			return nil, err
+		}
+		x = orNode{x, y, counts(x) && counts(y)}
+	}
+	return x, nil
+}
+
+func (p *parser) and() (node, error) {
+	x, err := p.compare()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); p.accept("&&"); t = p.peek() {
+		y, err := p.compare()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.want(t, x, kindBool, "the operands of &&"); err != nil {
+			return nil, err
+		}
+		if err := p.want(t, y, kindBool, "the operands of &&"); err != nil {
+			return nil, err
+		}
+		x = andNode{x, y, counts(x) && counts(y)}
+	}
+	return x, nil
+}
+
+func (p *parser) compare() (node, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch {
+	case t.typ == "==" || t.typ == "!=" || t.typ == "<" || t.typ == "<=" || t.typ == ">" || t.typ == ">=":
+		p.next()
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if x.kind() != y.kind() {
+			return nil, p.errorf(t, "cannot compare a %s with a %s", x.kind(), y.kind())
+		}
+		if t.typ == "==" || t.typ == "!=" {
+			if x.kind() == kindList {
+				return nil, p.errorf(t, "cannot compare lists")
+			}
+		} else if err := p.want(t, x, kindNumber, "the operands of "+t.typ); err != nil {
+			return nil, err
+		}
+		return compareNode{t.typ, x, y}, nil
+	case t.typ == "ident" && t.text == "in":
+		p.next()
+		list, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.want(t, x, kindString, "the left operand of in"); err != nil {
+			return nil, err
+		}
+		if err := p.want(t, list, kindList, "the right operand of in"); err != nil {
+			return nil, err
+		}
+		return inNode{x, list}, nil
+	case t.typ == "ident" && t.text == "matches":
+		p.next()
+		if err := p.want(t, x, kindString, "the left operand of matches"); err != nil {
+			return nil, err
+		}
+		s, err := p.expect("string")
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(s.text)
+		if err != nil {
+			return nil, p.errorf(s, "%v", err)
+		}
+		return matchesNode{x, re}, nil
+	}
+	return x, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if t := p.peek(); p.accept("!") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.want(t, x, kindBool, "the operand of !"); err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); p.accept("["); t = p.peek() {
+		i, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		if err := p.want(t, x, kindList, "an indexed value"); err != nil {
+			return nil, err
+		}
+		if err := p.want(t, i, kindNumber, "an index"); err != nil {
+			return nil, err
+		}
+		x = indexNode{x, i}
+	}
+	return x, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.typ {
+	case "string":
+		return literal{kindString, t.text}, nil
+	case "number":
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %q", t.text)
+		}
+		return literal{kindNumber, v}, nil
+	case "duration":
+		return nil, p.errorf(t, "a duration is only allowed as the window of count, distinct, sum, avg and ratio")
+	case "(":
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case "[":
+		var list listNode
+		for !p.accept("]") {
+			if len(list) > 0 {
+				if _, err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			item, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.want(t, item, kindString, "list items"); err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case "ident":
+		switch t.text {
+		case "true", "false":
+			return literal{kindBool, t.text == "true"}, nil
+		}
+		if p.peek().typ == "(" {
+			return p.call(t)
+		}
+		f, ok := fields[t.text]
+		if !ok {
+			return nil, p.errorf(t, "unknown field %q", t.text)
+		}
+		return fieldNode{f.kind, f.get}, nil
+	case "eof":
+		return nil, p.errorf(t, "unexpected end of the expression")
+	}
+	return nil, p.errorf(t, "unexpected %q", t.text)
+}
+
+// call parses the arguments of the function named by t.
+func (p *parser) call(name token) (node, error) {
+	p.next() // (
+	var args []node
+	var windowArg time.Duration
+	tumbling := false
+	for !p.accept(")") {
+		if len(args) > 0 || windowArg != 0 {
+			if _, err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		if t := p.peek(); t.typ == "duration" && windowed[name.text] && windowArg == 0 {
+			p.next()
+			d, err := time.ParseDuration(t.text)
+			if err != nil || d <= 0 {
+				return nil, p.errorf(t, "invalid window %q", t.text)
+			}
+			windowArg = d
+			continue
+		}
+		if t := p.peek(); windowArg != 0 && !tumbling && t.typ == "ident" && t.text == "tumbling" {
+			p.next()
+			tumbling = true
+			continue
+		}
+		if windowArg != 0 {
+			return nil, p.errorf(p.peek(), "the window must be the last argument of %s, optionally followed by tumbling", name.text)
+		}
+		arg, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	switch name.text {
+	case "count", "distinct", "sum", "avg", "ratio":
+		usage := map[string]string{
+			"count":    "count takes a key and a window, as in count(ip, 1m)",
+			"distinct": "distinct takes a key, a value and a window, as in distinct(ip, domain, 1m)",
+			"sum":      "sum takes a key, a number and a window, as in sum(ip, len(domain), 1m)",
+			"avg":      "avg takes a key, a number and a window, as in avg(ip, entropy(domain), 1m)",
+			"ratio":    `ratio takes a key, a condition and a window, as in ratio(ip, rcode == "NXDOMAIN", 1m)`,
+		}[name.text]
+		want := 2
+		if name.text == "count" {
+			want = 1
+		}
+		if len(args) != want || windowArg == 0 {
+			return nil, p.errorf(name, "%s", usage)
+		}
+		if k := args[0].kind(); k != kindString && k != kindList {
+			return nil, p.errorf(name, "the key of %s must be a string or a list", name.text)
+		}
+		c := counterNode{fn: name.text, key: args[0], w: newWindow(windowArg)}
+		if tumbling {
+			c.w = newTumblingWindow(windowArg)
+		}
+		p.windows = append(p.windows, c.w)
+		if want == 2 {
+			c.value = args[1]
+			valueKind := kindNumber
+			switch name.text {
+			case "distinct":
+				valueKind = kindString
+			case "ratio":
+				valueKind = kindBool
+			}
+			if err := p.want(name, c.value, valueKind, "the value of "+name.text); err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
+	case "len":
+		if len(args) != 1 || (args[0].kind() != kindString && args[0].kind() != kindList) {
+			return nil, p.errorf(name, "len takes a string or a list")
+		}
+		if args[0].kind() == kindString {
+			return callNode{kindNumber, args, func(a []any) any { return float64(len(a[0].(string))) }}, nil
+		}
+		return callNode{kindNumber, args, func(a []any) any { return float64(len(a[0].([]string))) }}, nil
+	case "inCIDR":
+		// The networks are parsed once, so they must be literals
+		if len(args) != 2 {
+			return nil, p.errorf(name, "inCIDR takes an address and a network or a list of networks")
+		}
+		if err := p.want(name, args[0], kindString, "the address of inCIDR"); err != nil {
+			return nil, err
+		}
+		var networks []node
+		switch a := args[1].(type) {
+		case literal:
+			networks = []node{a}
+		case listNode:
+			networks = a
+		}
+		c := cidrNode{x: args[0]}
+		for _, n := range networks {
+			lit, ok := n.(literal)
+			if !ok || lit.k != kindString {
+				return nil, p.errorf(name, "the networks of inCIDR must be strings")
+			}
+			prefix, err := netip.ParsePrefix(lit.v.(string))
+			if err != nil {
+				if addr, aerr := netip.ParseAddr(lit.v.(string)); aerr == nil {
+					prefix, err = addr.Prefix(addr.BitLen())
+				}
+			}
+			if err != nil {
+				return nil, p.errorf(name, "invalid network %q", lit.v)
+			}
+			c.prefixes = append(c.prefixes, prefix.Masked())
+		}
+		if len(c.prefixes) == 0 {
+			return nil, p.errorf(name, "the networks of inCIDR must be strings")
+		}
+		return c, nil
+	}
+	if windowArg != 0 {
+		return nil, p.errorf(name, "%s does not take a window", name.text)
+	}
+	f, ok := functions[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown function %q", name.text)
+	}
+	if len(args) != len(f.args) {
+		return nil, p.errorf(name, "%s takes %d arguments, not %d", name.text, len(f.args), len(args))
+	}
+	for i, a := range args {
+		if a.kind() != f.args[i] {
+			return nil, p.errorf(name, "argument %d of %s must be a %s, not a %s", i+1, name.text, f.args[i], a.kind())
+		}
+	}
+	return callNode{f.k, args, f.f}, nil
+}
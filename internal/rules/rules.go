@@ -0,0 +1,258 @@
+// Package rules is the detection engine of the consumer: declarative rules,
+// read from YAML, whose conditions are expressions over the fields of a DNS
+// request and windowed counters.
+//
+//	version: 2024-06-01
+//	rules:
+//	  - id: nxdomain-burst
+//	    severity: high
+//	    action: block
+//	    duration: 1h
+//	    reason: NXDOMAIN burst
+//	    when: rcode == "NXDOMAIN" && count(ip, 1m) > 50
+//
+// Expressions combine the fields ip, domain, registered, subdomain, labels,
+// qtype, rcode, transport, answers, port, query_size, response_size, ecs,
+// sensor and tenant with ==, !=, <, <=, >, >=, in, matches (a regular
+// expression), &&, || and !, list literals and indexes (labels[0],
+// labels[-1]), and the functions len, lower, startsWith, endsWith, contains,
+// inCIDR, entropy, dga (see package dga), and the windowed counters
+// count(key, window), distinct(key, value, window), sum(key, value, window),
+// avg(key, value, window) and ratio(key, condition, window), keyed on the
+// time of the events. Windows slide, or tumble when followed by tumbling:
+// count(ip, 1m, tumbling).
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of the rules, from the lowest.
+var Severities = []string{"low", "medium", "high", "critical"}
+
+// Actions of the rules.
+const (
+	// ActionBlock blocks the source of the request.
+	ActionBlock = "block"
+	// ActionAlert only reports the match.
+	ActionAlert = "alert"
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+
+// Rule is a detection rule.
+type Rule struct {
+	// ID identifies the rule in the blocks it makes: 1 to 63 lowercase
+	// letters, digits, dots, hyphens and underscores.
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	// Severity is one of Severities, medium when not set.
+	Severity string `yaml:"severity"`
+	// Action is ActionBlock, the default, or ActionAlert.
+	Action string `yaml:"action"`
+	// Duration is the duration of the blocks, 0 blocks until unblocked.
+	Duration time.Duration `yaml:"duration"`
+	// Reason is recorded with the blocks, after the rule.
+	Reason string `yaml:"reason"`
+	// Tenants restricts the rule to the requests of these tenants.
+	Tenants []string `yaml:"tenants"`
+	// When is the condition of the rule.
+	When string `yaml:"when"`
+}
+
+// BlockReason returns the reason of the blocks of r, citing it:
+// "rule <id> (<severity>): <reason>".
+func (r Rule) BlockReason() string {
+	s := fmt.Sprintf("rule %s (%s)", r.ID, r.Severity)
+	if r.Reason != "" {
+		s += ": " + r.Reason
+	}
+	return s
+}
+
+// compiled is a rule and its compiled condition.
+type compiled struct {
+	rule    Rule
+	when    node
+	windows []*window
+	tenants map[string]bool
+}
+
+// Set is a validated set of rules. It holds the state of their counters.
+type Set struct {
+	version string
+	rules   []*compiled
+}
+
+// Load reads the rules of the YAML file at path.
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse reads the rules of a YAML document. Its version is the version
+// field of the document, or "sha256:" followed by the start of the hash of
+// the document.
+func Parse(data []byte) (*Set, error) {
+	var file struct {
+		Version string `yaml:"version"`
+		Rules   []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	s, err := New(file.Rules)
+	if err != nil {
+		return nil, err
+	}
+	s.version = file.Version
+	if s.version == "" {
+		sum := sha256.Sum256(data)
+		s.version = "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	return s, nil
+}
+
+// New validates and compiles rules.
+func New(rules []Rule) (*Set, error) {
+	s := &Set{}
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if !idPattern.MatchString(r.ID) {
+			return nil, fmt.Errorf("invalid rule ID %q", r.ID)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("rule %s declared twice", r.ID)
+		}
+		seen[r.ID] = true
+		if r.Severity == "" {
+			r.Severity = "medium"
+		}
+		if Rank(r.Severity) < 0 {
+			return nil, fmt.Errorf("rule %s: unknown severity %q", r.ID, r.Severity)
+		}
+		switch r.Action {
+		case "":
+			r.Action = ActionBlock
+		case ActionBlock, ActionAlert:
+		default:
+			return nil, fmt.Errorf("rule %s: unknown action %q, expected %s or %s", r.ID, r.Action, ActionBlock, ActionAlert)
+		}
+		if r.Duration < 0 {
+			return nil, fmt.Errorf("rule %s: negative duration", r.ID)
+		}
+		if r.When == "" {
+			return nil, fmt.Errorf("rule %s: missing condition", r.ID)
+		}
+		when, windows, err := compile(r.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+		c := &compiled{rule: r, when: when, windows: windows}
+		if len(r.Tenants) > 0 {
+			c.tenants = make(map[string]bool)
+			for _, id := range r.Tenants {
+				c.tenants[id] = true
+			}
+		}
+		s.rules = append(s.rules, c)
+	}
+	return s, nil
+}
+
+// Rank returns the rank of severity in Severities, -1 if it is unknown.
+func Rank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// Version returns the version of s, empty for the sets made by New.
+func (s *Set) Version() string {
+	return s.version
+}
+
+// Inherit takes over the counters of the rules of prev with the same ID and
+// condition, so that replacing a set does not reset them. prev may be nil.
+func (s *Set) Inherit(prev *Set) {
+	if prev == nil {
+		return
+	}
+	byID := make(map[string]*compiled, len(prev.rules))
+	for _, c := range prev.rules {
+		byID[c.rule.ID] = c
+	}
+	for _, c := range s.rules {
+		if p, ok := byID[c.rule.ID]; ok && p.rule.When == c.rule.When {
+			c.when, c.windows = p.when, p.windows
+		}
+	}
+}
+
+// Keys returns the number of keys the counters of s hold. Each counter holds
+// up to 100000 keys, forgetting the least recently seen ones beyond.
+func (s *Set) Keys() int {
+	n := 0
+	for _, c := range s.rules {
+		for _, w := range c.windows {
+			n += w.len()
+		}
+	}
+	return n
+}
+
+// Evictions returns the number of keys the counters of s forgot to make room
+// for others.
+func (s *Set) Evictions() int64 {
+	var n int64
+	for _, c := range s.rules {
+		for _, w := range c.windows {
+			n += w.evicted.Load()
+		}
+	}
+	return n
+}
+
+// Rules returns the rules of s, in declaration order.
+func (s *Set) Rules() []Rule {
+	rules := make([]Rule, len(s.rules))
+	for i, c := range s.rules {
+		rules[i] = c.rule
+	}
+	return rules
+}
+
+// Eval evaluates the rules on the request req of the tenant, seen at now,
+// and returns the matching rules in declaration order. Every rule is
+// evaluated, even after a match, so that its counters see the request.
+func (s *Set) Eval(tenant string, req *pb.DnsRequest, now time.Time) []Rule {
+	e := &env{tenant: tenant, req: req, now: now}
+	var matches []Rule
+	for _, c := range s.rules {
+		if c.tenants != nil && !c.tenants[tenant] {
+			continue
+		}
+		if c.when.eval(e).(bool) {
+			matches = append(matches, c.rule)
+		}
+	}
+	return matches
+}
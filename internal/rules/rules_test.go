@@ -0,0 +1,314 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  - id: suffix
+    when: endsWith(ip, "70")
+  - id: txt
+    severity: low
+    action: alert
+    duration: 1h
+    reason: TXT query
+    tenants: [payments]
+    when: qtype == "TXT"
+`), 0o600))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{ID: "suffix", Severity: "medium", Action: ActionBlock, When: `endsWith(ip, "70")`},
+		{ID: "txt", Severity: "low", Action: ActionAlert, Duration: time.Hour, Reason: "TXT query", Tenants: []string{"payments"}, When: `qtype == "TXT"`},
+	}, s.Rules())
+	assert.Equal(t, "rule suffix (medium)", s.Rules()[0].BlockReason())
+	assert.Equal(t, "rule txt (low): TXT query", s.Rules()[1].BlockReason())
+
+	for _, rules := range [][]Rule{
+		{{ID: "Suffix", When: "true"}},
+		{{ID: "a", When: "true"}, {ID: "a", When: "true"}},
+		{{ID: "a", Severity: "urgent", When: "true"}},
+		{{ID: "a", Action: "drop", When: "true"}},
+		{{ID: "a", Duration: -time.Second, When: "true"}},
+		{{ID: "a"}},
+		{{ID: "a", When: "ip"}},
+	} {
+		_, err := New(rules)
+		assert.Error(t, err, rules)
+	}
+}
+
+func TestEval(t *testing.T) {
+	nxdomain := pb.ResponseCode_RESPONSE_CODE_NXDOMAIN
+	req := &pb.DnsRequest{
+		IpAddress:    "10.1.2.70",
+		Domain:       "a1b2.tunnel.example.com",
+		QueryType:    pb.QueryType_QUERY_TYPE_TXT,
+		Rcode:        &nxdomain,
+		Answers:      []*pb.Answer{{Type: pb.QueryType_QUERY_TYPE_A, Data: "192.0.2.1"}},
+		ClientPort:   5353,
+		Transport:    pb.Transport_TRANSPORT_DOH,
+		QuerySize:    120,
+		ResponseSize: 512,
+		SensorId:     "edge-1",
+	}
+	for expr, want := range map[string]bool{
+		`ip == "10.1.2.70"`:                                               true,
+		`endsWith(ip, "70") && !startsWith(ip, "10.")`:                    false,
+		`qtype in ["TXT", "NULL"]`:                                        true,
+		`qtype == "A" || rcode == "NXDOMAIN"`:                             true,
+		`len(labels) == 4 && labels[-1] == "com"`:                         true,
+		`labels[0] matches "^[a-z0-9]{4}$"`:                               true,
+		`labels[9] == ""`:                                                 true,
+		`"192.0.2.1" in answers`:                                          true,
+		`inCIDR(ip, "10.0.0.0/8")`:                                        true,
+		`inCIDR(ip, ["192.168.0.0/16", "10.1.2.70"])`:                     true,
+		`transport == "doh" && port >= 1024`:                              true,
+		`query_size < 100`:                                                false,
+		`sensor == "edge-1" && tenant == "payments"`:                      true,
+		`contains(lower(domain), "tunnel") && ecs == ""`:                  true,
+		`dga(domain) < 0.4 && dga("xjw8qk2lpz7v.com") > 0.6`:              true,
+		`registered == "example.com" && subdomain == "a1b2.tunnel"`:       true,
+		`entropy("aabb") == 1 && entropy("a.b") == 1 && entropy("") == 0`: true,
+	} {
+		s, err := New([]Rule{{ID: "r", When: expr}})
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, len(s.Eval("payments", req, time.Now())) == 1, expr)
+	}
+
+	s, err := New([]Rule{
+		{ID: "any", When: "true"},
+		{ID: "retail", Tenants: []string{"retail"}, When: "true"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, s.Eval("payments", req, time.Now()), 1)
+	assert.Len(t, s.Eval("retail", req, time.Now()), 2)
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`ip`,
+		`ip == 1`,
+		`ip < "a"`,
+		`labels == labels`,
+		`port in ["1"]`,
+		`ip in ip`,
+		`ip matches "("`,
+		`ip matches domain`,
+		`unknown == "a"`,
+		`nope(ip)`,
+		`endsWith(ip)`,
+		`endsWith(ip, 1)`,
+		`inCIDR(ip, "10.0.0.0/33")`,
+		`inCIDR(ip, domain)`,
+		`count(ip) > 1`,
+		`count(ip, 0s) > 1`,
+		`count(1m, ip) > 1`,
+		`distinct(ip, 1m) > 1`,
+		`lower(ip, 1m) == ""`,
+		`1m > 0`,
+		`(ip == "a"`,
+		`ip == "a")`,
+		`"unterminated`,
+		`!ip`,
+		`ip[0] == ""`,
+		`ip == "a" @`,
+		`response_size / 2 > 0`,
+		`count(port, 1m) > 1`,
+		`sum(ip, domain, 1m) > 1`,
+		`avg(ip, 1m) > 1`,
+		`distinct(ip, port, 1m) > 1`,
+		`ratio(ip, rcode, 1m) > 0.5`,
+		`count(ip, 1m, sliding) > 1`,
+		`count(ip, 1m, tumbling, tumbling) > 1`,
+		`count(ip, tumbling) > 1`,
+		`lower(ip, tumbling) == ""`,
+	} {
+		_, _, err := compile(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	nxdomain := pb.ResponseCode_RESPONSE_CODE_NXDOMAIN
+	s, err := New([]Rule{
+		{ID: "burst", When: `rcode == "NXDOMAIN" && count(ip, 1m) > 3`},
+		{ID: "spread", When: `distinct(ip, domain, 1m) >= 2`},
+	})
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	eval := func(tenant, ip, domain string, at time.Time) []string {
+		var ids []string
+		for _, r := range s.Eval(tenant, &pb.DnsRequest{IpAddress: ip, Domain: domain, Rcode: &nxdomain}, at) {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Empty(t, eval("t1", "10.0.0.1", "a.com", now.Add(time.Duration(i)*time.Second)))
+	}
+	assert.Equal(t, []string{"burst"}, eval("t1", "10.0.0.1", "a.com", now.Add(3*time.Second)))
+	// Counters are per tenant and per key
+	assert.Empty(t, eval("t2", "10.0.0.1", "b.com", now))
+	assert.Empty(t, eval("t1", "10.0.0.2", "b.com", now))
+	// Events slide out of the window
+	assert.Empty(t, eval("t1", "10.0.0.1", "b.com", now.Add(2*time.Minute)))
+	assert.Equal(t, []string{"spread"}, eval("t1", "10.0.0.1", "c.com", now.Add(2*time.Minute)))
+	assert.Equal(t, []string{"spread"}, eval("t1", "10.0.0.1", "c.com", now.Add(2*time.Minute)))
+}
+
+func TestSumAndAvg(t *testing.T) {
+	s, err := New([]Rule{
+		{ID: "bytes", When: `sum([ip, registered], len(subdomain), 1m) > 20`},
+		{ID: "entropy", When: `avg([ip, registered], entropy(subdomain), 1m) >= 2`},
+	})
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	eval := func(ip, domain string) []string {
+		var ids []string
+		for _, r := range s.Eval("t", &pb.DnsRequest{IpAddress: ip, Domain: domain}, now) {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	assert.Empty(t, eval("10.0.0.1", "aaaaaaaaaa.example.com"))
+	// Keys are pairs of the source and the registered domain
+	assert.Empty(t, eval("10.0.0.2", "bbbbbbbbbbb.example.com"))
+	assert.Empty(t, eval("10.0.0.1", "ccccccccccc.example.org"))
+	assert.Equal(t, []string{"bytes"}, eval("10.0.0.1", "abcdabcdabcd.example.com"))
+	// The mean entropy is (0 + 2 + 3) / 3, then (0 + 2 + 3 + 3) / 4
+	assert.Equal(t, []string{"bytes"}, eval("10.0.0.1", "abcdefgh.example.com"))
+	assert.Equal(t, []string{"bytes", "entropy"}, eval("10.0.0.1", "hgfedcba.example.com"))
+}
+
+func TestPerSource(t *testing.T) {
+	nxdomain := pb.ResponseCode_RESPONSE_CODE_NXDOMAIN
+	s, err := New([]Rule{
+		{ID: "rate", When: `count(ip, 1m, tumbling) > 3`},
+		{ID: "nxdomain-ratio", When: `count(ip, 1m) >= 4 && ratio(ip, rcode == "NXDOMAIN", 1m) > 0.5`},
+		{ID: "txt", When: `qtype == "TXT" && count([ip, qtype], 1m) > 1`},
+	})
+	require.NoError(t, err)
+	// 10 seconds before the end of a minute
+	start := time.Unix(1700000090, 0)
+	eval := func(qtype pb.QueryType, nx bool, at time.Time) []string {
+		req := &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "a.com", QueryType: qtype}
+		if nx {
+			req.Rcode = &nxdomain
+		}
+		var ids []string
+		for _, r := range s.Eval("t", req, at) {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	assert.Empty(t, eval(pb.QueryType_QUERY_TYPE_A, true, start))
+	assert.Empty(t, eval(pb.QueryType_QUERY_TYPE_A, false, start))
+	assert.Empty(t, eval(pb.QueryType_QUERY_TYPE_TXT, true, start.Add(time.Second)))
+	// Both counters of nxdomain-ratio see every event: 3 NXDOMAIN of 4
+	assert.Equal(t, []string{"rate", "nxdomain-ratio"}, eval(pb.QueryType_QUERY_TYPE_A, true, start.Add(2*time.Second)))
+	// The tumbling window restarts at the minute, the sliding ones do not
+	assert.Equal(t, []string{"nxdomain-ratio"}, eval(pb.QueryType_QUERY_TYPE_A, true, start.Add(12*time.Second)))
+	assert.Equal(t, []string{"nxdomain-ratio", "txt"}, eval(pb.QueryType_QUERY_TYPE_TXT, false, start.Add(13*time.Second)))
+
+	assert.Equal(t, 4, s.Keys())
+	assert.Zero(t, s.Evictions())
+}
+
+func TestWindow(t *testing.T) {
+	w := newWindow(10 * time.Second)
+	now := time.Unix(1700000000, 0)
+	assert.Equal(t, 1, w.count("a", now))
+	assert.Equal(t, 2, w.count("a", now.Add(5*time.Second)))
+	// A late event in the window is counted, an older one is not
+	assert.Equal(t, 3, w.count("a", now.Add(time.Second)))
+	assert.Equal(t, 3, w.count("a", now.Add(-time.Minute)))
+	assert.Equal(t, 2, w.count("a", now.Add(12*time.Second)))
+	assert.Equal(t, 1, w.count("a", now.Add(time.Hour)))
+
+	assert.Equal(t, 1, w.distinct("b", "x", now))
+	assert.Equal(t, 1, w.distinct("b", "x", now.Add(time.Second)))
+	assert.Equal(t, 2, w.distinct("b", "y", now.Add(5*time.Second)))
+	assert.Equal(t, 2, w.distinct("b", "z", now.Add(12*time.Second)))
+
+	// Memory is bounded by forgetting the least recently seen keys
+	w = newWindow(time.Minute)
+	w.maxKeys = 3
+	for i := 0; i < 5; i++ {
+		w.count(fmt.Sprint(i), now)
+	}
+	w.count("2", now)
+	w.count("5", now)
+	assert.Equal(t, 3, w.lru.Len())
+	assert.Len(t, w.keys, 3)
+	assert.Equal(t, 3, w.count("2", now))
+	assert.Equal(t, 1, w.count("3", now))
+	assert.Equal(t, int64(4), w.evicted.Load())
+
+	// Tumbling windows count from the start of the window, late events of
+	// past windows are dropped
+	w = newTumblingWindow(time.Minute)
+	now = time.Unix(1700000040, 0)
+	assert.Equal(t, 1, w.count("a", now))
+	assert.Equal(t, 2, w.count("a", now.Add(59*time.Second)))
+	assert.Equal(t, 1, w.count("a", now.Add(time.Minute)))
+	assert.Equal(t, 1, w.count("a", now))
+	assert.Equal(t, 1, w.distinct("b", "x", now.Add(time.Minute)))
+	assert.Equal(t, 1, w.distinct("b", "y", now))
+}
+
+func TestWindowClampsFutureEvents(t *testing.T) {
+	w := newWindow(time.Minute)
+	now := time.Now()
+	assert.Equal(t, 1, w.count("a", now))
+	// An event a year ahead is counted as if it came now, and does not make
+	// the next events late
+	assert.Equal(t, 2, w.count("a", now.AddDate(1, 0, 0)))
+	assert.Equal(t, 3, w.count("a", now.Add(time.Second)))
+	assert.Equal(t, 1, w.distinct("b", "x", now.AddDate(1, 0, 0)))
+	assert.Equal(t, 2, w.distinct("b", "y", now))
+}
+
+func TestVersionAndInherit(t *testing.T) {
+	s, err := Parse([]byte("version: v1\nrules: []\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", s.Version())
+	s, err = Parse([]byte("rules: []\n"))
+	require.NoError(t, err)
+	assert.Regexp(t, `^sha256:[0-9a-f]{12}$`, s.Version())
+
+	burst := Rule{ID: "burst", When: "count(ip, 1m) > 1"}
+	prev, err := New([]Rule{burst})
+	require.NoError(t, err)
+	now := time.Now()
+	req := &pb.DnsRequest{IpAddress: "10.0.0.1"}
+	assert.Empty(t, prev.Eval("t", req, now))
+
+	// The counters of unchanged rules survive the replacement of the set
+	next, err := New([]Rule{burst, {ID: "other", When: "count(ip, 1m) > 1"}})
+	require.NoError(t, err)
+	next.Inherit(prev)
+	next.Inherit(nil)
+	matches := next.Eval("t", req, now)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "burst", matches[0].ID)
+
+	changed, err := New([]Rule{{ID: "burst", When: "count(ip, 1m) > 2"}})
+	require.NoError(t, err)
+	changed.Inherit(next)
+	assert.Empty(t, changed.Eval("t", req, now))
+}
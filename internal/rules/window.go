@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// windowBuckets is the number of buckets of a sliding window: counts
+	// slide by a tenth of the window.
+	windowBuckets = 10
+	// maxKeys bounds the keys of a counter. The least recently seen keys
+	// are forgotten first.
+	maxKeys = 100000
+	// maxValues bounds the values distinct keeps per key, the count
+	// saturates there.
+	maxValues = 10000
+	// maxSkew bounds how far ahead of the clock of the consumer event times
+	// may be. Later times are clamped, so that an event from a sensor with a
+	// wrong clock does not move the window forward and make the next events
+	// of its key late.
+	maxSkew = 5 * time.Second
+)
+
+// window holds the windowed counters of a count or distinct call, by key.
+// Sliding windows are made of windowBuckets buckets, tumbling windows of a
+// single one, aligned on multiples of the window since the Unix epoch.
+type window struct {
+	width   time.Duration
+	n       int64
+	maxKeys int
+	// evicted counts the keys forgotten to make room for others.
+	evicted atomic.Int64
+
+	mu   sync.Mutex
+	keys map[string]*list.Element
+	// lru orders the *slot of the keys, least recently seen first.
+	lru *list.List
+}
+
+// slot holds the counts of a key.
+type slot struct {
+	key string
+	// last is the newest bucket, buckets[b%n] the count of bucket b and
+	// sums[b%n] the sum of its values.
+	last    int64
+	buckets [windowBuckets]uint32
+	sums    [windowBuckets]float64
+	// values are the distinct values, with the last bucket they were seen
+	// in.
+	values map[string]int64
+}
+
+func newWindow(d time.Duration) *window {
+	return &window{
+		width:   max(d/windowBuckets, 1),
+		n:       windowBuckets,
+		maxKeys: maxKeys,
+		keys:    make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func newTumblingWindow(d time.Duration) *window {
+	w := newWindow(d)
+	w.width, w.n = d, 1
+	return w
+}
+
+// len returns the number of keys of w.
+func (w *window) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lru.Len()
+}
+
+// slot returns the slot of key, advanced to the bucket of now, and the
+// bucket; w.mu must be held. now is clamped to maxSkew past the current
+// time.
+func (w *window) slot(key string, now time.Time) (*slot, int64) {
+	if limit := time.Now().Add(maxSkew); now.After(limit) {
+		now = limit
+	}
+	b := now.UnixNano() / int64(w.width)
+	el, ok := w.keys[key]
+	if ok {
+		w.lru.MoveToBack(el)
+	} else {
+		if w.lru.Len() >= w.maxKeys {
+			delete(w.keys, w.lru.Remove(w.lru.Front()).(*slot).key)
+			w.evicted.Add(1)
+		}
+		el = w.lru.PushBack(&slot{key: key, last: b})
+		w.keys[key] = el
+	}
+	s := el.Value.(*slot)
+	if b > s.last {
+		if b-s.last >= w.n {
+			s.buckets = [windowBuckets]uint32{}
+			s.sums = [windowBuckets]float64{}
+		} else {
+			for i := s.last + 1; i <= b; i++ {
+				s.buckets[i%w.n] = 0
+				s.sums[i%w.n] = 0
+			}
+		}
+		s.last = b
+		for v, seen := range s.values {
+			if seen <= b-w.n {
+				delete(s.values, v)
+			}
+		}
+	}
+	return s, b
+}
+
+// add counts an event of key with the value v at now and returns the number
+// of events of key in the window and the sum of their values. Late events are
+// counted in their bucket while it is in the window.
+func (w *window) add(key string, v float64, now time.Time) (int, float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s, b := w.slot(key, now)
+	if b > s.last-w.n {
+		s.buckets[b%w.n]++
+		s.sums[b%w.n] += v
+	}
+	n, sum := 0, 0.0
+	for i, c := range s.buckets {
+		n += int(c)
+		sum += s.sums[i]
+	}
+	return n, sum
+}
+
+// count counts an event of key at now and returns the events of key in the
+// window.
+func (w *window) count(key string, now time.Time) int {
+	n, _ := w.add(key, 0, now)
+	return n
+}
+
+// distinct records value for key at now and returns the number of distinct
+// values of key in the window.
+func (w *window) distinct(key, value string, now time.Time) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s, b := w.slot(key, now)
+	if s.values == nil {
+		s.values = make(map[string]int64)
+	}
+	if seen, ok := s.values[value]; ok {
+		s.values[value] = max(seen, b)
+	} else if len(s.values) < maxValues && b > s.last-w.n {
+		s.values[value] = b
+	}
+	return len(s.values)
+}
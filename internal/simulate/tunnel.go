@@ -0,0 +1,102 @@
+// Package simulate generates DNS requests resembling the traffic of some
+// attacks, for the client and for tests.
+package simulate
+
+import (
+	"encoding/base32"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dnsname"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+// encoding is the base32 alphabet of iodine and dnscat2, in lower case.
+var encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// tunnelTypes are the query types of a tunnel, weighted: mostly TXT, which
+// carries the most data back, then NULL and CNAME.
+var tunnelTypes = []pb.QueryType{
+	pb.QueryType_QUERY_TYPE_TXT, pb.QueryType_QUERY_TYPE_TXT, pb.QueryType_QUERY_TYPE_TXT,
+	pb.QueryType_QUERY_TYPE_NULL, pb.QueryType_QUERY_TYPE_CNAME,
+}
+
+// Tunnel generates the queries of a DNS tunnel, such as iodine or dnscat2,
+// exfiltrating data from a source through the names under a domain: every
+// name starts with a sequence number, followed by up to MaxPayload bytes of
+// data encoded in base32 labels of up to 63 characters.
+type Tunnel struct {
+	Source string
+	Domain string
+	// MaxPayload is the number of bytes encoded in a name, at most, capped
+	// by the length limit of names.
+	MaxPayload int
+
+	rand *rand.Rand
+	seq  uint32
+}
+
+// minPayload is the number of bytes a name must be able to carry, at least.
+const minPayload = 8
+
+// NewTunnel returns a tunnel from source through domain, whose data is drawn
+// from seed. It fails if domain is invalid or too long to leave room for
+// minPayload bytes in the names.
+func NewTunnel(source, domain string, seed int64) (*Tunnel, error) {
+	normalized, err := dnsname.Normalize(domain)
+	if err != nil {
+		return nil, err
+	}
+	if room := payloadRoom(normalized); room < minPayload {
+		return nil, fmt.Errorf("domain %s is too long for a tunnel: names would carry %d bytes, at least %d are needed", normalized, room, minPayload)
+	}
+	return &Tunnel{Source: source, Domain: normalized, MaxPayload: 110, rand: rand.New(rand.NewSource(seed))}, nil
+}
+
+// payloadRoom returns the number of bytes the names under domain can carry.
+func payloadRoom(domain string) int {
+	// 253 characters at most: the payload labels, their dots, the
+	// sequence number, of 4 characters, and the domain
+	room := 253 - len(domain) - 4 - 2
+	room -= room / 64
+	return max(room*5/8, 0)
+}
+
+// Next returns the next query of the tunnel, at now.
+func (t *Tunnel) Next(now time.Time) *pb.DnsRequest {
+	t.seq++
+	prefix := fmt.Sprintf("%04x", t.seq&0xffff)
+	size := max(min(t.MaxPayload, payloadRoom(t.Domain)), 0)
+	payload := make([]byte, size/2+t.rand.Intn(size/2+1))
+	t.rand.Read(payload)
+	data := encoding.EncodeToString(payload)
+
+	labels := []string{prefix}
+	for len(data) > 63 {
+		labels, data = append(labels, data[:63]), data[63:]
+	}
+	if data != "" {
+		labels = append(labels, data)
+	}
+	name := strings.Join(append(labels, t.Domain), ".")
+
+	req := &pb.DnsRequest{
+		IpAddress:  t.Source,
+		Domain:     name,
+		QueryType:  tunnelTypes[t.rand.Intn(len(tunnelTypes))],
+		Timestamp:  now.Unix(),
+		ClientPort: uint32(1024 + t.rand.Intn(64512)),
+		Transport:  pb.Transport_TRANSPORT_UDP,
+		QuerySize:  uint32(len(name) + 18),
+		Rcode:      pb.ResponseCode_RESPONSE_CODE_NOERROR.Enum(),
+	}
+	// The server sends data back in the answer
+	reply := make([]byte, 32+t.rand.Intn(128))
+	t.rand.Read(reply)
+	answer := encoding.EncodeToString(reply)
+	req.Answers = []*pb.Answer{{Type: req.QueryType, Data: answer}}
+	req.ResponseSize = req.QuerySize + uint32(len(answer)) + 12
+	return req
+}
@@ -0,0 +1,49 @@
+package simulate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dnsname"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTunnel(t *testing.T) {
+	for _, domain := range []string{"t.example.com", strings.Repeat("d", 63) + "." + strings.Repeat("e", 63) + ".example"} {
+		tunnel, err := NewTunnel("10.0.0.5", domain, 1)
+		require.NoError(t, err)
+		seen := make(map[string]bool)
+		for i := 0; i < 200; i++ {
+			req := tunnel.Next(time.Now())
+			name := req.GetDomain()
+			assert.Equal(t, "10.0.0.5", req.GetIpAddress())
+			assert.True(t, strings.HasSuffix(name, "."+domain), name)
+			assert.False(t, seen[name], name)
+			seen[name] = true
+			normalized, err := dnsname.Normalize(name)
+			require.NoError(t, err, name)
+			assert.Equal(t, name, normalized)
+		}
+	}
+	// The same seed gives the same traffic
+	a, err := NewTunnel("a", "t.example", 7)
+	require.NoError(t, err)
+	b, err := NewTunnel("a", "t.example", 7)
+	require.NoError(t, err)
+	assert.Equal(t, a.Next(time.Unix(0, 0)).GetDomain(), b.Next(time.Unix(0, 0)).GetDomain())
+
+	// Domains leaving no room for data are rejected
+	long := strings.Repeat("d", 63) + "." + strings.Repeat("e", 63) + "." + strings.Repeat("f", 63) + "." + strings.Repeat("g", 50) + ".example"
+	for _, domain := range []string{long, "bad..example"} {
+		_, err := NewTunnel("a", domain, 1)
+		assert.Error(t, err, domain)
+	}
+
+	// A negative payload size is treated as an empty one
+	tunnel, err := NewTunnel("a", "t.example", 1)
+	require.NoError(t, err)
+	tunnel.MaxPayload = -1
+	assert.Equal(t, "0001.t.example", tunnel.Next(time.Now()).GetDomain())
+}
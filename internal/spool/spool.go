@@ -0,0 +1,414 @@
+// Package spool implements a bounded on-disk FIFO queue of bus messages. The
+// server appends the events it cannot hand to the event bus, and replays them
+// in order once the bus is reachable again.
+//
+// The spool is a directory of segment files holding length-prefixed records,
+// and a cursor file recording the position of the oldest unacknowledged
+// record. Segments are deleted once every record in them is acknowledged.
+// Records are written without fsync: they survive a crash of the process but
+// not of the host.
+package spool
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+)
+
+// ErrFull is returned by Append when the spool reached its size limit.
+var ErrFull = errors.New("spool: full")
+
+// ErrEmpty is returned by Peek when the spool holds no message.
+var ErrEmpty = errors.New("spool: empty")
+
+const (
+	segmentSuffix = ".seg"
+	cursorFile    = "cursor"
+	// headerSize is the size of the record header: payload length and CRC-32
+	// of the payload.
+	headerSize = 8
+	// maxSegmentBytes caps the size of a segment, so that acknowledged
+	// records are reclaimed while the spool drains.
+	maxSegmentBytes = 64 << 20
+)
+
+// record is the payload of a spooled message.
+type record struct {
+	AppendedAt time.Time    `json:"appended_at"`
+	Message    *bus.Message `json:"message"`
+}
+
+type segment struct {
+	seq  uint64
+	size int64
+}
+
+// Spool is a bounded on-disk FIFO queue of messages, safe for concurrent use.
+type Spool struct {
+	mu           sync.Mutex
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+
+	segments []segment
+	// size is the total size of the segments, count the number of
+	// unacknowledged records.
+	size  int64
+	count int
+
+	writer *os.File
+	reader *os.File
+	cursor *os.File
+	// readOffset is the offset in the first segment of the head record.
+	readOffset int64
+
+	// head is the decoded head record and headSize its size on disk, cached
+	// between Peek and Ack.
+	head     *record
+	headSize int64
+}
+
+// Open opens the spool in dir, creating it if needed. The segments of the
+// spool take at most maxBytes on disk.
+func Open(dir string, maxBytes int64) (*Spool, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("spool: invalid size limit %d", maxBytes)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Spool{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		segmentBytes: min(maxBytes/8+1, maxSegmentBytes),
+	}
+	if err := s.load(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load lists the segments, restores the cursor and counts the records.
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), segmentSuffix)
+		if !ok {
+			continue
+		}
+		seq, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		s.segments = append(s.segments, segment{seq: seq, size: info.Size()})
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+
+	if s.cursor, err = os.OpenFile(filepath.Join(s.dir, cursorFile), os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		return err
+	}
+	var buf [16]byte
+	if n, _ := s.cursor.ReadAt(buf[:], 0); n == len(buf) {
+		seq, offset := binary.BigEndian.Uint64(buf[:8]), int64(binary.BigEndian.Uint64(buf[8:]))
+		// Segments before the cursor were acknowledged but not deleted
+		for len(s.segments) > 0 && s.segments[0].seq < seq {
+			if err := os.Remove(s.segmentPath(s.segments[0].seq)); err != nil {
+				return err
+			}
+			s.segments = s.segments[1:]
+		}
+		if len(s.segments) > 0 && s.segments[0].seq == seq {
+			s.readOffset = min(offset, s.segments[0].size)
+		}
+	}
+
+	if len(s.segments) == 0 {
+		s.segments = []segment{{seq: 1}}
+	}
+	for i := range s.segments {
+		start := int64(0)
+		if i == 0 {
+			start = s.readOffset
+		}
+		n, end, err := s.scan(s.segments[i].seq, start)
+		if err != nil {
+			return err
+		}
+		if end < s.segments[i].size {
+			// Torn write of the last record before a crash
+			if err := os.Truncate(s.segmentPath(s.segments[i].seq), end); err != nil {
+				return err
+			}
+			s.segments[i].size = end
+		}
+		s.count += n
+		s.size += s.segments[i].size
+	}
+
+	last := s.segments[len(s.segments)-1]
+	if s.writer, err = os.OpenFile(s.segmentPath(last.seq), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644); err != nil {
+		return err
+	}
+	if s.reader, err = os.Open(s.segmentPath(s.segments[0].seq)); err != nil {
+		return err
+	}
+	return s.saveCursor()
+}
+
+// scan counts the valid records of a segment from offset start, and returns
+// the offset following the last one.
+func (s *Spool) scan(seq uint64, start int64) (int, int64, error) {
+	f, err := os.Open(s.segmentPath(seq))
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	n, offset := 0, start
+	for {
+		_, size, err := readRecord(f, offset, s.maxBytes)
+		if err != nil {
+			return n, offset, nil
+		}
+		n++
+		offset += size
+	}
+}
+
+func (s *Spool) segmentPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, segmentSuffix))
+}
+
+// Append adds m at the tail of the spool.
+func (s *Spool) Append(m *bus.Message) error {
+	payload, err := json.Marshal(record{AppendedAt: time.Now().UTC(), Message: m})
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[headerSize:], payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writer == nil {
+		return bus.ErrClosed
+	}
+	if s.size+int64(len(buf)) > s.maxBytes {
+		return ErrFull
+	}
+	tail := &s.segments[len(s.segments)-1]
+	if tail.size > 0 && tail.size+int64(len(buf)) > s.segmentBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+		tail = &s.segments[len(s.segments)-1]
+	}
+	if _, err := s.writer.Write(buf); err != nil {
+		return err
+	}
+	tail.size += int64(len(buf))
+	s.size += int64(len(buf))
+	s.count++
+	return nil
+}
+
+// rotate starts a new tail segment.
+func (s *Spool) rotate() error {
+	seq := s.segments[len(s.segments)-1].seq + 1
+	f, err := os.OpenFile(s.segmentPath(seq), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := s.writer.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	s.writer.Close()
+	s.writer = f
+	s.segments = append(s.segments, segment{seq: seq})
+	return nil
+}
+
+// Peek returns the message at the head of the spool, or ErrEmpty.
+func (s *Spool) Peek() (*bus.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadHead(); err != nil {
+		return nil, err
+	}
+	return s.head.Message, nil
+}
+
+// loadHead decodes the head record, moving to the next segment when the
+// first one is exhausted.
+func (s *Spool) loadHead() error {
+	if s.reader == nil {
+		return bus.ErrClosed
+	}
+	if s.head != nil {
+		return nil
+	}
+	if s.count == 0 {
+		return ErrEmpty
+	}
+	for s.readOffset >= s.segments[0].size {
+		if err := s.dropFirstSegment(); err != nil {
+			return err
+		}
+	}
+	r, size, err := readRecord(s.reader, s.readOffset, s.maxBytes)
+	if err != nil {
+		return fmt.Errorf("spool: reading segment %d at %d: %w", s.segments[0].seq, s.readOffset, err)
+	}
+	s.head, s.headSize = r, size
+	return nil
+}
+
+// Ack removes the message returned by the last Peek from the spool.
+func (s *Spool) Ack() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadHead(); err != nil {
+		return err
+	}
+	s.readOffset += s.headSize
+	s.head, s.headSize = nil, 0
+	s.count--
+	if s.readOffset >= s.segments[0].size {
+		return s.dropFirstSegment()
+	}
+	return s.saveCursor()
+}
+
+// dropFirstSegment deletes the fully acknowledged first segment. The tail
+// segment is truncated instead, as it is still open for writing.
+func (s *Spool) dropFirstSegment() error {
+	if len(s.segments) == 1 {
+		if err := s.writer.Truncate(0); err != nil {
+			return err
+		}
+		s.size -= s.segments[0].size
+		s.segments[0].size = 0
+		s.readOffset = 0
+		return s.saveCursor()
+	}
+	first := s.segments[0]
+	reader, err := os.Open(s.segmentPath(s.segments[1].seq))
+	if err != nil {
+		return err
+	}
+	s.reader.Close()
+	s.reader = reader
+	s.segments = s.segments[1:]
+	s.size -= first.size
+	s.readOffset = 0
+	if err := s.saveCursor(); err != nil {
+		return err
+	}
+	return os.Remove(s.segmentPath(first.seq))
+}
+
+func (s *Spool) saveCursor() error {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], s.segments[0].seq)
+	binary.BigEndian.PutUint64(buf[8:], uint64(s.readOffset))
+	_, err := s.cursor.WriteAt(buf[:], 0)
+	return err
+}
+
+// Len returns the number of messages in the spool.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Size returns the size of the spool on disk in bytes.
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Oldest returns the time the head message was appended, or the zero time
+// if the spool is empty.
+func (s *Spool) Oldest() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadHead(); err != nil {
+		return time.Time{}
+	}
+	return s.head.AppendedAt
+}
+
+// Close syncs and closes the spool files.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	if s.writer != nil {
+		errs = append(errs, s.writer.Sync(), s.writer.Close())
+		s.writer = nil
+	}
+	if s.reader != nil {
+		errs = append(errs, s.reader.Close())
+		s.reader = nil
+	}
+	if s.cursor != nil {
+		errs = append(errs, s.cursor.Close())
+		s.cursor = nil
+	}
+	return errors.Join(errs...)
+}
+
+// readRecord decodes the record of f at offset and returns it with its size
+// on disk. Records larger than limit are corrupted.
+func readRecord(f *os.File, offset, limit int64) (*record, int64, error) {
+	var header [headerSize]byte
+	if _, err := f.ReadAt(header[:], offset); err != nil {
+		return nil, 0, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if int64(length) > limit {
+		return nil, 0, errors.New("record length out of range")
+	}
+	payload := make([]byte, length)
+	if _, err := f.ReadAt(payload, offset+headerSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errors.New("checksum mismatch")
+	}
+	var r record
+	if err := json.Unmarshal(payload, &r); err != nil {
+		return nil, 0, err
+	}
+	return &r, headerSize + int64(length), nil
+}
@@ -0,0 +1,165 @@
+package spool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func message(i int) *bus.Message {
+	return &bus.Message{
+		Topic:   "myTopic",
+		Key:     []byte(fmt.Sprintf("10.0.0.%d", i)),
+		Value:   []byte(fmt.Sprintf("event %d", i)),
+		Headers: []bus.Header{{Key: "n", Value: []byte{byte(i)}}},
+	}
+}
+
+// drain acks every message of s and returns their values in order.
+func drain(t *testing.T, s *Spool) []string {
+	var values []string
+	for {
+		m, err := s.Peek()
+		if err == ErrEmpty {
+			return values
+		}
+		require.NoError(t, err)
+		values = append(values, string(m.Value))
+		require.NoError(t, s.Ack())
+	}
+}
+
+func values(from, to int) []string {
+	var v []string
+	for i := from; i < to; i++ {
+		v = append(v, fmt.Sprintf("event %d", i))
+	}
+	return v
+}
+
+func TestAppendPeekAck(t *testing.T) {
+	s, err := Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Peek()
+	assert.ErrorIs(t, err, ErrEmpty)
+	assert.True(t, s.Oldest().IsZero())
+
+	before := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Append(message(i)))
+	}
+	assert.Equal(t, 3, s.Len())
+	assert.Positive(t, s.Size())
+	assert.WithinRange(t, s.Oldest(), before.Add(-time.Second), time.Now())
+
+	m, err := s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, message(0), m)
+	m, err = s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "event 0", string(m.Value), "Peek does not advance")
+
+	assert.Equal(t, values(0, 3), drain(t, s))
+	assert.Equal(t, 0, s.Len())
+	assert.Zero(t, s.Size(), "a drained spool is truncated")
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, 1<<20)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Append(message(i)))
+	}
+	for i := 0; i < 2; i++ {
+		_, err := s.Peek()
+		require.NoError(t, err)
+		require.NoError(t, s.Ack())
+	}
+	require.NoError(t, s.Close())
+
+	s, err = Open(dir, 1<<20)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, 3, s.Len())
+	require.NoError(t, s.Append(message(5)))
+	assert.Equal(t, values(2, 6), drain(t, s))
+}
+
+func TestFull(t *testing.T) {
+	s, err := Open(t.TempDir(), 1024)
+	require.NoError(t, err)
+	defer s.Close()
+
+	n := 0
+	for ; ; n++ {
+		if err := s.Append(message(n)); err != nil {
+			assert.ErrorIs(t, err, ErrFull)
+			break
+		}
+	}
+	assert.Positive(t, n)
+	assert.LessOrEqual(t, s.Size(), int64(1024))
+
+	// Acknowledged segments free space for new messages.
+	for i := 0; i < n; i++ {
+		_, err := s.Peek()
+		require.NoError(t, err)
+		require.NoError(t, s.Ack())
+	}
+	require.NoError(t, s.Append(message(n)))
+	assert.Equal(t, values(n, n+1), drain(t, s))
+}
+
+func TestSegmentRotation(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, 16384)
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := 0; i < 30; i++ {
+		require.NoError(t, s.Append(message(i)))
+	}
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	assert.Greater(t, len(segments), 1)
+
+	for i := 0; i < 20; i++ {
+		_, err := s.Peek()
+		require.NoError(t, err)
+		require.NoError(t, s.Ack())
+	}
+	left, _ := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	assert.Less(t, len(left), len(segments), "acknowledged segments are deleted")
+	assert.Equal(t, values(20, 30), drain(t, s))
+}
+
+func TestTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, s.Append(message(0)))
+	require.NoError(t, s.Append(message(1)))
+	require.NoError(t, s.Close())
+
+	// Cut the last record in half, as a crash in the middle of a write would
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	require.Len(t, segments, 1)
+	info, err := os.Stat(segments[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(segments[0], info.Size()-5))
+
+	s, err = Open(dir, 1<<20)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, 1, s.Len())
+	require.NoError(t, s.Append(message(2)))
+	assert.Equal(t, []string{"event 0", "event 2"}, drain(t, s))
+}
@@ -0,0 +1,251 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltBucket      = []byte("blacklist")
+	boltAllowBucket = []byte("allowlist")
+	boltRuleBucket  = []byte("block_rules")
+)
+
+// Bolt is a Store keeping the blacklist in an embedded bbolt database file,
+// for single-node deployments that need persistence without Redis. The
+// tenants have their own buckets, "blacklist:<tenant>" and
+// "allowlist:<tenant>", and "block_rules:<tenant>".
+type Bolt struct {
+	db  *bolt.DB
+	now func() time.Time
+	// bucket, allowBucket and ruleBucket hold the blacklist, the allowlist
+	// and the block rules.
+	bucket      []byte
+	allowBucket []byte
+	ruleBucket  []byte
+	// shared is set on the stores of the tenants, which do not own db.
+	shared bool
+}
+
+// OpenBolt opens, or creates, the bbolt database at path.
+func OpenBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	b := &Bolt{db: db, now: time.Now, bucket: boltBucket, allowBucket: boltAllowBucket, ruleBucket: boltRuleBucket}
+	if err := b.createBuckets(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Bolt) createBuckets() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{b.bucket, b.allowBucket, b.ruleBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Bolt) Tenant(id string) (Store, error) {
+	t := &Bolt{
+		db:          b.db,
+		now:         b.now,
+		bucket:      []byte(string(boltBucket) + ":" + id),
+		allowBucket: []byte(string(boltAllowBucket) + ":" + id),
+		ruleBucket:  []byte(string(boltRuleBucket) + ":" + id),
+		shared:      true,
+	}
+	if err := t.createBuckets(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (b *Bolt) Block(ctx context.Context, e Entry) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(e.IP), value)
+	})
+}
+
+func (b *Bolt) Unblock(ctx context.Context, ip string) (bool, error) {
+	found := false
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		value := bucket.Get([]byte(ip))
+		if value == nil {
+			return nil
+		}
+		var e Entry
+		if err := json.Unmarshal(value, &e); err != nil {
+			return err
+		}
+		found = !e.Expired(b.now())
+		return bucket.Delete([]byte(ip))
+	})
+	return found, err
+}
+
+func (b *Bolt) Get(ctx context.Context, ip string) (Entry, error) {
+	var e Entry
+	err := b.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(b.bucket).Get([]byte(ip))
+		if value == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(value, &e); err != nil {
+			return err
+		}
+		if e.Expired(b.now()) {
+			return ErrNotFound
+		}
+		return nil
+	})
+	return e, err
+}
+
+// List returns the unexpired entries and deletes the expired ones.
+func (b *Bolt) List(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		now := b.now()
+		bucket := tx.Bucket(b.bucket)
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			if e.Expired(now) {
+				expired = append(expired, k)
+				return nil
+			}
+			entries = append(entries, e)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func (b *Bolt) Allow(ctx context.Context, e AllowEntry) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.allowBucket).Put([]byte(e.Value), value)
+	})
+}
+
+func (b *Bolt) Disallow(ctx context.Context, value string) (bool, error) {
+	found := false
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.allowBucket)
+		found = bucket.Get([]byte(value)) != nil
+		return bucket.Delete([]byte(value))
+	})
+	return found, err
+}
+
+func (b *Bolt) ListAllowed(ctx context.Context) ([]AllowEntry, error) {
+	var entries []AllowEntry
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.allowBucket).ForEach(func(k, v []byte) error {
+			var e AllowEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+func (b *Bolt) AddBlockRule(ctx context.Context, r BlockRule) error {
+	value, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.ruleBucket).Put([]byte(r.ID), value)
+	})
+}
+
+func (b *Bolt) RemoveBlockRule(ctx context.Context, id string) (bool, error) {
+	found := false
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.ruleBucket)
+		value := bucket.Get([]byte(id))
+		if value == nil {
+			return nil
+		}
+		var r BlockRule
+		if err := json.Unmarshal(value, &r); err != nil {
+			return err
+		}
+		found = !r.Expired(b.now())
+		return bucket.Delete([]byte(id))
+	})
+	return found, err
+}
+
+// ListBlockRules returns the unexpired rules and deletes the expired ones.
+func (b *Bolt) ListBlockRules(ctx context.Context) ([]BlockRule, error) {
+	var rules []BlockRule
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		now := b.now()
+		bucket := tx.Bucket(b.ruleBucket)
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var r BlockRule
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if r.Expired(now) {
+				expired = append(expired, k)
+				return nil
+			}
+			rules = append(rules, r)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return rules, err
+}
+
+func (b *Bolt) Close() error {
+	if b.shared {
+		return nil
+	}
+	return b.db.Close()
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Store keeping the blacklist in process memory. Its content is
+// lost on restart, so it is meant for tests and single-node development.
+type Memory struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+	allowed map[string]AllowEntry
+	rules   map[string]BlockRule
+	now     func() time.Time
+	// tenants holds the stores of the tenants, see Tenant.
+	tenants map[string]*Memory
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		entries: make(map[string]Entry),
+		allowed: make(map[string]AllowEntry),
+		rules:   make(map[string]BlockRule),
+		now:     time.Now,
+	}
+}
+
+func (m *Memory) Tenant(id string) (Store, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tenants == nil {
+		m.tenants = make(map[string]*Memory)
+	}
+	t, ok := m.tenants[id]
+	if !ok {
+		t = NewMemory()
+		t.now = m.now
+		m.tenants[id] = t
+	}
+	return t, nil
+}
+
+func (m *Memory) Block(ctx context.Context, e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[e.IP] = e
+	return nil
+}
+
+func (m *Memory) Unblock(ctx context.Context, ip string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[ip]
+	delete(m.entries, ip)
+	return ok && !e.Expired(m.now()), nil
+}
+
+func (m *Memory) Get(ctx context.Context, ip string) (Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[ip]
+	if !ok || e.Expired(m.now()) {
+		return Entry{}, ErrNotFound
+	}
+	return e, nil
+}
+
+func (m *Memory) List(ctx context.Context) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	entries := make([]Entry, 0, len(m.entries))
+	for ip, e := range m.entries {
+		if e.Expired(now) {
+			delete(m.entries, ip)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (m *Memory) Allow(ctx context.Context, e AllowEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowed[e.Value] = e
+	return nil
+}
+
+func (m *Memory) Disallow(ctx context.Context, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.allowed[value]
+	delete(m.allowed, value)
+	return ok, nil
+}
+
+func (m *Memory) ListAllowed(ctx context.Context) ([]AllowEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := make([]AllowEntry, 0, len(m.allowed))
+	for _, e := range m.allowed {
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (m *Memory) AddBlockRule(ctx context.Context, r BlockRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules[r.ID] = r
+	return nil
+}
+
+func (m *Memory) RemoveBlockRule(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.rules[id]
+	delete(m.rules, id)
+	return ok && !r.Expired(m.now()), nil
+}
+
+func (m *Memory) ListBlockRules(ctx context.Context) ([]BlockRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	rules := make([]BlockRule, 0, len(m.rules))
+	for id, r := range m.rules {
+		if r.Expired(now) {
+			delete(m.rules, id)
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
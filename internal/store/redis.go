@@ -0,0 +1,343 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/go-redis/redis/v8"
+)
+
+// legacyValue is the value of the keys of the blocked IPs in the releases
+// before the namespaced keys.
+const legacyValue = "malicious"
+
+// RedisConfig configures the Redis backend.
+type RedisConfig struct {
+	// Mode is "standalone", "sentinel" or "cluster".
+	Mode string
+	// Addrs is the server address in standalone mode, the sentinel addresses
+	// in sentinel mode and the seed nodes in cluster mode.
+	Addrs []string
+	// MasterName is the name of the master monitored by the sentinels.
+	MasterName string
+	Password   string
+	// Namespace is the hash tag of every key, see Redis.
+	Namespace string
+}
+
+// Redis is a Store keeping each entry as a JSON value under
+// "{<namespace>}:ip:<ip>", expired with the Redis key TTL, and indexing the
+// IPs in the sorted set "{<namespace>}:index" scored by expiry time. The
+// allowlist is the hash "{<namespace>}:allowlist" of JSON entries by value,
+// and the block rules the hash "{<namespace>}:rules" of JSON rules by ID,
+// whose expired rules are deleted by ListBlockRules.
+//
+// The namespace is a hash tag, so in cluster mode every key of a namespace
+// lives in the same slot and the multi-key operations (MULTI/EXEC updates of
+// an entry and its index, MGET of the listed entries) are allowed. The
+// blacklist of a namespace is thus held by a single shard and its replicas.
+//
+// The keys of a tenant are in the namespace "<namespace>:<tenant>", so that
+// the tenants are spread over the shards of a cluster.
+type Redis struct {
+	client    redis.UniversalClient
+	namespace string
+	prefix    string
+	// shared is set on the stores of the tenants, which do not own client.
+	shared bool
+}
+
+// NewRedis returns a store connected to Redis as configured by cfg.
+func NewRedis(cfg RedisConfig) (*Redis, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, fmt.Errorf("store: no Redis address")
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = "blacklist"
+	}
+	var client redis.UniversalClient
+	switch cfg.Mode {
+	case "standalone", "":
+		client = redis.NewClient(&redis.Options{Addr: cfg.Addrs[0], Password: cfg.Password})
+	case "sentinel":
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("store: sentinel mode needs a master name")
+		}
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    cfg.MasterName,
+			SentinelAddrs: cfg.Addrs,
+			Password:      cfg.Password,
+		})
+	case "cluster":
+		client = redis.NewClusterClient(&redis.ClusterOptions{Addrs: cfg.Addrs, Password: cfg.Password})
+	default:
+		return nil, fmt.Errorf("store: unknown Redis mode %q", cfg.Mode)
+	}
+	return &Redis{client: client, namespace: cfg.Namespace, prefix: "{" + cfg.Namespace + "}:"}, nil
+}
+
+func (r *Redis) Tenant(id string) (Store, error) {
+	namespace := r.namespace + ":" + id
+	return &Redis{client: r.client, namespace: namespace, prefix: "{" + namespace + "}:", shared: true}, nil
+}
+
+func (r *Redis) key(ip string) string {
+	return r.prefix + "ip:" + ip
+}
+
+func (r *Redis) index() string {
+	return r.prefix + "index"
+}
+
+func (r *Redis) allowlist() string {
+	return r.prefix + "allowlist"
+}
+
+func (r *Redis) rules() string {
+	return r.prefix + "rules"
+}
+
+// score is the index score of an entry, its expiry in Unix milliseconds.
+func score(e Entry) float64 {
+	if e.ExpiresAt.IsZero() {
+		return math.Inf(1)
+	}
+	return float64(e.ExpiresAt.UnixMilli())
+}
+
+func (r *Redis) Block(ctx context.Context, e Entry) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if !e.ExpiresAt.IsZero() {
+		ttl = time.Until(e.ExpiresAt)
+		if ttl <= 0 {
+			_, err := r.Unblock(ctx, e.IP)
+			return err
+		}
+	}
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.key(e.IP), value, ttl)
+	pipe.ZAdd(ctx, r.index(), &redis.Z{Score: score(e), Member: e.IP})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *Redis) Unblock(ctx context.Context, ip string) (bool, error) {
+	pipe := r.client.TxPipeline()
+	del := pipe.Del(ctx, r.key(ip))
+	pipe.ZRem(ctx, r.index(), ip)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return del.Val() > 0, nil
+}
+
+func (r *Redis) Get(ctx context.Context, ip string) (Entry, error) {
+	value, err := r.client.Get(ctx, r.key(ip)).Bytes()
+	if err == redis.Nil {
+		return Entry{}, ErrNotFound
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	var e Entry
+	if err := json.Unmarshal(value, &e); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// listBatchSize is the number of entries fetched by each MGET of List.
+const listBatchSize = 500
+
+// List reads the unexpired IPs from the index, fetches their entries and
+// prunes the expired IPs from the index.
+func (r *Redis) List(ctx context.Context) ([]Entry, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if err := r.client.ZRemRangeByScore(ctx, r.index(), "-inf", now).Err(); err != nil {
+		return nil, err
+	}
+	ips, err := r.client.ZRangeByScore(ctx, r.index(), &redis.ZRangeBy{Min: "(" + now, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(ips))
+	for start := 0; start < len(ips); start += listBatchSize {
+		end := min(start+listBatchSize, len(ips))
+		keys := make([]string, 0, end-start)
+		for _, ip := range ips[start:end] {
+			keys = append(keys, r.key(ip))
+		}
+		values, err := r.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			s, ok := v.(string)
+			if !ok {
+				// Expired, or unblocked, since the index was read
+				continue
+			}
+			var e Entry
+			if err := json.Unmarshal([]byte(s), &e); err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (r *Redis) Allow(ctx context.Context, e AllowEntry) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, r.allowlist(), e.Value, value).Err()
+}
+
+func (r *Redis) Disallow(ctx context.Context, value string) (bool, error) {
+	n, err := r.client.HDel(ctx, r.allowlist(), value).Result()
+	return n > 0, err
+}
+
+func (r *Redis) ListAllowed(ctx context.Context) ([]AllowEntry, error) {
+	values, err := r.client.HVals(ctx, r.allowlist()).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]AllowEntry, 0, len(values))
+	for _, v := range values {
+		var e AllowEntry
+		if err := json.Unmarshal([]byte(v), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (r *Redis) AddBlockRule(ctx context.Context, rule BlockRule) error {
+	value, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, r.rules(), rule.ID, value).Err()
+}
+
+func (r *Redis) RemoveBlockRule(ctx context.Context, id string) (bool, error) {
+	value, err := r.client.HGet(ctx, r.rules(), id).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	n, err := r.client.HDel(ctx, r.rules(), id).Result()
+	if err != nil || n == 0 {
+		// Removed by someone else since it was read
+		return false, err
+	}
+	var rule BlockRule
+	if err := json.Unmarshal(value, &rule); err != nil {
+		return false, err
+	}
+	return !rule.Expired(time.Now()), nil
+}
+
+func (r *Redis) ListBlockRules(ctx context.Context) ([]BlockRule, error) {
+	values, err := r.client.HGetAll(ctx, r.rules()).Result()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	rules := make([]BlockRule, 0, len(values))
+	var expired []string
+	for id, v := range values {
+		var rule BlockRule
+		if err := json.Unmarshal([]byte(v), &rule); err != nil {
+			return nil, err
+		}
+		if rule.Expired(now) {
+			expired = append(expired, id)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(expired) > 0 {
+		if err := r.client.HDel(ctx, r.rules(), expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// MigrateLegacy imports the blacklist of the releases before the namespaced
+// keys, which kept each blocked IP as a key of its own holding "malicious",
+// without expiry. Each such key becomes an entry without expiry, unless the
+// IP is already blocked, and is then deleted, so that the migration runs once
+// however often it is called. It returns the number of entries imported.
+func (r *Redis) MigrateLegacy(ctx context.Context) (int, error) {
+	// ForEachMaster runs concurrently on the masters
+	var imported atomic.Int64
+	migrate := func(ctx context.Context, c *redis.Client) error {
+		iter := c.Scan(ctx, 0, "*", 1000).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			ip, err := ipaddr.Canonical(key)
+			if err != nil {
+				continue
+			}
+			value, err := c.Get(ctx, key).Result()
+			if err == redis.Nil || (err != nil && strings.HasPrefix(err.Error(), "WRONGTYPE")) {
+				// Expired since the scan, or not a string
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if value != legacyValue {
+				continue
+			}
+			if _, err := r.Get(ctx, ip); err == ErrNotFound {
+				e := Entry{IP: ip, Reason: "imported from a legacy key", CreatedAt: time.Now().UTC()}
+				if err := r.Block(ctx, e); err != nil {
+					return err
+				}
+				imported.Add(1)
+			} else if err != nil {
+				return err
+			}
+			if err := c.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+	var err error
+	switch c := r.client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, migrate)
+	case *redis.Client:
+		err = migrate(ctx, c)
+	}
+	return int(imported.Load()), err
+}
+
+func (r *Redis) Close() error {
+	if r.shared {
+		return nil
+	}
+	return r.client.Close()
+}
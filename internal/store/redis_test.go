@@ -0,0 +1,231 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store/storetest"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests start local redis-server processes and are skipped when
+// redis-server is not in the PATH.
+
+// freePort returns a TCP port that is free at the time of the call.
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// startRedis starts a redis-server process on a free port with the given
+// extra arguments, waits until it answers PING and returns its address and
+// process. The process is killed at the end of the test.
+func startRedis(t *testing.T, args ...string) (string, *exec.Cmd) {
+	return startRedisProcess(t, "", args...)
+}
+
+// startSentinel starts a Redis sentinel process with the config file conf,
+// which must be writable.
+func startSentinel(t *testing.T, conf string) string {
+	addr, _ := startRedisProcess(t, conf, "--sentinel")
+	return addr
+}
+
+func startRedisProcess(t *testing.T, conf string, args ...string) (string, *exec.Cmd) {
+	bin, err := exec.LookPath("redis-server")
+	if err != nil {
+		t.Skip("redis-server not in PATH")
+	}
+	dir := t.TempDir()
+	port := freePort(t)
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	argv := []string{"--port", strconv.Itoa(port), "--bind", "127.0.0.1", "--dir", dir}
+	if conf != "" {
+		// The config file must be the first argument
+		argv = append([]string{conf}, argv...)
+	} else {
+		argv = append(argv, "--save", "", "--appendonly", "no")
+	}
+	cmd := exec.Command(bin, append(argv, args...)...)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	require.Eventually(t, func() bool {
+		return client.Ping(context.Background()).Err() == nil
+	}, 5*time.Second, 50*time.Millisecond, "redis-server at %s did not start", addr)
+	return addr, cmd
+}
+
+func infoField(client *redis.Client, section, field string) string {
+	info, err := client.Info(context.Background(), section).Result()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(info, "\r\n") {
+		if v, ok := strings.CutPrefix(line, field+":"); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// TestRedisSentinelFailover blocks an IP through the sentinels, kills the
+// master and checks that the store keeps the entry and accepts writes once
+// the sentinel has promoted the replica.
+func TestRedisSentinelFailover(t *testing.T) {
+	ctx := context.Background()
+	masterAddr, master := startRedis(t)
+	_, masterPort, _ := net.SplitHostPort(masterAddr)
+	replicaAddr, _ := startRedis(t, "--replicaof", "127.0.0.1", masterPort)
+
+	replica := redis.NewClient(&redis.Options{Addr: replicaAddr})
+	defer replica.Close()
+	require.Eventually(t, func() bool {
+		return infoField(replica, "replication", "master_link_status") == "up"
+	}, 10*time.Second, 100*time.Millisecond, "replica did not sync")
+
+	conf := filepath.Join(t.TempDir(), "sentinel.conf")
+	require.NoError(t, os.WriteFile(conf, []byte(fmt.Sprintf(
+		"sentinel monitor mymaster 127.0.0.1 %s 1\n"+
+			"sentinel down-after-milliseconds mymaster 500\n"+
+			"sentinel failover-timeout mymaster 2000\n", masterPort)), 0o600))
+	sentinelAddr := startSentinel(t, conf)
+
+	s, err := store.NewRedis(store.RedisConfig{
+		Mode:       "sentinel",
+		Addrs:      []string{sentinelAddr},
+		MasterName: "mymaster",
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "192.168.1.70", Reason: "before failover", CreatedAt: time.Now()}))
+	masterClient := redis.NewClient(&redis.Options{Addr: masterAddr})
+	require.NoError(t, masterClient.Do(ctx, "WAIT", 1, 5000).Err())
+	masterClient.Close()
+
+	require.NoError(t, master.Process.Kill())
+
+	// Commands fail while the master is down, until the sentinel promotes
+	// the replica and the client reconnects to it.
+	require.Eventually(t, func() bool {
+		return infoField(replica, "replication", "role") == "master"
+	}, 20*time.Second, 100*time.Millisecond, "replica was not promoted")
+	require.Eventually(t, func() bool {
+		return s.Block(ctx, store.Entry{IP: "10.0.0.1", Reason: "after failover", CreatedAt: time.Now()}) == nil
+	}, 10*time.Second, 100*time.Millisecond, "store did not recover after failover")
+
+	e, err := s.Get(ctx, "192.168.1.70")
+	require.NoError(t, err)
+	assert.Equal(t, "before failover", e.Reason)
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+// TestRedisCluster runs the conformance suite against a three node cluster,
+// which fails with CROSSSLOT errors if the multi-key operations span slots.
+func TestRedisCluster(t *testing.T) {
+	ctx := context.Background()
+	var addrs []string
+	var clients []*redis.Client
+	for i := 0; i < 3; i++ {
+		dir := t.TempDir()
+		addr, _ := startRedis(t, "--cluster-enabled", "yes",
+			"--cluster-config-file", filepath.Join(dir, "nodes.conf"),
+			"--cluster-node-timeout", "2000")
+		addrs = append(addrs, addr)
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		defer client.Close()
+		clients = append(clients, client)
+	}
+
+	const slots = 16384
+	for i, client := range clients {
+		require.NoError(t, client.ClusterAddSlotsRange(ctx, i*slots/3, (i+1)*slots/3-1).Err())
+		if i > 0 {
+			host, port, _ := net.SplitHostPort(addrs[i])
+			require.NoError(t, clients[0].ClusterMeet(ctx, host, port).Err())
+		}
+	}
+	for _, client := range clients {
+		client := client
+		require.Eventually(t, func() bool {
+			info, err := client.ClusterInfo(ctx).Result()
+			return err == nil && strings.Contains(info, "cluster_state:ok")
+		}, 20*time.Second, 100*time.Millisecond, "cluster did not converge")
+	}
+
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{Addrs: addrs})
+	defer cluster.Close()
+	storetest.Run(t, func(t *testing.T) store.Store {
+		require.NoError(t, cluster.ForEachMaster(ctx, func(ctx context.Context, c *redis.Client) error {
+			return c.FlushDB(ctx).Err()
+		}))
+		s, err := store.NewRedis(store.RedisConfig{Mode: "cluster", Addrs: addrs})
+		require.NoError(t, err)
+		return s
+	})
+}
+
+func TestNewRedisConfig(t *testing.T) {
+	_, err := store.NewRedis(store.RedisConfig{})
+	assert.Error(t, err, "no address")
+	_, err = store.NewRedis(store.RedisConfig{Mode: "sentinel", Addrs: []string{"127.0.0.1:26379"}})
+	assert.Error(t, err, "no master name")
+	_, err = store.NewRedis(store.RedisConfig{Mode: "replicated", Addrs: []string{"127.0.0.1:6379"}})
+	assert.Error(t, err, "unknown mode")
+}
+
+// TestRedisMigrateLegacy imports the raw IP keys of earlier releases.
+func TestRedisMigrateLegacy(t *testing.T) {
+	ctx := context.Background()
+	addr, _ := startRedis(t)
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	require.NoError(t, client.Set(ctx, "192.168.1.70", "malicious", 0).Err())
+	require.NoError(t, client.Set(ctx, "10.0.0.1", "malicious", 0).Err())
+	require.NoError(t, client.Set(ctx, "10.0.0.2", "something else", 0).Err())
+	require.NoError(t, client.Set(ctx, "session", "malicious", 0).Err())
+	require.NoError(t, client.HSet(ctx, "10.0.0.3", "field", "malicious").Err())
+
+	s, err := store.NewRedis(store.RedisConfig{Addrs: []string{addr}})
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.1", Reason: "kept", CreatedAt: time.Now()}))
+
+	n, err := s.MigrateLegacy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	e, err := s.Get(ctx, "192.168.1.70")
+	require.NoError(t, err)
+	assert.True(t, e.ExpiresAt.IsZero())
+	e, err = s.Get(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "kept", e.Reason)
+	assert.Zero(t, client.Exists(ctx, "192.168.1.70", "10.0.0.1").Val())
+	assert.Equal(t, int64(3), client.Exists(ctx, "10.0.0.2", "session", "10.0.0.3").Val())
+
+	// The legacy keys are gone, so the migration runs once
+	n, err = s.MigrateLegacy(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
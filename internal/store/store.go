@@ -0,0 +1,121 @@
+// Package store persists the IP blacklist behind the Store interface so that
+// the server can run on Redis, in memory or on an embedded bbolt file. The
+// store opened by Open holds the default tenant, see Store.Tenant for the
+// others.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned by Get when the IP is not blacklisted.
+var ErrNotFound = errors.New("store: ip not blacklisted")
+
+// Entry is a blacklisted IP with its metadata.
+type Entry struct {
+	IP        string    `json:"ip"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is the time the block is lifted, zero if it never expires.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired reports whether the entry has expired at now.
+func (e Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// AllowEntry is an allowlist entry, protecting an IP, a CIDR or a domain
+// suffix from being blocked.
+type AllowEntry struct {
+	// Value is the IP, CIDR or domain suffix, in canonical form.
+	Value string `json:"value"`
+	// Kind is "ip", "cidr" or "domain".
+	Kind      string    `json:"kind"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BlockRule is a scoped block: requests from Source for a domain matching
+// DomainPattern with the type QueryType are blocked, the other requests of
+// the source are not.
+type BlockRule struct {
+	// ID identifies the rule, it is derived from the scope so that adding a
+	// rule replaces the rule with the same scope.
+	ID string `json:"id"`
+	// Source is an IP or a CIDR, in canonical form, empty for any source.
+	Source string `json:"source,omitempty"`
+	// DomainPattern is a domain, "*.<domain>" for its subdomains, or empty
+	// for any domain.
+	DomainPattern string `json:"domain_pattern,omitempty"`
+	// QueryType is the RR type number, 0 for any type.
+	QueryType uint32    `json:"query_type,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is the time the rule is lifted, zero if it never expires.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired reports whether the rule has expired at now.
+func (r BlockRule) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Store is the blacklist and allowlist storage used by the server.
+type Store interface {
+	// Block adds the entry, replacing any entry for the same IP.
+	Block(ctx context.Context, e Entry) error
+	// Unblock removes ip from the blacklist and reports whether it was there.
+	Unblock(ctx context.Context, ip string) (bool, error)
+	// Get returns the entry of ip, or ErrNotFound if it is not blacklisted.
+	Get(ctx context.Context, ip string) (Entry, error)
+	// List returns every entry that has not expired, in no particular order.
+	List(ctx context.Context) ([]Entry, error)
+	// Allow adds the allowlist entry, replacing any entry with the same value.
+	Allow(ctx context.Context, e AllowEntry) error
+	// Disallow removes value from the allowlist and reports whether it was
+	// there.
+	Disallow(ctx context.Context, value string) (bool, error)
+	// ListAllowed returns every allowlist entry, in no particular order.
+	ListAllowed(ctx context.Context) ([]AllowEntry, error)
+	// AddBlockRule adds the rule, replacing any rule with the same ID.
+	AddBlockRule(ctx context.Context, r BlockRule) error
+	// RemoveBlockRule removes the rule id and reports whether it was there.
+	RemoveBlockRule(ctx context.Context, id string) (bool, error)
+	// ListBlockRules returns every rule that has not expired, in no
+	// particular order.
+	ListBlockRules(ctx context.Context) ([]BlockRule, error)
+	// Tenant returns the store of the tenant id, whose blacklist and
+	// allowlist are kept apart from those of this store. It shares the
+	// resources of this store: closing it does nothing.
+	Tenant(id string) (Store, error)
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// Config selects and configures a Store backend.
+type Config struct {
+	// Backend is "redis", "memory" or "bolt".
+	Backend string
+	// Redis configures the redis backend.
+	Redis RedisConfig
+	// BoltPath is the database file used by the bolt backend.
+	BoltPath string
+}
+
+// Open returns the Store selected by cfg.
+func Open(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "redis", "":
+		return NewRedis(cfg.Redis)
+	case "memory":
+		return NewMemory(), nil
+	case "bolt":
+		return OpenBolt(cfg.BoltPath)
+	default:
+		return nil, fmt.Errorf("store: unknown backend %q", cfg.Backend)
+	}
+}
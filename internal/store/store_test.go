@@ -0,0 +1,44 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store/storetest"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.NewMemory()
+	})
+}
+
+func TestBolt(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := store.OpenBolt(filepath.Join(t.TempDir(), "blacklist.db"))
+		require.NoError(t, err)
+		return s
+	})
+}
+
+// TestRedis runs against the Redis server at REDIS_ADDR, whose database is
+// flushed before each subtest.
+func TestRedis(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	storetest.Run(t, func(t *testing.T) store.Store {
+		require.NoError(t, client.FlushDB(context.Background()).Err())
+		s, err := store.NewRedis(store.RedisConfig{Addrs: []string{addr}})
+		require.NoError(t, err)
+		return s
+	})
+}
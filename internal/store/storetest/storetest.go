@@ -0,0 +1,293 @@
+// Package storetest is the conformance test suite shared by every
+// store.Store implementation.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run runs the conformance suite. newStore must return an empty store, it is
+// called once per subtest and the store is closed at the end of the subtest.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s store.Store)
+	}{
+		{"BlockAndGet", testBlockAndGet},
+		{"GetMissing", testGetMissing},
+		{"BlockReplaces", testBlockReplaces},
+		{"Unblock", testUnblock},
+		{"List", testList},
+		{"Expiry", testExpiry},
+		{"Concurrent", testConcurrent},
+		{"Allowlist", testAllowlist},
+		{"BlockRules", testBlockRules},
+		{"Tenants", testTenants},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			defer s.Close()
+			tt.fn(t, s)
+		})
+	}
+}
+
+// now is truncated because backends are not required to keep sub-second
+// precision.
+func now() time.Time {
+	return time.Now().Truncate(time.Second).UTC()
+}
+
+func testBlockAndGet(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	created := now()
+	expires := created.Add(time.Hour)
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "192.168.1.70", Reason: "scanner", CreatedAt: created, ExpiresAt: expires}))
+
+	e, err := s.Get(ctx, "192.168.1.70")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.70", e.IP)
+	assert.Equal(t, "scanner", e.Reason)
+	assert.True(t, created.Equal(e.CreatedAt), "created_at %v, want %v", e.CreatedAt, created)
+	assert.True(t, expires.Equal(e.ExpiresAt), "expires_at %v, want %v", e.ExpiresAt, expires)
+}
+
+func testGetMissing(t *testing.T, s store.Store) {
+	_, err := s.Get(context.Background(), "10.0.0.1")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testBlockReplaces(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.1", Reason: "first", CreatedAt: now(), ExpiresAt: now().Add(time.Hour)}))
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.1", Reason: "second", CreatedAt: now()}))
+
+	e, err := s.Get(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "second", e.Reason)
+	assert.True(t, e.ExpiresAt.IsZero(), "replacing entry should drop the expiry")
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func testUnblock(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.1", CreatedAt: now()}))
+
+	found, err := s.Unblock(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	_, err = s.Get(ctx, "10.0.0.1")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	found, err = s.Unblock(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func testList(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	want := []string{"10.0.0.1", "10.0.0.2", "192.168.1.70"}
+	for _, ip := range want {
+		require.NoError(t, s.Block(ctx, store.Entry{IP: ip, CreatedAt: now()}))
+	}
+	entries, err = s.List(ctx)
+	require.NoError(t, err)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.IP)
+	}
+	sort.Strings(got)
+	assert.Equal(t, want, got)
+}
+
+func testExpiry(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.1", CreatedAt: now(), ExpiresAt: time.Now().Add(-time.Second)}))
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.2", CreatedAt: now(), ExpiresAt: time.Now().Add(200 * time.Millisecond)}))
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.3", CreatedAt: now()}))
+
+	_, err := s.Get(ctx, "10.0.0.1")
+	assert.ErrorIs(t, err, store.ErrNotFound, "entry already expired when blocked")
+	_, err = s.Get(ctx, "10.0.0.2")
+	assert.NoError(t, err)
+
+	time.Sleep(400 * time.Millisecond)
+	_, err = s.Get(ctx, "10.0.0.2")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "10.0.0.3", entries[0].IP)
+
+	found, err := s.Unblock(ctx, "10.0.0.2")
+	require.NoError(t, err)
+	assert.False(t, found, "expired entry should not be reported as unblocked")
+}
+
+func testConcurrent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ip := fmt.Sprintf("10.0.1.%d", i)
+			assert.NoError(t, s.Block(ctx, store.Entry{IP: ip, CreatedAt: now()}))
+			_, err := s.Get(ctx, ip)
+			assert.NoError(t, err)
+			_, err = s.List(ctx)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 20)
+}
+
+func testAllowlist(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	entries, err := s.ListAllowed(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	want := []store.AllowEntry{
+		{Value: "10.0.0.0/8", Kind: "cidr", Reason: "internal", CreatedAt: now()},
+		{Value: "192.168.1.1", Kind: "ip", CreatedAt: now()},
+		{Value: "example.com", Kind: "domain", CreatedAt: now()},
+	}
+	for _, e := range want {
+		require.NoError(t, s.Allow(ctx, e))
+	}
+	replaced := want[1]
+	replaced.Reason = "resolver"
+	require.NoError(t, s.Allow(ctx, replaced))
+	want[1] = replaced
+
+	entries, err = s.ListAllowed(ctx)
+	require.NoError(t, err)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Value < entries[j].Value })
+	assert.Equal(t, want, entries)
+
+	found, err := s.Disallow(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.Disallow(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, found)
+	entries, err = s.ListAllowed(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	_, err = s.Get(ctx, "192.168.1.1")
+	assert.ErrorIs(t, err, store.ErrNotFound, "the allowlist is separate from the blacklist")
+}
+
+func testBlockRules(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	rules, err := s.ListBlockRules(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	want := []store.BlockRule{
+		{ID: "a", Source: "10.0.0.1", DomainPattern: "*.example.com", Reason: "c2", CreatedAt: now(), ExpiresAt: now().Add(time.Hour)},
+		{ID: "b", Source: "10.0.0.0/8", QueryType: 16, CreatedAt: now()},
+	}
+	for _, r := range want {
+		require.NoError(t, s.AddBlockRule(ctx, r))
+	}
+	replaced := want[1]
+	replaced.Reason = "txt exfiltration"
+	require.NoError(t, s.AddBlockRule(ctx, replaced))
+	want[1] = replaced
+	require.NoError(t, s.AddBlockRule(ctx, store.BlockRule{ID: "expired", DomainPattern: "example.org", CreatedAt: now().Add(-time.Hour), ExpiresAt: now().Add(-time.Second)}))
+
+	rules, err = s.ListBlockRules(ctx)
+	require.NoError(t, err)
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	require.Len(t, rules, 2)
+	for i := range want {
+		assert.Equal(t, want[i].ID, rules[i].ID)
+		assert.Equal(t, want[i].Source, rules[i].Source)
+		assert.Equal(t, want[i].DomainPattern, rules[i].DomainPattern)
+		assert.Equal(t, want[i].QueryType, rules[i].QueryType)
+		assert.Equal(t, want[i].Reason, rules[i].Reason)
+		assert.True(t, want[i].ExpiresAt.Equal(rules[i].ExpiresAt), "expires_at %v, want %v", rules[i].ExpiresAt, want[i].ExpiresAt)
+	}
+
+	found, err := s.RemoveBlockRule(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.RemoveBlockRule(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = s.RemoveBlockRule(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, found)
+	rules, err = s.ListBlockRules(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+}
+
+func testTenants(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	payments, err := s.Tenant("payments")
+	require.NoError(t, err)
+	retail, err := s.Tenant("retail")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Block(ctx, store.Entry{IP: "10.0.0.1", CreatedAt: now()}))
+	require.NoError(t, payments.Block(ctx, store.Entry{IP: "10.0.0.2", Reason: "payments", CreatedAt: now()}))
+	require.NoError(t, payments.Allow(ctx, store.AllowEntry{Value: "example.com", Kind: "domain", CreatedAt: now()}))
+
+	_, err = s.Get(ctx, "10.0.0.2")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = retail.Get(ctx, "10.0.0.2")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = payments.Get(ctx, "10.0.0.1")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	entries, err := payments.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "payments", entries[0].Reason)
+	entries, err = s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	allowed, err := s.ListAllowed(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, allowed)
+
+	// The store of a tenant is the same every time, and closing it leaves
+	// the parent store open
+	again, err := s.Tenant("payments")
+	require.NoError(t, err)
+	_, err = again.Get(ctx, "10.0.0.2")
+	assert.NoError(t, err)
+	require.NoError(t, again.Close())
+	_, err = s.Get(ctx, "10.0.0.1")
+	assert.NoError(t, err)
+	found, err := payments.Unblock(ctx, "10.0.0.2")
+	require.NoError(t, err)
+	assert.True(t, found)
+}
@@ -0,0 +1,251 @@
+// Package tenant identifies the business unit, or tenant, a request belongs
+// to and holds the per-tenant settings: the tokens authenticating it, the
+// topic its DNS requests are published to and its detector settings.
+//
+// Every tenant has its own blacklist and allowlist. Requests without a tenant
+// belong to Default, which keeps the keys and topic of single-tenant
+// deployments.
+package tenant
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default is the tenant of requests that do not name one.
+const Default = "default"
+
+// MetadataKey is the gRPC metadata key naming the tenant of a request, and
+// HeaderKey the event bus header naming the tenant of an event.
+const (
+	MetadataKey = "x-tenant-id"
+	HeaderKey   = "tenant"
+)
+
+// Errors returned by Resolve.
+var (
+	ErrUnknownToken  = errors.New("tenant: unknown token")
+	ErrTokenRequired = errors.New("tenant: token required")
+	ErrForbidden     = errors.New("tenant: token does not belong to the tenant")
+	ErrUnknownTenant = errors.New("tenant: unknown tenant")
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Validate returns an error if id is not a valid tenant ID: 1 to 63
+// lowercase letters, digits, hyphens and underscores, starting with a letter
+// or a digit. IDs are used in Redis keys and topic names.
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("invalid tenant ID %q", id)
+	}
+	return nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the tenant id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant carried by ctx, Default if there is none.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(contextKey{}).(string); ok {
+		return id
+	}
+	return Default
+}
+
+// Detector holds the settings of the consumer detectors for a tenant.
+type Detector struct {
+	// MaliciousSuffixes are the suffixes of the source IPs blocked by the
+	// consumer, ["70"] when not set.
+	MaliciousSuffixes []string `yaml:"malicious_suffixes"`
+	// BlockTTL is the duration of the blocks, 0 blocks until unblocked.
+	BlockTTL time.Duration `yaml:"block_ttl"`
+	// BlockReason is recorded with the blocks.
+	BlockReason string `yaml:"block_reason"`
+}
+
+// DefaultDetector is the detector of tenants without detector settings.
+var DefaultDetector = Detector{MaliciousSuffixes: []string{"70"}}
+
+// Tenant holds the settings of a tenant.
+type Tenant struct {
+	ID string `yaml:"id"`
+	// Tokens authenticate the requests of the tenant, sent as
+	// "authorization: Bearer <token>". A tenant with tokens cannot be
+	// selected by MetadataKey alone.
+	Tokens []string `yaml:"tokens"`
+	// Topic receives the DNS requests of the tenant, the shared topic when
+	// empty.
+	Topic    string   `yaml:"topic"`
+	Detector Detector `yaml:"detector"`
+}
+
+// Config is the set of declared tenants. A nil Config only knows Default,
+// without tokens, topic or detector settings.
+type Config struct {
+	tenants map[string]Tenant
+	// order is the declaration order of the tenants.
+	order []string
+}
+
+// Load reads the tenants of the YAML file at path:
+//
+//	tenants:
+//	  - id: payments
+//	    tokens: [secret]
+//	    topic: myTopic.payments
+//	    detector:
+//	      malicious_suffixes: ["66", "70"]
+//	      block_ttl: 1h
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Tenants []Tenant `yaml:"tenants"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	c, err := New(file.Tenants)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// New returns the config of tenants.
+func New(tenants []Tenant) (*Config, error) {
+	c := &Config{tenants: make(map[string]Tenant)}
+	owners := make(map[string]string)
+	for _, t := range tenants {
+		if err := Validate(t.ID); err != nil {
+			return nil, err
+		}
+		if _, ok := c.tenants[t.ID]; ok {
+			return nil, fmt.Errorf("tenant %s declared twice", t.ID)
+		}
+		for _, token := range t.Tokens {
+			if token == "" {
+				return nil, fmt.Errorf("tenant %s has an empty token", t.ID)
+			}
+			if owner, ok := owners[token]; ok {
+				return nil, fmt.Errorf("tenants %s and %s share a token", owner, t.ID)
+			}
+			owners[token] = t.ID
+		}
+		if t.Detector.MaliciousSuffixes == nil {
+			t.Detector.MaliciousSuffixes = DefaultDetector.MaliciousSuffixes
+		}
+		c.tenants[t.ID] = t
+		c.order = append(c.order, t.ID)
+	}
+	return c, nil
+}
+
+// Lookup returns the settings of the tenant id.
+func (c *Config) Lookup(id string) (Tenant, bool) {
+	if c != nil {
+		if t, ok := c.tenants[id]; ok {
+			return t, true
+		}
+	}
+	if id == Default {
+		return Tenant{ID: Default, Detector: DefaultDetector}, true
+	}
+	return Tenant{}, false
+}
+
+// IDs returns the IDs of the declared tenants, in declaration order, and
+// Default.
+func (c *Config) IDs() []string {
+	ids := []string{Default}
+	if c == nil {
+		return ids
+	}
+	for _, id := range c.order {
+		if id != Default {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Topic returns the topic of the DNS requests of the tenant id, fallback if
+// it has none.
+func (c *Config) Topic(id, fallback string) string {
+	if t, ok := c.Lookup(id); ok && t.Topic != "" {
+		return t.Topic
+	}
+	return fallback
+}
+
+// Detector returns the detector settings of the tenant id, DefaultDetector
+// for unknown tenants.
+func (c *Config) Detector(id string) Detector {
+	if t, ok := c.Lookup(id); ok {
+		return t.Detector
+	}
+	return DefaultDetector
+}
+
+// Resolve returns the tenant of a request naming the tenant id, "" if it
+// does not name one, and authenticated by token, "" if it has none.
+//
+// A token selects its tenant, and the named tenant, if any, must be that one.
+// Without a token the named tenant must be declared without tokens.
+func (c *Config) Resolve(id, token string) (string, error) {
+	if token != "" {
+		owner, ok := c.owner(token)
+		if !ok {
+			return "", ErrUnknownToken
+		}
+		if id != "" && id != owner {
+			return "", ErrForbidden
+		}
+		return owner, nil
+	}
+	if id == "" {
+		id = Default
+	}
+	if err := Validate(id); err != nil {
+		return "", err
+	}
+	t, ok := c.Lookup(id)
+	if !ok {
+		return "", ErrUnknownTenant
+	}
+	if len(t.Tokens) > 0 {
+		return "", ErrTokenRequired
+	}
+	return id, nil
+}
+
+// owner returns the tenant of token, comparing it in constant time with every
+// token.
+func (c *Config) owner(token string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	owner, found := "", false
+	for _, id := range c.order {
+		for _, t := range c.tenants[id].Tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				owner, found = id, true
+			}
+		}
+	}
+	return owner, found
+}
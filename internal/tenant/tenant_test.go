@@ -0,0 +1,95 @@
+package tenant
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tenants:
+  - id: payments
+    tokens: [secret]
+    topic: myTopic.payments
+    detector:
+      malicious_suffixes: ["66"]
+      block_ttl: 1h
+      block_reason: payments detector
+  - id: retail
+`), 0o600))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{Default, "payments", "retail"}, c.IDs())
+	assert.Equal(t, "myTopic.payments", c.Topic("payments", "myTopic"))
+	assert.Equal(t, "myTopic", c.Topic("retail", "myTopic"))
+	assert.Equal(t, "myTopic", c.Topic(Default, "myTopic"))
+	assert.Equal(t, Detector{MaliciousSuffixes: []string{"66"}, BlockTTL: time.Hour, BlockReason: "payments detector"}, c.Detector("payments"))
+	assert.Equal(t, DefaultDetector, c.Detector("retail"))
+	assert.Equal(t, DefaultDetector, c.Detector("unknown"))
+
+	for _, tenants := range [][]Tenant{
+		{{ID: "Payments"}},
+		{{ID: "a"}, {ID: "a"}},
+		{{ID: "a", Tokens: []string{"t"}}, {ID: "b", Tokens: []string{"t"}}},
+		{{ID: "a", Tokens: []string{""}}},
+	} {
+		_, err := New(tenants)
+		assert.Error(t, err, tenants)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	c, err := New([]Tenant{
+		{ID: "payments", Tokens: []string{"payments-token"}},
+		{ID: "retail"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		id, token string
+		want      string
+		err       error
+	}{
+		{"", "", Default, nil},
+		{"retail", "", "retail", nil},
+		{"", "payments-token", "payments", nil},
+		{"payments", "payments-token", "payments", nil},
+		{"payments", "", "", ErrTokenRequired},
+		{"retail", "payments-token", "", ErrForbidden},
+		{"", "wrong", "", ErrUnknownToken},
+		{"unknown", "", "", ErrUnknownTenant},
+	}
+	for _, tt := range tests {
+		got, err := c.Resolve(tt.id, tt.token)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, "%+v", tt)
+			continue
+		}
+		if assert.NoError(t, err, "%+v", tt) {
+			assert.Equal(t, tt.want, got, "%+v", tt)
+		}
+	}
+	_, err = c.Resolve("Bad Tenant", "")
+	assert.Error(t, err)
+
+	// Without a config, only the default tenant exists
+	var none *Config
+	got, err := none.Resolve("", "")
+	require.NoError(t, err)
+	assert.Equal(t, Default, got)
+	_, err = none.Resolve("retail", "")
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, Default, FromContext(context.Background()))
+	assert.Equal(t, "payments", FromContext(NewContext(context.Background(), "payments")))
+}
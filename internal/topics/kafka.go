@@ -0,0 +1,125 @@
+package topics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// KafkaAdmin is the Admin of a Kafka cluster.
+type KafkaAdmin struct {
+	client *kafka.AdminClient
+}
+
+// NewKafkaAdmin returns an Admin connected to the given brokers.
+func NewKafkaAdmin(bootstrapServers string) (*KafkaAdmin, error) {
+	client, err := kafka.NewAdminClient(&kafka.ConfigMap{"bootstrap.servers": bootstrapServers})
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaAdmin{client: client}, nil
+}
+
+func (a *KafkaAdmin) Close() {
+	a.client.Close()
+}
+
+func (a *KafkaAdmin) BrokerCount(ctx context.Context) (int, error) {
+	cluster, err := a.client.DescribeCluster(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(cluster.Nodes), nil
+}
+
+// Describe uses DescribeTopics rather than a metadata request, which could
+// auto-create the topic on brokers with auto.create.topics.enable.
+func (a *KafkaAdmin) Describe(ctx context.Context, topic string) (State, bool, error) {
+	result, err := a.client.DescribeTopics(ctx, kafka.NewTopicCollectionOfTopicNames([]string{topic}))
+	if err != nil {
+		return State{}, false, err
+	}
+	if len(result.TopicDescriptions) != 1 {
+		return State{}, false, fmt.Errorf("expected one topic description, got %d", len(result.TopicDescriptions))
+	}
+	desc := result.TopicDescriptions[0]
+	switch desc.Error.Code() {
+	case kafka.ErrNoError:
+	case kafka.ErrUnknownTopicOrPart, kafka.ErrUnknownTopic:
+		return State{}, false, nil
+	default:
+		return State{}, false, desc.Error
+	}
+
+	state := State{Partitions: len(desc.Partitions), Config: make(map[string]string)}
+	if len(desc.Partitions) > 0 {
+		state.ReplicationFactor = len(desc.Partitions[0].Replicas)
+	}
+	configs, err := a.client.DescribeConfigs(ctx, []kafka.ConfigResource{{Type: kafka.ResourceTopic, Name: topic}})
+	if err != nil {
+		return State{}, false, err
+	}
+	for _, res := range configs {
+		if res.Error.Code() != kafka.ErrNoError {
+			return State{}, false, res.Error
+		}
+		for name, entry := range res.Config {
+			state.Config[name] = entry.Value
+		}
+	}
+	return state, true, nil
+}
+
+func (a *KafkaAdmin) Create(ctx context.Context, spec Spec) error {
+	results, err := a.client.CreateTopics(ctx, []kafka.TopicSpecification{{
+		Topic:             spec.Name,
+		NumPartitions:     spec.Partitions,
+		ReplicationFactor: spec.ReplicationFactor,
+		Config:            spec.Config,
+	}})
+	if err != nil {
+		return err
+	}
+	return topicResultsError(results)
+}
+
+func (a *KafkaAdmin) AddPartitions(ctx context.Context, topic string, total int) error {
+	results, err := a.client.CreatePartitions(ctx, []kafka.PartitionsSpecification{{Topic: topic, IncreaseTo: total}})
+	if err != nil {
+		return err
+	}
+	return topicResultsError(results)
+}
+
+// SetConfig uses an incremental alter so that the properties not in config
+// keep their value.
+func (a *KafkaAdmin) SetConfig(ctx context.Context, topic string, config map[string]string) error {
+	var entries []kafka.ConfigEntry
+	for name, value := range config {
+		entries = append(entries, kafka.ConfigEntry{Name: name, Value: value, IncrementalOperation: kafka.AlterConfigOpTypeSet})
+	}
+	results, err := a.client.IncrementalAlterConfigs(ctx, []kafka.ConfigResource{{
+		Type:   kafka.ResourceTopic,
+		Name:   topic,
+		Config: entries,
+	}})
+	if err != nil {
+		return err
+	}
+	for _, res := range results {
+		if res.Error.Code() != kafka.ErrNoError {
+			return res.Error
+		}
+	}
+	return nil
+}
+
+func topicResultsError(results []kafka.TopicResult) error {
+	for _, result := range results {
+		if result.Error.Code() != kafka.ErrNoError {
+			return result.Error
+		}
+	}
+	return nil
+}
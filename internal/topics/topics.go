@@ -0,0 +1,188 @@
+// Package topics provisions the Kafka topics from declarative specs: missing
+// topics are created, partitions are added and configuration drift is
+// corrected on existing topics, and every change is reported.
+package topics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the desired state of a topic.
+type Spec struct {
+	Name       string `yaml:"name"`
+	Partitions int    `yaml:"partitions"`
+	// ReplicationFactor is capped at the number of live brokers.
+	ReplicationFactor int `yaml:"replication_factor"`
+	// Config holds topic configuration properties such as retention.ms,
+	// cleanup.policy or compression.type. Properties not listed are left
+	// untouched.
+	Config map[string]string `yaml:"config"`
+}
+
+// State is the current state of a topic.
+type State struct {
+	Partitions        int
+	ReplicationFactor int
+	Config            map[string]string
+}
+
+// Admin is the broker administration used by Provision.
+type Admin interface {
+	// BrokerCount returns the number of live brokers.
+	BrokerCount(ctx context.Context) (int, error)
+	// Describe returns the state of topic, and false if it does not exist.
+	Describe(ctx context.Context, topic string) (State, bool, error)
+	Create(ctx context.Context, spec Spec) error
+	// AddPartitions increases the number of partitions of topic to total.
+	AddPartitions(ctx context.Context, topic string, total int) error
+	// SetConfig sets the given configuration properties of topic.
+	SetConfig(ctx context.Context, topic string, config map[string]string) error
+}
+
+// Change describes something Provision changed, or could not change.
+type Change struct {
+	Topic  string
+	Action string
+	Detail string
+}
+
+// Change actions.
+const (
+	ActionCreate           = "create"
+	ActionAddPartitions    = "add_partitions"
+	ActionAlterConfig      = "alter_config"
+	ActionCapReplication   = "cap_replication"
+	ActionUnreconciledDiff = "unreconciled"
+)
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s: %s", c.Topic, c.Action, c.Detail)
+}
+
+// Provision brings the topics to the state of specs and returns the changes.
+// Changes Kafka cannot apply in place, fewer partitions or a different
+// replication factor on an existing topic, are reported with the
+// ActionUnreconciledDiff action and left as they are.
+func Provision(ctx context.Context, admin Admin, specs []Spec) ([]Change, error) {
+	brokers, err := admin.BrokerCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count brokers: %w", err)
+	}
+	if brokers < 1 {
+		return nil, fmt.Errorf("no live broker")
+	}
+
+	var changes []Change
+	for _, spec := range specs {
+		if spec.ReplicationFactor > brokers {
+			changes = append(changes, Change{spec.Name, ActionCapReplication,
+				fmt.Sprintf("replication factor %d capped to %d live brokers", spec.ReplicationFactor, brokers)})
+			spec.ReplicationFactor = brokers
+		}
+
+		state, exists, err := admin.Describe(ctx, spec.Name)
+		if err != nil {
+			return changes, fmt.Errorf("failed to describe topic %s: %w", spec.Name, err)
+		}
+		if !exists {
+			if err := admin.Create(ctx, spec); err != nil {
+				return changes, fmt.Errorf("failed to create topic %s: %w", spec.Name, err)
+			}
+			changes = append(changes, Change{spec.Name, ActionCreate,
+				fmt.Sprintf("%d partitions, replication factor %d", spec.Partitions, spec.ReplicationFactor)})
+			continue
+		}
+
+		switch {
+		case spec.Partitions > state.Partitions:
+			if err := admin.AddPartitions(ctx, spec.Name, spec.Partitions); err != nil {
+				return changes, fmt.Errorf("failed to add partitions to topic %s: %w", spec.Name, err)
+			}
+			changes = append(changes, Change{spec.Name, ActionAddPartitions,
+				fmt.Sprintf("%d -> %d partitions", state.Partitions, spec.Partitions)})
+		case spec.Partitions < state.Partitions:
+			changes = append(changes, Change{spec.Name, ActionUnreconciledDiff,
+				fmt.Sprintf("has %d partitions, %d wanted, partitions cannot be removed", state.Partitions, spec.Partitions)})
+		}
+		if spec.ReplicationFactor != state.ReplicationFactor {
+			changes = append(changes, Change{spec.Name, ActionUnreconciledDiff,
+				fmt.Sprintf("has replication factor %d, %d wanted, reassign the partitions to change it", state.ReplicationFactor, spec.ReplicationFactor)})
+		}
+
+		drift := configDrift(spec.Config, state.Config)
+		if len(drift) > 0 {
+			var altered []Change
+			for _, name := range sortedKeys(drift) {
+				altered = append(altered, Change{spec.Name, ActionAlterConfig,
+					fmt.Sprintf("%s: %q -> %q", name, state.Config[name], drift[name])})
+			}
+			if err := admin.SetConfig(ctx, spec.Name, drift); err != nil {
+				return changes, fmt.Errorf("failed to alter config of topic %s: %w", spec.Name, err)
+			}
+			changes = append(changes, altered...)
+		}
+	}
+	return changes, nil
+}
+
+// configDrift returns the properties of want whose value differs in have.
+func configDrift(want, have map[string]string) map[string]string {
+	drift := make(map[string]string)
+	for name, value := range want {
+		if current, ok := have[name]; !ok || current != value {
+			drift[name] = value
+		}
+	}
+	return drift
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LoadSpecs reads topic specs from a YAML file of the form:
+//
+//	topics:
+//	  - name: myTopic
+//	    partitions: 3
+//	    replication_factor: 2
+//	    config:
+//	      retention.ms: "604800000"
+//	      cleanup.policy: delete
+func LoadSpecs(path string) ([]Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Topics []Spec `yaml:"topics"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	seen := make(map[string]bool)
+	for _, spec := range file.Topics {
+		switch {
+		case spec.Name == "":
+			return nil, fmt.Errorf("%s: topic without a name", path)
+		case seen[spec.Name]:
+			return nil, fmt.Errorf("%s: topic %s declared twice", path, spec.Name)
+		case spec.Partitions < 1:
+			return nil, fmt.Errorf("%s: topic %s needs at least one partition", path, spec.Name)
+		case spec.ReplicationFactor < 1:
+			return nil, fmt.Errorf("%s: topic %s needs a replication factor of at least one", path, spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+	return file.Topics, nil
+}
@@ -0,0 +1,134 @@
+package topics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdmin is an in-memory cluster of brokers brokers.
+type fakeAdmin struct {
+	brokers int
+	topics  map[string]State
+}
+
+func (f *fakeAdmin) BrokerCount(ctx context.Context) (int, error) {
+	return f.brokers, nil
+}
+
+func (f *fakeAdmin) Describe(ctx context.Context, topic string) (State, bool, error) {
+	state, ok := f.topics[topic]
+	return state, ok, nil
+}
+
+func (f *fakeAdmin) Create(ctx context.Context, spec Spec) error {
+	config := make(map[string]string)
+	for k, v := range spec.Config {
+		config[k] = v
+	}
+	f.topics[spec.Name] = State{Partitions: spec.Partitions, ReplicationFactor: spec.ReplicationFactor, Config: config}
+	return nil
+}
+
+func (f *fakeAdmin) AddPartitions(ctx context.Context, topic string, total int) error {
+	state := f.topics[topic]
+	state.Partitions = total
+	f.topics[topic] = state
+	return nil
+}
+
+func (f *fakeAdmin) SetConfig(ctx context.Context, topic string, config map[string]string) error {
+	for k, v := range config {
+		f.topics[topic].Config[k] = v
+	}
+	return nil
+}
+
+func actions(changes []Change) []string {
+	var out []string
+	for _, c := range changes {
+		out = append(out, c.Topic+" "+c.Action)
+	}
+	return out
+}
+
+func TestProvisionCreatesAndCapsReplication(t *testing.T) {
+	admin := &fakeAdmin{brokers: 1, topics: map[string]State{}}
+	specs := []Spec{{Name: "myTopic", Partitions: 3, ReplicationFactor: 2, Config: map[string]string{"retention.ms": "3600000"}}}
+
+	changes, err := Provision(context.Background(), admin, specs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myTopic cap_replication", "myTopic create"}, actions(changes))
+	assert.Equal(t, State{Partitions: 3, ReplicationFactor: 1, Config: map[string]string{"retention.ms": "3600000"}}, admin.topics["myTopic"])
+
+	// Provisioning again is a no-op apart from the capping notice
+	changes, err = Provision(context.Background(), admin, specs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myTopic cap_replication"}, actions(changes))
+}
+
+func TestProvisionReconcilesExistingTopic(t *testing.T) {
+	admin := &fakeAdmin{brokers: 3, topics: map[string]State{
+		"myTopic": {Partitions: 3, ReplicationFactor: 3, Config: map[string]string{
+			"retention.ms":     "604800000",
+			"cleanup.policy":   "delete",
+			"compression.type": "producer",
+		}},
+		"dlq": {Partitions: 6, ReplicationFactor: 1, Config: map[string]string{}},
+	}}
+	specs := []Spec{
+		{Name: "myTopic", Partitions: 6, ReplicationFactor: 3, Config: map[string]string{
+			"retention.ms":     "86400000",
+			"cleanup.policy":   "delete",
+			"compression.type": "zstd",
+		}},
+		{Name: "dlq", Partitions: 3, ReplicationFactor: 3},
+	}
+
+	changes, err := Provision(context.Background(), admin, specs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"myTopic add_partitions",
+		"myTopic alter_config",
+		"myTopic alter_config",
+		"dlq unreconciled",
+		"dlq unreconciled",
+	}, actions(changes))
+	assert.Equal(t, `myTopic: alter_config: compression.type: "producer" -> "zstd"`, changes[1].String())
+
+	state := admin.topics["myTopic"]
+	assert.Equal(t, 6, state.Partitions)
+	assert.Equal(t, "86400000", state.Config["retention.ms"])
+	assert.Equal(t, "zstd", state.Config["compression.type"])
+	assert.Equal(t, 6, admin.topics["dlq"].Partitions, "partitions are never removed")
+}
+
+func TestLoadSpecs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "topics.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+topics:
+  - name: myTopic
+    partitions: 3
+    replication_factor: 2
+    config:
+      retention.ms: "604800000"
+      cleanup.policy: compact
+`), 0o600))
+	specs, err := LoadSpecs(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Spec{{
+		Name:              "myTopic",
+		Partitions:        3,
+		ReplicationFactor: 2,
+		Config:            map[string]string{"retention.ms": "604800000", "cleanup.policy": "compact"},
+	}}, specs)
+
+	require.NoError(t, os.WriteFile(path, []byte("topics:\n  - name: myTopic\n    replication_factor: 1\n"), 0o600))
+	_, err = LoadSpecs(path)
+	assert.ErrorContains(t, err, "at least one partition")
+}
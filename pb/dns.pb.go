@@ -20,15 +20,683 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// DNS response codes (RFC 1035 and the IANA "DNS RCODEs" registry).
+type ResponseCode int32
+
+const (
+	ResponseCode_RESPONSE_CODE_NOERROR   ResponseCode = 0
+	ResponseCode_RESPONSE_CODE_FORMERR   ResponseCode = 1
+	ResponseCode_RESPONSE_CODE_SERVFAIL  ResponseCode = 2
+	ResponseCode_RESPONSE_CODE_NXDOMAIN  ResponseCode = 3
+	ResponseCode_RESPONSE_CODE_NOTIMP    ResponseCode = 4
+	ResponseCode_RESPONSE_CODE_REFUSED   ResponseCode = 5
+	ResponseCode_RESPONSE_CODE_YXDOMAIN  ResponseCode = 6
+	ResponseCode_RESPONSE_CODE_YXRRSET   ResponseCode = 7
+	ResponseCode_RESPONSE_CODE_NXRRSET   ResponseCode = 8
+	ResponseCode_RESPONSE_CODE_NOTAUTH   ResponseCode = 9
+	ResponseCode_RESPONSE_CODE_NOTZONE   ResponseCode = 10
+	ResponseCode_RESPONSE_CODE_DSOTYPENI ResponseCode = 11
+	ResponseCode_RESPONSE_CODE_BADVERS   ResponseCode = 16
+	ResponseCode_RESPONSE_CODE_BADKEY    ResponseCode = 17
+	ResponseCode_RESPONSE_CODE_BADTIME   ResponseCode = 18
+	ResponseCode_RESPONSE_CODE_BADMODE   ResponseCode = 19
+	ResponseCode_RESPONSE_CODE_BADNAME   ResponseCode = 20
+	ResponseCode_RESPONSE_CODE_BADALG    ResponseCode = 21
+	ResponseCode_RESPONSE_CODE_BADTRUNC  ResponseCode = 22
+	ResponseCode_RESPONSE_CODE_BADCOOKIE ResponseCode = 23
+)
+
+// Enum value maps for ResponseCode.
+var (
+	ResponseCode_name = map[int32]string{
+		0:  "RESPONSE_CODE_NOERROR",
+		1:  "RESPONSE_CODE_FORMERR",
+		2:  "RESPONSE_CODE_SERVFAIL",
+		3:  "RESPONSE_CODE_NXDOMAIN",
+		4:  "RESPONSE_CODE_NOTIMP",
+		5:  "RESPONSE_CODE_REFUSED",
+		6:  "RESPONSE_CODE_YXDOMAIN",
+		7:  "RESPONSE_CODE_YXRRSET",
+		8:  "RESPONSE_CODE_NXRRSET",
+		9:  "RESPONSE_CODE_NOTAUTH",
+		10: "RESPONSE_CODE_NOTZONE",
+		11: "RESPONSE_CODE_DSOTYPENI",
+		16: "RESPONSE_CODE_BADVERS",
+		17: "RESPONSE_CODE_BADKEY",
+		18: "RESPONSE_CODE_BADTIME",
+		19: "RESPONSE_CODE_BADMODE",
+		20: "RESPONSE_CODE_BADNAME",
+		21: "RESPONSE_CODE_BADALG",
+		22: "RESPONSE_CODE_BADTRUNC",
+		23: "RESPONSE_CODE_BADCOOKIE",
+	}
+	ResponseCode_value = map[string]int32{
+		"RESPONSE_CODE_NOERROR":   0,
+		"RESPONSE_CODE_FORMERR":   1,
+		"RESPONSE_CODE_SERVFAIL":  2,
+		"RESPONSE_CODE_NXDOMAIN":  3,
+		"RESPONSE_CODE_NOTIMP":    4,
+		"RESPONSE_CODE_REFUSED":   5,
+		"RESPONSE_CODE_YXDOMAIN":  6,
+		"RESPONSE_CODE_YXRRSET":   7,
+		"RESPONSE_CODE_NXRRSET":   8,
+		"RESPONSE_CODE_NOTAUTH":   9,
+		"RESPONSE_CODE_NOTZONE":   10,
+		"RESPONSE_CODE_DSOTYPENI": 11,
+		"RESPONSE_CODE_BADVERS":   16,
+		"RESPONSE_CODE_BADKEY":    17,
+		"RESPONSE_CODE_BADTIME":   18,
+		"RESPONSE_CODE_BADMODE":   19,
+		"RESPONSE_CODE_BADNAME":   20,
+		"RESPONSE_CODE_BADALG":    21,
+		"RESPONSE_CODE_BADTRUNC":  22,
+		"RESPONSE_CODE_BADCOOKIE": 23,
+	}
+)
+
+func (x ResponseCode) Enum() *ResponseCode {
+	p := new(ResponseCode)
+	*p = x
+	return p
+}
+
+func (x ResponseCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResponseCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_dns_proto_enumTypes[0].Descriptor()
+}
+
+func (ResponseCode) Type() protoreflect.EnumType {
+	return &file_dns_proto_enumTypes[0]
+}
+
+func (x ResponseCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResponseCode.Descriptor instead.
+func (ResponseCode) EnumDescriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{0}
+}
+
+// Transport protocol of the query.
+type Transport int32
+
+const (
+	Transport_TRANSPORT_UNKNOWN Transport = 0
+	Transport_TRANSPORT_UDP     Transport = 1
+	Transport_TRANSPORT_TCP     Transport = 2
+	// DNS over TLS (RFC 7858).
+	Transport_TRANSPORT_DOT Transport = 3
+	// DNS over HTTPS (RFC 8484).
+	Transport_TRANSPORT_DOH Transport = 4
+	// DNS over QUIC (RFC 9250).
+	Transport_TRANSPORT_DOQ Transport = 5
+)
+
+// Enum value maps for Transport.
+var (
+	Transport_name = map[int32]string{
+		0: "TRANSPORT_UNKNOWN",
+		1: "TRANSPORT_UDP",
+		2: "TRANSPORT_TCP",
+		3: "TRANSPORT_DOT",
+		4: "TRANSPORT_DOH",
+		5: "TRANSPORT_DOQ",
+	}
+	Transport_value = map[string]int32{
+		"TRANSPORT_UNKNOWN": 0,
+		"TRANSPORT_UDP":     1,
+		"TRANSPORT_TCP":     2,
+		"TRANSPORT_DOT":     3,
+		"TRANSPORT_DOH":     4,
+		"TRANSPORT_DOQ":     5,
+	}
+)
+
+func (x Transport) Enum() *Transport {
+	p := new(Transport)
+	*p = x
+	return p
+}
+
+func (x Transport) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Transport) Descriptor() protoreflect.EnumDescriptor {
+	return file_dns_proto_enumTypes[1].Descriptor()
+}
+
+func (Transport) Type() protoreflect.EnumType {
+	return &file_dns_proto_enumTypes[1]
+}
+
+func (x Transport) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Transport.Descriptor instead.
+func (Transport) EnumDescriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{1}
+}
+
+// DNS resource record types, numbered as in the IANA "Resource Record (RR)
+// TYPEs" registry. Types missing from this list are carried by their number,
+// which proto3 preserves as an unrecognized enum value.
+type QueryType int32
+
+const (
+	QueryType_QUERY_TYPE_UNKNOWN    QueryType = 0
+	QueryType_QUERY_TYPE_A          QueryType = 1
+	QueryType_QUERY_TYPE_NS         QueryType = 2
+	QueryType_QUERY_TYPE_MD         QueryType = 3
+	QueryType_QUERY_TYPE_MF         QueryType = 4
+	QueryType_QUERY_TYPE_CNAME      QueryType = 5
+	QueryType_QUERY_TYPE_SOA        QueryType = 6
+	QueryType_QUERY_TYPE_MB         QueryType = 7
+	QueryType_QUERY_TYPE_MG         QueryType = 8
+	QueryType_QUERY_TYPE_MR         QueryType = 9
+	QueryType_QUERY_TYPE_NULL       QueryType = 10
+	QueryType_QUERY_TYPE_WKS        QueryType = 11
+	QueryType_QUERY_TYPE_PTR        QueryType = 12
+	QueryType_QUERY_TYPE_HINFO      QueryType = 13
+	QueryType_QUERY_TYPE_MINFO      QueryType = 14
+	QueryType_QUERY_TYPE_MX         QueryType = 15
+	QueryType_QUERY_TYPE_TXT        QueryType = 16
+	QueryType_QUERY_TYPE_RP         QueryType = 17
+	QueryType_QUERY_TYPE_AFSDB      QueryType = 18
+	QueryType_QUERY_TYPE_X25        QueryType = 19
+	QueryType_QUERY_TYPE_ISDN       QueryType = 20
+	QueryType_QUERY_TYPE_RT         QueryType = 21
+	QueryType_QUERY_TYPE_NSAP       QueryType = 22
+	QueryType_QUERY_TYPE_NSAP_PTR   QueryType = 23
+	QueryType_QUERY_TYPE_SIG        QueryType = 24
+	QueryType_QUERY_TYPE_KEY        QueryType = 25
+	QueryType_QUERY_TYPE_PX         QueryType = 26
+	QueryType_QUERY_TYPE_GPOS       QueryType = 27
+	QueryType_QUERY_TYPE_AAAA       QueryType = 28
+	QueryType_QUERY_TYPE_LOC        QueryType = 29
+	QueryType_QUERY_TYPE_NXT        QueryType = 30
+	QueryType_QUERY_TYPE_EID        QueryType = 31
+	QueryType_QUERY_TYPE_NIMLOC     QueryType = 32
+	QueryType_QUERY_TYPE_SRV        QueryType = 33
+	QueryType_QUERY_TYPE_ATMA       QueryType = 34
+	QueryType_QUERY_TYPE_NAPTR      QueryType = 35
+	QueryType_QUERY_TYPE_KX         QueryType = 36
+	QueryType_QUERY_TYPE_CERT       QueryType = 37
+	QueryType_QUERY_TYPE_A6         QueryType = 38
+	QueryType_QUERY_TYPE_DNAME      QueryType = 39
+	QueryType_QUERY_TYPE_SINK       QueryType = 40
+	QueryType_QUERY_TYPE_OPT        QueryType = 41
+	QueryType_QUERY_TYPE_APL        QueryType = 42
+	QueryType_QUERY_TYPE_DS         QueryType = 43
+	QueryType_QUERY_TYPE_SSHFP      QueryType = 44
+	QueryType_QUERY_TYPE_IPSECKEY   QueryType = 45
+	QueryType_QUERY_TYPE_RRSIG      QueryType = 46
+	QueryType_QUERY_TYPE_NSEC       QueryType = 47
+	QueryType_QUERY_TYPE_DNSKEY     QueryType = 48
+	QueryType_QUERY_TYPE_DHCID      QueryType = 49
+	QueryType_QUERY_TYPE_NSEC3      QueryType = 50
+	QueryType_QUERY_TYPE_NSEC3PARAM QueryType = 51
+	QueryType_QUERY_TYPE_TLSA       QueryType = 52
+	QueryType_QUERY_TYPE_SMIMEA     QueryType = 53
+	QueryType_QUERY_TYPE_HIP        QueryType = 55
+	QueryType_QUERY_TYPE_NINFO      QueryType = 56
+	QueryType_QUERY_TYPE_RKEY       QueryType = 57
+	QueryType_QUERY_TYPE_TALINK     QueryType = 58
+	QueryType_QUERY_TYPE_CDS        QueryType = 59
+	QueryType_QUERY_TYPE_CDNSKEY    QueryType = 60
+	QueryType_QUERY_TYPE_OPENPGPKEY QueryType = 61
+	QueryType_QUERY_TYPE_CSYNC      QueryType = 62
+	QueryType_QUERY_TYPE_ZONEMD     QueryType = 63
+	QueryType_QUERY_TYPE_SVCB       QueryType = 64
+	QueryType_QUERY_TYPE_HTTPS      QueryType = 65
+	QueryType_QUERY_TYPE_DSYNC      QueryType = 66
+	QueryType_QUERY_TYPE_SPF        QueryType = 99
+	QueryType_QUERY_TYPE_UINFO      QueryType = 100
+	QueryType_QUERY_TYPE_UID        QueryType = 101
+	QueryType_QUERY_TYPE_GID        QueryType = 102
+	QueryType_QUERY_TYPE_UNSPEC     QueryType = 103
+	QueryType_QUERY_TYPE_NID        QueryType = 104
+	QueryType_QUERY_TYPE_L32        QueryType = 105
+	QueryType_QUERY_TYPE_L64        QueryType = 106
+	QueryType_QUERY_TYPE_LP         QueryType = 107
+	QueryType_QUERY_TYPE_EUI48      QueryType = 108
+	QueryType_QUERY_TYPE_EUI64      QueryType = 109
+	QueryType_QUERY_TYPE_NXNAME     QueryType = 128
+	QueryType_QUERY_TYPE_TKEY       QueryType = 249
+	QueryType_QUERY_TYPE_TSIG       QueryType = 250
+	QueryType_QUERY_TYPE_IXFR       QueryType = 251
+	QueryType_QUERY_TYPE_AXFR       QueryType = 252
+	QueryType_QUERY_TYPE_MAILB      QueryType = 253
+	QueryType_QUERY_TYPE_MAILA      QueryType = 254
+	QueryType_QUERY_TYPE_ANY        QueryType = 255
+	QueryType_QUERY_TYPE_URI        QueryType = 256
+	QueryType_QUERY_TYPE_CAA        QueryType = 257
+	QueryType_QUERY_TYPE_AVC        QueryType = 258
+	QueryType_QUERY_TYPE_DOA        QueryType = 259
+	QueryType_QUERY_TYPE_AMTRELAY   QueryType = 260
+	QueryType_QUERY_TYPE_RESINFO    QueryType = 261
+	QueryType_QUERY_TYPE_WALLET     QueryType = 262
+	QueryType_QUERY_TYPE_CLA        QueryType = 263
+	QueryType_QUERY_TYPE_IPN        QueryType = 264
+	QueryType_QUERY_TYPE_TA         QueryType = 32768
+	QueryType_QUERY_TYPE_DLV        QueryType = 32769
+)
+
+// Enum value maps for QueryType.
+var (
+	QueryType_name = map[int32]string{
+		0:     "QUERY_TYPE_UNKNOWN",
+		1:     "QUERY_TYPE_A",
+		2:     "QUERY_TYPE_NS",
+		3:     "QUERY_TYPE_MD",
+		4:     "QUERY_TYPE_MF",
+		5:     "QUERY_TYPE_CNAME",
+		6:     "QUERY_TYPE_SOA",
+		7:     "QUERY_TYPE_MB",
+		8:     "QUERY_TYPE_MG",
+		9:     "QUERY_TYPE_MR",
+		10:    "QUERY_TYPE_NULL",
+		11:    "QUERY_TYPE_WKS",
+		12:    "QUERY_TYPE_PTR",
+		13:    "QUERY_TYPE_HINFO",
+		14:    "QUERY_TYPE_MINFO",
+		15:    "QUERY_TYPE_MX",
+		16:    "QUERY_TYPE_TXT",
+		17:    "QUERY_TYPE_RP",
+		18:    "QUERY_TYPE_AFSDB",
+		19:    "QUERY_TYPE_X25",
+		20:    "QUERY_TYPE_ISDN",
+		21:    "QUERY_TYPE_RT",
+		22:    "QUERY_TYPE_NSAP",
+		23:    "QUERY_TYPE_NSAP_PTR",
+		24:    "QUERY_TYPE_SIG",
+		25:    "QUERY_TYPE_KEY",
+		26:    "QUERY_TYPE_PX",
+		27:    "QUERY_TYPE_GPOS",
+		28:    "QUERY_TYPE_AAAA",
+		29:    "QUERY_TYPE_LOC",
+		30:    "QUERY_TYPE_NXT",
+		31:    "QUERY_TYPE_EID",
+		32:    "QUERY_TYPE_NIMLOC",
+		33:    "QUERY_TYPE_SRV",
+		34:    "QUERY_TYPE_ATMA",
+		35:    "QUERY_TYPE_NAPTR",
+		36:    "QUERY_TYPE_KX",
+		37:    "QUERY_TYPE_CERT",
+		38:    "QUERY_TYPE_A6",
+		39:    "QUERY_TYPE_DNAME",
+		40:    "QUERY_TYPE_SINK",
+		41:    "QUERY_TYPE_OPT",
+		42:    "QUERY_TYPE_APL",
+		43:    "QUERY_TYPE_DS",
+		44:    "QUERY_TYPE_SSHFP",
+		45:    "QUERY_TYPE_IPSECKEY",
+		46:    "QUERY_TYPE_RRSIG",
+		47:    "QUERY_TYPE_NSEC",
+		48:    "QUERY_TYPE_DNSKEY",
+		49:    "QUERY_TYPE_DHCID",
+		50:    "QUERY_TYPE_NSEC3",
+		51:    "QUERY_TYPE_NSEC3PARAM",
+		52:    "QUERY_TYPE_TLSA",
+		53:    "QUERY_TYPE_SMIMEA",
+		55:    "QUERY_TYPE_HIP",
+		56:    "QUERY_TYPE_NINFO",
+		57:    "QUERY_TYPE_RKEY",
+		58:    "QUERY_TYPE_TALINK",
+		59:    "QUERY_TYPE_CDS",
+		60:    "QUERY_TYPE_CDNSKEY",
+		61:    "QUERY_TYPE_OPENPGPKEY",
+		62:    "QUERY_TYPE_CSYNC",
+		63:    "QUERY_TYPE_ZONEMD",
+		64:    "QUERY_TYPE_SVCB",
+		65:    "QUERY_TYPE_HTTPS",
+		66:    "QUERY_TYPE_DSYNC",
+		99:    "QUERY_TYPE_SPF",
+		100:   "QUERY_TYPE_UINFO",
+		101:   "QUERY_TYPE_UID",
+		102:   "QUERY_TYPE_GID",
+		103:   "QUERY_TYPE_UNSPEC",
+		104:   "QUERY_TYPE_NID",
+		105:   "QUERY_TYPE_L32",
+		106:   "QUERY_TYPE_L64",
+		107:   "QUERY_TYPE_LP",
+		108:   "QUERY_TYPE_EUI48",
+		109:   "QUERY_TYPE_EUI64",
+		128:   "QUERY_TYPE_NXNAME",
+		249:   "QUERY_TYPE_TKEY",
+		250:   "QUERY_TYPE_TSIG",
+		251:   "QUERY_TYPE_IXFR",
+		252:   "QUERY_TYPE_AXFR",
+		253:   "QUERY_TYPE_MAILB",
+		254:   "QUERY_TYPE_MAILA",
+		255:   "QUERY_TYPE_ANY",
+		256:   "QUERY_TYPE_URI",
+		257:   "QUERY_TYPE_CAA",
+		258:   "QUERY_TYPE_AVC",
+		259:   "QUERY_TYPE_DOA",
+		260:   "QUERY_TYPE_AMTRELAY",
+		261:   "QUERY_TYPE_RESINFO",
+		262:   "QUERY_TYPE_WALLET",
+		263:   "QUERY_TYPE_CLA",
+		264:   "QUERY_TYPE_IPN",
+		32768: "QUERY_TYPE_TA",
+		32769: "QUERY_TYPE_DLV",
+	}
+	QueryType_value = map[string]int32{
+		"QUERY_TYPE_UNKNOWN":    0,
+		"QUERY_TYPE_A":          1,
+		"QUERY_TYPE_NS":         2,
+		"QUERY_TYPE_MD":         3,
+		"QUERY_TYPE_MF":         4,
+		"QUERY_TYPE_CNAME":      5,
+		"QUERY_TYPE_SOA":        6,
+		"QUERY_TYPE_MB":         7,
+		"QUERY_TYPE_MG":         8,
+		"QUERY_TYPE_MR":         9,
+		"QUERY_TYPE_NULL":       10,
+		"QUERY_TYPE_WKS":        11,
+		"QUERY_TYPE_PTR":        12,
+		"QUERY_TYPE_HINFO":      13,
+		"QUERY_TYPE_MINFO":      14,
+		"QUERY_TYPE_MX":         15,
+		"QUERY_TYPE_TXT":        16,
+		"QUERY_TYPE_RP":         17,
+		"QUERY_TYPE_AFSDB":      18,
+		"QUERY_TYPE_X25":        19,
+		"QUERY_TYPE_ISDN":       20,
+		"QUERY_TYPE_RT":         21,
+		"QUERY_TYPE_NSAP":       22,
+		"QUERY_TYPE_NSAP_PTR":   23,
+		"QUERY_TYPE_SIG":        24,
+		"QUERY_TYPE_KEY":        25,
+		"QUERY_TYPE_PX":         26,
+		"QUERY_TYPE_GPOS":       27,
+		"QUERY_TYPE_AAAA":       28,
+		"QUERY_TYPE_LOC":        29,
+		"QUERY_TYPE_NXT":        30,
+		"QUERY_TYPE_EID":        31,
+		"QUERY_TYPE_NIMLOC":     32,
+		"QUERY_TYPE_SRV":        33,
+		"QUERY_TYPE_ATMA":       34,
+		"QUERY_TYPE_NAPTR":      35,
+		"QUERY_TYPE_KX":         36,
+		"QUERY_TYPE_CERT":       37,
+		"QUERY_TYPE_A6":         38,
+		"QUERY_TYPE_DNAME":      39,
+		"QUERY_TYPE_SINK":       40,
+		"QUERY_TYPE_OPT":        41,
+		"QUERY_TYPE_APL":        42,
+		"QUERY_TYPE_DS":         43,
+		"QUERY_TYPE_SSHFP":      44,
+		"QUERY_TYPE_IPSECKEY":   45,
+		"QUERY_TYPE_RRSIG":      46,
+		"QUERY_TYPE_NSEC":       47,
+		"QUERY_TYPE_DNSKEY":     48,
+		"QUERY_TYPE_DHCID":      49,
+		"QUERY_TYPE_NSEC3":      50,
+		"QUERY_TYPE_NSEC3PARAM": 51,
+		"QUERY_TYPE_TLSA":       52,
+		"QUERY_TYPE_SMIMEA":     53,
+		"QUERY_TYPE_HIP":        55,
+		"QUERY_TYPE_NINFO":      56,
+		"QUERY_TYPE_RKEY":       57,
+		"QUERY_TYPE_TALINK":     58,
+		"QUERY_TYPE_CDS":        59,
+		"QUERY_TYPE_CDNSKEY":    60,
+		"QUERY_TYPE_OPENPGPKEY": 61,
+		"QUERY_TYPE_CSYNC":      62,
+		"QUERY_TYPE_ZONEMD":     63,
+		"QUERY_TYPE_SVCB":       64,
+		"QUERY_TYPE_HTTPS":      65,
+		"QUERY_TYPE_DSYNC":      66,
+		"QUERY_TYPE_SPF":        99,
+		"QUERY_TYPE_UINFO":      100,
+		"QUERY_TYPE_UID":        101,
+		"QUERY_TYPE_GID":        102,
+		"QUERY_TYPE_UNSPEC":     103,
+		"QUERY_TYPE_NID":        104,
+		"QUERY_TYPE_L32":        105,
+		"QUERY_TYPE_L64":        106,
+		"QUERY_TYPE_LP":         107,
+		"QUERY_TYPE_EUI48":      108,
+		"QUERY_TYPE_EUI64":      109,
+		"QUERY_TYPE_NXNAME":     128,
+		"QUERY_TYPE_TKEY":       249,
+		"QUERY_TYPE_TSIG":       250,
+		"QUERY_TYPE_IXFR":       251,
+		"QUERY_TYPE_AXFR":       252,
+		"QUERY_TYPE_MAILB":      253,
+		"QUERY_TYPE_MAILA":      254,
+		"QUERY_TYPE_ANY":        255,
+		"QUERY_TYPE_URI":        256,
+		"QUERY_TYPE_CAA":        257,
+		"QUERY_TYPE_AVC":        258,
+		"QUERY_TYPE_DOA":        259,
+		"QUERY_TYPE_AMTRELAY":   260,
+		"QUERY_TYPE_RESINFO":    261,
+		"QUERY_TYPE_WALLET":     262,
+		"QUERY_TYPE_CLA":        263,
+		"QUERY_TYPE_IPN":        264,
+		"QUERY_TYPE_TA":         32768,
+		"QUERY_TYPE_DLV":        32769,
+	}
+)
+
+func (x QueryType) Enum() *QueryType {
+	p := new(QueryType)
+	*p = x
+	return p
+}
+
+func (x QueryType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QueryType) Descriptor() protoreflect.EnumDescriptor {
+	return file_dns_proto_enumTypes[2].Descriptor()
+}
+
+func (QueryType) Type() protoreflect.EnumType {
+	return &file_dns_proto_enumTypes[2]
+}
+
+func (x QueryType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QueryType.Descriptor instead.
+func (QueryType) EnumDescriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{2}
+}
+
+type BlockEventType int32
+
+const (
+	BlockEventType_BLOCK_EVENT_TYPE_UNKNOWN BlockEventType = 0
+	// A block of the snapshot. The first snapshot event of a stream tells
+	// the watcher to drop the blocks it knows.
+	BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT BlockEventType = 1
+	// The snapshot is complete.
+	BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT_END BlockEventType = 2
+	// A block was added, or replaced.
+	BlockEventType_BLOCK_EVENT_TYPE_ADDED   BlockEventType = 3
+	BlockEventType_BLOCK_EVENT_TYPE_REMOVED BlockEventType = 4
+	BlockEventType_BLOCK_EVENT_TYPE_EXPIRED BlockEventType = 5
+)
+
+// Enum value maps for BlockEventType.
+var (
+	BlockEventType_name = map[int32]string{
+		0: "BLOCK_EVENT_TYPE_UNKNOWN",
+		1: "BLOCK_EVENT_TYPE_SNAPSHOT",
+		2: "BLOCK_EVENT_TYPE_SNAPSHOT_END",
+		3: "BLOCK_EVENT_TYPE_ADDED",
+		4: "BLOCK_EVENT_TYPE_REMOVED",
+		5: "BLOCK_EVENT_TYPE_EXPIRED",
+	}
+	BlockEventType_value = map[string]int32{
+		"BLOCK_EVENT_TYPE_UNKNOWN":      0,
+		"BLOCK_EVENT_TYPE_SNAPSHOT":     1,
+		"BLOCK_EVENT_TYPE_SNAPSHOT_END": 2,
+		"BLOCK_EVENT_TYPE_ADDED":        3,
+		"BLOCK_EVENT_TYPE_REMOVED":      4,
+		"BLOCK_EVENT_TYPE_EXPIRED":      5,
+	}
+)
+
+func (x BlockEventType) Enum() *BlockEventType {
+	p := new(BlockEventType)
+	*p = x
+	return p
+}
+
+func (x BlockEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BlockEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_dns_proto_enumTypes[3].Descriptor()
+}
+
+func (BlockEventType) Type() protoreflect.EnumType {
+	return &file_dns_proto_enumTypes[3]
+}
+
+func (x BlockEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BlockEventType.Descriptor instead.
+func (BlockEventType) EnumDescriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{3}
+}
+
+type BlocklistFormat int32
+
+const (
+	// JSON Lines, one object per entry.
+	BlocklistFormat_BLOCKLIST_FORMAT_JSONL BlocklistFormat = 0
+	// CSV with a header line.
+	BlocklistFormat_BLOCKLIST_FORMAT_CSV BlocklistFormat = 1
+)
+
+// Enum value maps for BlocklistFormat.
+var (
+	BlocklistFormat_name = map[int32]string{
+		0: "BLOCKLIST_FORMAT_JSONL",
+		1: "BLOCKLIST_FORMAT_CSV",
+	}
+	BlocklistFormat_value = map[string]int32{
+		"BLOCKLIST_FORMAT_JSONL": 0,
+		"BLOCKLIST_FORMAT_CSV":   1,
+	}
+)
+
+func (x BlocklistFormat) Enum() *BlocklistFormat {
+	p := new(BlocklistFormat)
+	*p = x
+	return p
+}
+
+func (x BlocklistFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BlocklistFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_dns_proto_enumTypes[4].Descriptor()
+}
+
+func (BlocklistFormat) Type() protoreflect.EnumType {
+	return &file_dns_proto_enumTypes[4]
+}
+
+func (x BlocklistFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BlocklistFormat.Descriptor instead.
+func (BlocklistFormat) EnumDescriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{4}
+}
+
+type ImportMode int32
+
+const (
+	// The entries of the file are added to the blacklist, replacing those
+	// of the same IPs.
+	ImportMode_IMPORT_MODE_MERGE ImportMode = 0
+	// The blacklist is replaced with the entries of the file.
+	ImportMode_IMPORT_MODE_REPLACE ImportMode = 1
+)
+
+// Enum value maps for ImportMode.
+var (
+	ImportMode_name = map[int32]string{
+		0: "IMPORT_MODE_MERGE",
+		1: "IMPORT_MODE_REPLACE",
+	}
+	ImportMode_value = map[string]int32{
+		"IMPORT_MODE_MERGE":   0,
+		"IMPORT_MODE_REPLACE": 1,
+	}
+)
+
+func (x ImportMode) Enum() *ImportMode {
+	p := new(ImportMode)
+	*p = x
+	return p
+}
+
+func (x ImportMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_dns_proto_enumTypes[5].Descriptor()
+}
+
+func (ImportMode) Type() protoreflect.EnumType {
+	return &file_dns_proto_enumTypes[5]
+}
+
+func (x ImportMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportMode.Descriptor instead.
+func (ImportMode) EnumDescriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{5}
+}
+
 type DnsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	IpAddress string `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
-	Domain    string `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
-	QueryType string `protobuf:"bytes,3,opt,name=query_type,json=queryType,proto3" json:"query_type,omitempty"`
-	Timestamp int64  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Queried name, normalized by the server: lower case ASCII, Unicode
+	// labels converted to punycode, no trailing dot.
+	Domain    string    `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	QueryType QueryType `protobuf:"varint,5,opt,name=query_type,json=queryType,proto3,enum=dns.QueryType" json:"query_type,omitempty"`
+	Timestamp int64     `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Response code, unset when the resolver did not answer.
+	Rcode   *ResponseCode `protobuf:"varint,6,opt,name=rcode,proto3,enum=dns.ResponseCode,oneof" json:"rcode,omitempty"`
+	Answers []*Answer     `protobuf:"bytes,7,rep,name=answers,proto3" json:"answers,omitempty"`
+	// Source port of the query.
+	ClientPort uint32    `protobuf:"varint,8,opt,name=client_port,json=clientPort,proto3" json:"client_port,omitempty"`
+	Transport  Transport `protobuf:"varint,9,opt,name=transport,proto3,enum=dns.Transport" json:"transport,omitempty"`
+	// Sizes of the query and response messages in bytes.
+	QuerySize    uint32 `protobuf:"varint,10,opt,name=query_size,json=querySize,proto3" json:"query_size,omitempty"`
+	ResponseSize uint32 `protobuf:"varint,11,opt,name=response_size,json=responseSize,proto3" json:"response_size,omitempty"`
+	// EDNS Client Subnet option of the query (RFC 7871), as a CIDR.
+	EdnsClientSubnet string `protobuf:"bytes,12,opt,name=edns_client_subnet,json=ednsClientSubnet,proto3" json:"edns_client_subnet,omitempty"`
+	// Identity of the sensor or resolver that observed the query.
+	SensorId string `protobuf:"bytes,13,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
 }
 
 func (x *DnsRequest) Reset() {
@@ -77,11 +745,11 @@ func (x *DnsRequest) GetDomain() string {
 	return ""
 }
 
-func (x *DnsRequest) GetQueryType() string {
+func (x *DnsRequest) GetQueryType() QueryType {
 	if x != nil {
 		return x.QueryType
 	}
-	return ""
+	return QueryType_QUERY_TYPE_UNKNOWN
 }
 
 func (x *DnsRequest) GetTimestamp() int64 {
@@ -91,18 +759,145 @@ func (x *DnsRequest) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *DnsRequest) GetRcode() ResponseCode {
+	if x != nil && x.Rcode != nil {
+		return *x.Rcode
+	}
+	return ResponseCode_RESPONSE_CODE_NOERROR
+}
+
+func (x *DnsRequest) GetAnswers() []*Answer {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+func (x *DnsRequest) GetClientPort() uint32 {
+	if x != nil {
+		return x.ClientPort
+	}
+	return 0
+}
+
+func (x *DnsRequest) GetTransport() Transport {
+	if x != nil {
+		return x.Transport
+	}
+	return Transport_TRANSPORT_UNKNOWN
+}
+
+func (x *DnsRequest) GetQuerySize() uint32 {
+	if x != nil {
+		return x.QuerySize
+	}
+	return 0
+}
+
+func (x *DnsRequest) GetResponseSize() uint32 {
+	if x != nil {
+		return x.ResponseSize
+	}
+	return 0
+}
+
+func (x *DnsRequest) GetEdnsClientSubnet() string {
+	if x != nil {
+		return x.EdnsClientSubnet
+	}
+	return ""
+}
+
+func (x *DnsRequest) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+// A resource record of the answer section.
+type Answer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type QueryType `protobuf:"varint,1,opt,name=type,proto3,enum=dns.QueryType" json:"type,omitempty"`
+	// Record data in presentation format, e.g. the address of an A record.
+	Data string `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Ttl  uint32 `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+}
+
+func (x *Answer) Reset() {
+	*x = Answer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Answer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Answer) ProtoMessage() {}
+
+func (x *Answer) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Answer.ProtoReflect.Descriptor instead.
+func (*Answer) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Answer) GetType() QueryType {
+	if x != nil {
+		return x.Type
+	}
+	return QueryType_QUERY_TYPE_UNKNOWN
+}
+
+func (x *Answer) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *Answer) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
 type DnsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Why the request got its status, e.g. "blacklisted" or
+	// "store_unavailable_fail_closed", empty for a clean IP.
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Scoped block rule that blocked the request, set when reason is
+	// "block_rule".
+	Rule *BlockRule `protobuf:"bytes,3,opt,name=rule,proto3" json:"rule,omitempty"`
 }
 
 func (x *DnsResponse) Reset() {
 	*x = DnsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dns_proto_msgTypes[1]
+		mi := &file_dns_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -115,7 +910,7 @@ func (x *DnsResponse) String() string {
 func (*DnsResponse) ProtoMessage() {}
 
 func (x *DnsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dns_proto_msgTypes[1]
+	mi := &file_dns_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -128,7 +923,7 @@ func (x *DnsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DnsResponse.ProtoReflect.Descriptor instead.
 func (*DnsResponse) Descriptor() ([]byte, []int) {
-	return file_dns_proto_rawDescGZIP(), []int{1}
+	return file_dns_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *DnsResponse) GetStatus() string {
@@ -138,18 +933,35 @@ func (x *DnsResponse) GetStatus() string {
 	return ""
 }
 
+func (x *DnsResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DnsResponse) GetRule() *BlockRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
 type BlockIpRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	IpAddress string `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Reason    string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Duration of the block, 0 blocks the IP until it is unblocked.
+	TtlSeconds int64 `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
 }
 
 func (x *BlockIpRequest) Reset() {
 	*x = BlockIpRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dns_proto_msgTypes[2]
+		mi := &file_dns_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -162,7 +974,7 @@ func (x *BlockIpRequest) String() string {
 func (*BlockIpRequest) ProtoMessage() {}
 
 func (x *BlockIpRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dns_proto_msgTypes[2]
+	mi := &file_dns_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -175,7 +987,7 @@ func (x *BlockIpRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockIpRequest.ProtoReflect.Descriptor instead.
 func (*BlockIpRequest) Descriptor() ([]byte, []int) {
-	return file_dns_proto_rawDescGZIP(), []int{2}
+	return file_dns_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *BlockIpRequest) GetIpAddress() string {
@@ -185,6 +997,20 @@ func (x *BlockIpRequest) GetIpAddress() string {
 	return ""
 }
 
+func (x *BlockIpRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BlockIpRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
 type BlockIpResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -196,7 +1022,7 @@ type BlockIpResponse struct {
 func (x *BlockIpResponse) Reset() {
 	*x = BlockIpResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dns_proto_msgTypes[3]
+		mi := &file_dns_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -209,7 +1035,7 @@ func (x *BlockIpResponse) String() string {
 func (*BlockIpResponse) ProtoMessage() {}
 
 func (x *BlockIpResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dns_proto_msgTypes[3]
+	mi := &file_dns_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -222,7 +1048,7 @@ func (x *BlockIpResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockIpResponse.ProtoReflect.Descriptor instead.
 func (*BlockIpResponse) Descriptor() ([]byte, []int) {
-	return file_dns_proto_rawDescGZIP(), []int{3}
+	return file_dns_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *BlockIpResponse) GetStatus() string {
@@ -232,35 +1058,2608 @@ func (x *BlockIpResponse) GetStatus() string {
 	return ""
 }
 
-var File_dns_proto protoreflect.FileDescriptor
+type UnblockIpRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_dns_proto_rawDesc = []byte{
-	0x0a, 0x09, 0x64, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x64, 0x6e, 0x73,
-	0x22, 0x80, 0x01, 0x0a, 0x0a, 0x44, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16,
-	0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
-	0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f,
-	0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72,
-	0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	IpAddress string `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+}
+
+func (x *UnblockIpRequest) Reset() {
+	*x = UnblockIpRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnblockIpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnblockIpRequest) ProtoMessage() {}
+
+func (x *UnblockIpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnblockIpRequest.ProtoReflect.Descriptor instead.
+func (*UnblockIpRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UnblockIpRequest) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+type UnblockIpResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *UnblockIpResponse) Reset() {
+	*x = UnblockIpResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnblockIpResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnblockIpResponse) ProtoMessage() {}
+
+func (x *UnblockIpResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnblockIpResponse.ProtoReflect.Descriptor instead.
+func (*UnblockIpResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UnblockIpResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListBlockedIpsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListBlockedIpsRequest) Reset() {
+	*x = ListBlockedIpsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBlockedIpsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlockedIpsRequest) ProtoMessage() {}
+
+func (x *ListBlockedIpsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlockedIpsRequest.ProtoReflect.Descriptor instead.
+func (*ListBlockedIpsRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{7}
+}
+
+type BlockedIp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IpAddress string `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	// Remaining time before the block expires, -1 if it never expires.
+	TtlSeconds int64  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	Reason     string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Unix time the IP was blocked.
+	CreatedAt int64 `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *BlockedIp) Reset() {
+	*x = BlockedIp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockedIp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockedIp) ProtoMessage() {}
+
+func (x *BlockedIp) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockedIp.ProtoReflect.Descriptor instead.
+func (*BlockedIp) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BlockedIp) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *BlockedIp) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *BlockedIp) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BlockedIp) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ListBlockedIpsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockedIps []*BlockedIp `protobuf:"bytes,1,rep,name=blocked_ips,json=blockedIps,proto3" json:"blocked_ips,omitempty"`
+}
+
+func (x *ListBlockedIpsResponse) Reset() {
+	*x = ListBlockedIpsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBlockedIpsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlockedIpsResponse) ProtoMessage() {}
+
+func (x *ListBlockedIpsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlockedIpsResponse.ProtoReflect.Descriptor instead.
+func (*ListBlockedIpsResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListBlockedIpsResponse) GetBlockedIps() []*BlockedIp {
+	if x != nil {
+		return x.BlockedIps
+	}
+	return nil
+}
+
+type CheckIpRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IpAddress string `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+}
+
+func (x *CheckIpRequest) Reset() {
+	*x = CheckIpRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckIpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckIpRequest) ProtoMessage() {}
+
+func (x *CheckIpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckIpRequest.ProtoReflect.Descriptor instead.
+func (*CheckIpRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CheckIpRequest) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+type CheckIpResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IpAddress string `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Blocked   bool   `protobuf:"varint,2,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	// Blacklist entry of the IP, set when blocked is true.
+	Entry *BlockedIp `protobuf:"bytes,3,opt,name=entry,proto3" json:"entry,omitempty"`
+}
+
+func (x *CheckIpResponse) Reset() {
+	*x = CheckIpResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckIpResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckIpResponse) ProtoMessage() {}
+
+func (x *CheckIpResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckIpResponse.ProtoReflect.Descriptor instead.
+func (*CheckIpResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CheckIpResponse) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *CheckIpResponse) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+func (x *CheckIpResponse) GetEntry() *BlockedIp {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{12}
+}
+
+type GetStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Counters map[string]int64 `protobuf:"bytes,1,rep,name=counters,proto3" json:"counters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetStatsResponse) GetCounters() map[string]int64 {
+	if x != nil {
+		return x.Counters
+	}
+	return nil
+}
+
+type TailDnsRequestsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TailDnsRequestsRequest) Reset() {
+	*x = TailDnsRequestsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TailDnsRequestsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TailDnsRequestsRequest) ProtoMessage() {}
+
+func (x *TailDnsRequestsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TailDnsRequestsRequest.ProtoReflect.Descriptor instead.
+func (*TailDnsRequestsRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{14}
+}
+
+type TailDnsRequestsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Request *DnsRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	// Status returned to the sender, "success" or "blocked".
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *TailDnsRequestsResponse) Reset() {
+	*x = TailDnsRequestsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TailDnsRequestsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TailDnsRequestsResponse) ProtoMessage() {}
+
+func (x *TailDnsRequestsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TailDnsRequestsResponse.ProtoReflect.Descriptor instead.
+func (*TailDnsRequestsResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TailDnsRequestsResponse) GetRequest() *DnsRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *TailDnsRequestsResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// An IP, a CIDR or a domain suffix that can never be blocked.
+type AllowlistEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	// "ip", "cidr" or "domain".
+	Kind      string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Reason    string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedAt int64  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *AllowlistEntry) Reset() {
+	*x = AllowlistEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllowlistEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllowlistEntry) ProtoMessage() {}
+
+func (x *AllowlistEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllowlistEntry.ProtoReflect.Descriptor instead.
+func (*AllowlistEntry) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AllowlistEntry) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *AllowlistEntry) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *AllowlistEntry) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AllowlistEntry) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type AddAllowlistEntryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value  string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *AddAllowlistEntryRequest) Reset() {
+	*x = AddAllowlistEntryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddAllowlistEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddAllowlistEntryRequest) ProtoMessage() {}
+
+func (x *AddAllowlistEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddAllowlistEntryRequest.ProtoReflect.Descriptor instead.
+func (*AddAllowlistEntryRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AddAllowlistEntryRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *AddAllowlistEntryRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type AddAllowlistEntryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status string          `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Entry  *AllowlistEntry `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+}
+
+func (x *AddAllowlistEntryResponse) Reset() {
+	*x = AddAllowlistEntryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddAllowlistEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddAllowlistEntryResponse) ProtoMessage() {}
+
+func (x *AddAllowlistEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddAllowlistEntryResponse.ProtoReflect.Descriptor instead.
+func (*AddAllowlistEntryResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AddAllowlistEntryResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AddAllowlistEntryResponse) GetEntry() *AllowlistEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type RemoveAllowlistEntryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *RemoveAllowlistEntryRequest) Reset() {
+	*x = RemoveAllowlistEntryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveAllowlistEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveAllowlistEntryRequest) ProtoMessage() {}
+
+func (x *RemoveAllowlistEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveAllowlistEntryRequest.ProtoReflect.Descriptor instead.
+func (*RemoveAllowlistEntryRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RemoveAllowlistEntryRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type RemoveAllowlistEntryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *RemoveAllowlistEntryResponse) Reset() {
+	*x = RemoveAllowlistEntryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveAllowlistEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveAllowlistEntryResponse) ProtoMessage() {}
+
+func (x *RemoveAllowlistEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveAllowlistEntryResponse.ProtoReflect.Descriptor instead.
+func (*RemoveAllowlistEntryResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RemoveAllowlistEntryResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListAllowlistRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListAllowlistRequest) Reset() {
+	*x = ListAllowlistRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAllowlistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAllowlistRequest) ProtoMessage() {}
+
+func (x *ListAllowlistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAllowlistRequest.ProtoReflect.Descriptor instead.
+func (*ListAllowlistRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{21}
+}
+
+type ListAllowlistResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*AllowlistEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *ListAllowlistResponse) Reset() {
+	*x = ListAllowlistResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAllowlistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAllowlistResponse) ProtoMessage() {}
+
+func (x *ListAllowlistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAllowlistResponse.ProtoReflect.Descriptor instead.
+func (*ListAllowlistResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListAllowlistResponse) GetEntries() []*AllowlistEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// A scoped block: requests from source for a domain matching domain_pattern
+// with the type query_type are blocked, the other requests of the source are
+// not.
+type BlockRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Derived from the scope, adding a rule replaces the rule with the same
+	// scope.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// IP or CIDR, empty for any source.
+	Source string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// Domain, "*.<domain>" for its subdomains, or empty for any domain.
+	DomainPattern string `protobuf:"bytes,3,opt,name=domain_pattern,json=domainPattern,proto3" json:"domain_pattern,omitempty"`
+	// QUERY_TYPE_UNKNOWN for any type.
+	QueryType QueryType `protobuf:"varint,4,opt,name=query_type,json=queryType,proto3,enum=dns.QueryType" json:"query_type,omitempty"`
+	Reason    string    `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Remaining time before the rule expires, -1 if it never expires.
+	TtlSeconds int64 `protobuf:"varint,6,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	CreatedAt  int64 `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *BlockRule) Reset() {
+	*x = BlockRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockRule) ProtoMessage() {}
+
+func (x *BlockRule) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockRule.ProtoReflect.Descriptor instead.
+func (*BlockRule) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BlockRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BlockRule) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *BlockRule) GetDomainPattern() string {
+	if x != nil {
+		return x.DomainPattern
+	}
+	return ""
+}
+
+func (x *BlockRule) GetQueryType() QueryType {
+	if x != nil {
+		return x.QueryType
+	}
+	return QueryType_QUERY_TYPE_UNKNOWN
+}
+
+func (x *BlockRule) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BlockRule) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *BlockRule) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type AddBlockRuleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Source        string    `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	DomainPattern string    `protobuf:"bytes,2,opt,name=domain_pattern,json=domainPattern,proto3" json:"domain_pattern,omitempty"`
+	QueryType     QueryType `protobuf:"varint,3,opt,name=query_type,json=queryType,proto3,enum=dns.QueryType" json:"query_type,omitempty"`
+	Reason        string    `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Duration of the rule, 0 keeps it until it is removed.
+	TtlSeconds int64 `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+}
+
+func (x *AddBlockRuleRequest) Reset() {
+	*x = AddBlockRuleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddBlockRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBlockRuleRequest) ProtoMessage() {}
+
+func (x *AddBlockRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBlockRuleRequest.ProtoReflect.Descriptor instead.
+func (*AddBlockRuleRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AddBlockRuleRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *AddBlockRuleRequest) GetDomainPattern() string {
+	if x != nil {
+		return x.DomainPattern
+	}
+	return ""
+}
+
+func (x *AddBlockRuleRequest) GetQueryType() QueryType {
+	if x != nil {
+		return x.QueryType
+	}
+	return QueryType_QUERY_TYPE_UNKNOWN
+}
+
+func (x *AddBlockRuleRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AddBlockRuleRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type AddBlockRuleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status string     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Rule   *BlockRule `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+}
+
+func (x *AddBlockRuleResponse) Reset() {
+	*x = AddBlockRuleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddBlockRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBlockRuleResponse) ProtoMessage() {}
+
+func (x *AddBlockRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBlockRuleResponse.ProtoReflect.Descriptor instead.
+func (*AddBlockRuleResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AddBlockRuleResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AddBlockRuleResponse) GetRule() *BlockRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type RemoveBlockRuleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *RemoveBlockRuleRequest) Reset() {
+	*x = RemoveBlockRuleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveBlockRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveBlockRuleRequest) ProtoMessage() {}
+
+func (x *RemoveBlockRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveBlockRuleRequest.ProtoReflect.Descriptor instead.
+func (*RemoveBlockRuleRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RemoveBlockRuleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RemoveBlockRuleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *RemoveBlockRuleResponse) Reset() {
+	*x = RemoveBlockRuleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveBlockRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveBlockRuleResponse) ProtoMessage() {}
+
+func (x *RemoveBlockRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveBlockRuleResponse.ProtoReflect.Descriptor instead.
+func (*RemoveBlockRuleResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RemoveBlockRuleResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListBlockRulesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListBlockRulesRequest) Reset() {
+	*x = ListBlockRulesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBlockRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlockRulesRequest) ProtoMessage() {}
+
+func (x *ListBlockRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlockRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListBlockRulesRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{28}
+}
+
+type ListBlockRulesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rules []*BlockRule `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+}
+
+func (x *ListBlockRulesResponse) Reset() {
+	*x = ListBlockRulesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBlockRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlockRulesResponse) ProtoMessage() {}
+
+func (x *ListBlockRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlockRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListBlockRulesResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListBlockRulesResponse) GetRules() []*BlockRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type ExplainVerdictRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IpAddress string    `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Domain    string    `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	QueryType QueryType `protobuf:"varint,3,opt,name=query_type,json=queryType,proto3,enum=dns.QueryType" json:"query_type,omitempty"`
+}
+
+func (x *ExplainVerdictRequest) Reset() {
+	*x = ExplainVerdictRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExplainVerdictRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainVerdictRequest) ProtoMessage() {}
+
+func (x *ExplainVerdictRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainVerdictRequest.ProtoReflect.Descriptor instead.
+func (*ExplainVerdictRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ExplainVerdictRequest) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *ExplainVerdictRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ExplainVerdictRequest) GetQueryType() QueryType {
+	if x != nil {
+		return x.QueryType
+	}
+	return QueryType_QUERY_TYPE_UNKNOWN
+}
+
+// An allowlist entry, blacklist entry or block rule matching a request.
+type VerdictMatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// "allowlist_ip", "allowlist_cidr", "allowlist_domain", "blacklist_ip",
+	// "block_rule_ip", "block_rule_cidr" or "block_rule_any_source".
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Matching IP, CIDR or domain suffix of an allowlist or blacklist entry,
+	// ID of a block rule.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Set for block rules.
+	Rule      *BlockRule `protobuf:"bytes,3,opt,name=rule,proto3" json:"rule,omitempty"`
+	Reason    string     `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedAt int64      `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Remaining time before the match expires, -1 if it never expires.
+	TtlSeconds int64 `protobuf:"varint,6,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// Whether the match decided the verdict; the others are overridden or
+	// redundant.
+	Decisive bool `protobuf:"varint,7,opt,name=decisive,proto3" json:"decisive,omitempty"`
+}
+
+func (x *VerdictMatch) Reset() {
+	*x = VerdictMatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerdictMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerdictMatch) ProtoMessage() {}
+
+func (x *VerdictMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerdictMatch.ProtoReflect.Descriptor instead.
+func (*VerdictMatch) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *VerdictMatch) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *VerdictMatch) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *VerdictMatch) GetRule() *BlockRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *VerdictMatch) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *VerdictMatch) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *VerdictMatch) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *VerdictMatch) GetDecisive() bool {
+	if x != nil {
+		return x.Decisive
+	}
+	return false
+}
+
+type ExplainVerdictResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Normalized request.
+	IpAddress string    `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Domain    string    `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	QueryType QueryType `protobuf:"varint,3,opt,name=query_type,json=queryType,proto3,enum=dns.QueryType" json:"query_type,omitempty"`
+	// Status and reason SendDnsRequest would return.
+	Status  string          `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Reason  string          `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Matches []*VerdictMatch `protobuf:"bytes,6,rep,name=matches,proto3" json:"matches,omitempty"`
+}
+
+func (x *ExplainVerdictResponse) Reset() {
+	*x = ExplainVerdictResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExplainVerdictResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainVerdictResponse) ProtoMessage() {}
+
+func (x *ExplainVerdictResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainVerdictResponse.ProtoReflect.Descriptor instead.
+func (*ExplainVerdictResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ExplainVerdictResponse) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *ExplainVerdictResponse) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ExplainVerdictResponse) GetQueryType() QueryType {
+	if x != nil {
+		return x.QueryType
+	}
+	return QueryType_QUERY_TYPE_UNKNOWN
+}
+
+func (x *ExplainVerdictResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ExplainVerdictResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ExplainVerdictResponse) GetMatches() []*VerdictMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+type WatchBlocksRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Resume token of the last event received by a reconnecting watcher,
+	// empty to start with a snapshot.
+	ResumeToken string `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+}
+
+func (x *WatchBlocksRequest) Reset() {
+	*x = WatchBlocksRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchBlocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBlocksRequest) ProtoMessage() {}
+
+func (x *WatchBlocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBlocksRequest.ProtoReflect.Descriptor instead.
+func (*WatchBlocksRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *WatchBlocksRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+type WatchBlocksResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type BlockEventType `protobuf:"varint,1,opt,name=type,proto3,enum=dns.BlockEventType" json:"type,omitempty"`
+	// The blocked IP or the block rule of the event, as last known for
+	// removed and expired blocks.
+	BlockedIp *BlockedIp `protobuf:"bytes,2,opt,name=blocked_ip,json=blockedIp,proto3" json:"blocked_ip,omitempty"`
+	Rule      *BlockRule `protobuf:"bytes,3,opt,name=rule,proto3" json:"rule,omitempty"`
+	// Token resuming the stream after this event, set on every event but
+	// the snapshot ones.
+	ResumeToken string `protobuf:"bytes,4,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	// Unix time the change was seen by the server.
+	Time int64 `protobuf:"varint,5,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (x *WatchBlocksResponse) Reset() {
+	*x = WatchBlocksResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchBlocksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBlocksResponse) ProtoMessage() {}
+
+func (x *WatchBlocksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBlocksResponse.ProtoReflect.Descriptor instead.
+func (*WatchBlocksResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *WatchBlocksResponse) GetType() BlockEventType {
+	if x != nil {
+		return x.Type
+	}
+	return BlockEventType_BLOCK_EVENT_TYPE_UNKNOWN
+}
+
+func (x *WatchBlocksResponse) GetBlockedIp() *BlockedIp {
+	if x != nil {
+		return x.BlockedIp
+	}
+	return nil
+}
+
+func (x *WatchBlocksResponse) GetRule() *BlockRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *WatchBlocksResponse) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *WatchBlocksResponse) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type ExportBlocklistRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format BlocklistFormat `protobuf:"varint,1,opt,name=format,proto3,enum=dns.BlocklistFormat" json:"format,omitempty"`
+}
+
+func (x *ExportBlocklistRequest) Reset() {
+	*x = ExportBlocklistRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportBlocklistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportBlocklistRequest) ProtoMessage() {}
+
+func (x *ExportBlocklistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportBlocklistRequest.ProtoReflect.Descriptor instead.
+func (*ExportBlocklistRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ExportBlocklistRequest) GetFormat() BlocklistFormat {
+	if x != nil {
+		return x.Format
+	}
+	return BlocklistFormat_BLOCKLIST_FORMAT_JSONL
+}
+
+type ExportBlocklistResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The next chunk of the file.
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ExportBlocklistResponse) Reset() {
+	*x = ExportBlocklistResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportBlocklistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportBlocklistResponse) ProtoMessage() {}
+
+func (x *ExportBlocklistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportBlocklistResponse.ProtoReflect.Descriptor instead.
+func (*ExportBlocklistResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ExportBlocklistResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ImportBlocklistRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// format, mode and dry_run are read from the first message.
+	Format BlocklistFormat `protobuf:"varint,1,opt,name=format,proto3,enum=dns.BlocklistFormat" json:"format,omitempty"`
+	Mode   ImportMode      `protobuf:"varint,2,opt,name=mode,proto3,enum=dns.ImportMode" json:"mode,omitempty"`
+	// Compute the changes without applying them.
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// The next chunk of the file.
+	Data []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ImportBlocklistRequest) Reset() {
+	*x = ImportBlocklistRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportBlocklistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportBlocklistRequest) ProtoMessage() {}
+
+func (x *ImportBlocklistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportBlocklistRequest.ProtoReflect.Descriptor instead.
+func (*ImportBlocklistRequest) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ImportBlocklistRequest) GetFormat() BlocklistFormat {
+	if x != nil {
+		return x.Format
+	}
+	return BlocklistFormat_BLOCKLIST_FORMAT_JSONL
+}
+
+func (x *ImportBlocklistRequest) GetMode() ImportMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ImportMode_IMPORT_MODE_MERGE
+}
+
+func (x *ImportBlocklistRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportBlocklistRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type BlocklistChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// "add", "update", "remove", or "skip" for the entries protected by the
+	// allowlist.
+	Action string `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	// The imported entry, or the removed one.
+	Entry *BlockedIp `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+	// The entry replaced by an update.
+	Previous *BlockedIp `protobuf:"bytes,3,opt,name=previous,proto3" json:"previous,omitempty"`
+	Detail   string     `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+}
+
+func (x *BlocklistChange) Reset() {
+	*x = BlocklistChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlocklistChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlocklistChange) ProtoMessage() {}
+
+func (x *BlocklistChange) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlocklistChange.ProtoReflect.Descriptor instead.
+func (*BlocklistChange) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *BlocklistChange) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *BlocklistChange) GetEntry() *BlockedIp {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *BlocklistChange) GetPrevious() *BlockedIp {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *BlocklistChange) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ImportBlocklistResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// "success", "failed" if some changes could not be applied, or
+	// "dry_run".
+	Status    string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Added     int32  `protobuf:"varint,2,opt,name=added,proto3" json:"added,omitempty"`
+	Updated   int32  `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	Removed   int32  `protobuf:"varint,4,opt,name=removed,proto3" json:"removed,omitempty"`
+	Unchanged int32  `protobuf:"varint,5,opt,name=unchanged,proto3" json:"unchanged,omitempty"`
+	Skipped   int32  `protobuf:"varint,6,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// The changes, sorted by IP, at most 10000 of them.
+	Changes   []*BlocklistChange `protobuf:"bytes,7,rep,name=changes,proto3" json:"changes,omitempty"`
+	Truncated bool               `protobuf:"varint,8,opt,name=truncated,proto3" json:"truncated,omitempty"`
+}
+
+func (x *ImportBlocklistResponse) Reset() {
+	*x = ImportBlocklistResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dns_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportBlocklistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportBlocklistResponse) ProtoMessage() {}
+
+func (x *ImportBlocklistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dns_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportBlocklistResponse.ProtoReflect.Descriptor instead.
+func (*ImportBlocklistResponse) Descriptor() ([]byte, []int) {
+	return file_dns_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ImportBlocklistResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ImportBlocklistResponse) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *ImportBlocklistResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *ImportBlocklistResponse) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *ImportBlocklistResponse) GetUnchanged() int32 {
+	if x != nil {
+		return x.Unchanged
+	}
+	return 0
+}
+
+func (x *ImportBlocklistResponse) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ImportBlocklistResponse) GetChanges() []*BlocklistChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ImportBlocklistResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+var File_dns_proto protoreflect.FileDescriptor
+
+var file_dns_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x64, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x64, 0x6e, 0x73,
+	0x22, 0xd3, 0x03, 0x0a, 0x0a, 0x44, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x2d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73,
+	0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
 	0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x22, 0x25, 0x0a, 0x0b, 0x44, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x61, 0x6d, 0x70, 0x12, 0x2c, 0x0a, 0x05, 0x72, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x11, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x43, 0x6f, 0x64, 0x65, 0x48, 0x00, 0x52, 0x05, 0x72, 0x63, 0x6f, 0x64, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x25, 0x0a, 0x07, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x52,
+	0x07, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x2c, 0x0a, 0x09, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x64,
+	0x6e, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x09, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x72,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x2c, 0x0a, 0x12, 0x65,
+	0x64, 0x6e, 0x73, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x75, 0x62, 0x6e, 0x65,
+	0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x65, 0x64, 0x6e, 0x73, 0x43, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x53, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e,
+	0x73, 0x6f, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x49, 0x64, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x72, 0x63, 0x6f, 0x64, 0x65,
+	0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0x52, 0x0a, 0x06, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72,
+	0x12, 0x22, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e,
+	0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x22, 0x61, 0x0a, 0x0b, 0x44, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x04, 0x72, 0x75, 0x6c,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x22, 0x68, 0x0a,
+	0x0e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f, 0x73, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x74, 0x6c,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x29, 0x0a, 0x0f, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x22, 0x31, 0x0a, 0x10, 0x55, 0x6e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x70, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x2b, 0x0a, 0x11, 0x55, 0x6e, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x22, 0x17, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65,
+	0x64, 0x49, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x82, 0x01, 0x0a, 0x09,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69,
+	0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74,
+	0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x22, 0x49, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49,
+	0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x0b, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x69, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x52,
+	0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x73, 0x22, 0x2f, 0x0a, 0x0e, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x70, 0x0a, 0x0f,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x05, 0x65, 0x6e, 0x74, 0x72,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x52, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x11,
+	0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x90, 0x01, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x08, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65,
+	0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x47,
+	0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x18, 0x0a, 0x16, 0x54, 0x61, 0x69, 0x6c, 0x44, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x5c,
+	0x0a, 0x17, 0x54, 0x61, 0x69, 0x6c, 0x44, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x07, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x64, 0x6e, 0x73,
+	0x2e, 0x44, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x71, 0x0a, 0x0e,
+	0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22,
+	0x48, 0x0a, 0x18, 0x41, 0x64, 0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x5e, 0x0a, 0x19, 0x41, 0x64, 0x64,
+	0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x29,
+	0x0a, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x64, 0x6e, 0x73, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x33, 0x0a, 0x1b, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x36,
+	0x0a, 0x1c, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73,
+	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x16, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c,
+	0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x46,
+	0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x41,
+	0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0xe1, 0x01, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x75, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x25, 0x0a, 0x0e,
+	0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x5f, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x50, 0x61, 0x74, 0x74,
+	0x65, 0x72, 0x6e, 0x12, 0x2d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74,
+	0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xbc, 0x01, 0x0a, 0x13, 0x41,
+	0x64, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x6f,
+	0x6d, 0x61, 0x69, 0x6e, 0x5f, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x6e, 0x12, 0x2d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74,
+	0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x52, 0x0a, 0x14, 0x41, 0x64, 0x64,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x22, 0x0a, 0x04, 0x72, 0x75, 0x6c,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x22, 0x28, 0x0a,
+	0x16, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x31, 0x0a, 0x17, 0x52, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
 	0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x2f, 0x0a, 0x0e, 0x42, 0x6c,
-	0x6f, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x17, 0x0a, 0x15, 0x4c, 0x69,
+	0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x22, 0x3e, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a,
+	0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64,
+	0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x72, 0x75,
+	0x6c, 0x65, 0x73, 0x22, 0x7d, 0x0a, 0x15, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x56, 0x65,
+	0x72, 0x64, 0x69, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
 	0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x29, 0x0a, 0x0f, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16,
-	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x32, 0x77, 0x0a, 0x0a, 0x44, 0x6e, 0x73, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x12, 0x33, 0x0a, 0x0e, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x6e, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0f, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x44, 0x6e, 0x73,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x44, 0x6e,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x42, 0x6c, 0x6f,
-	0x63, 0x6b, 0x49, 0x70, 0x12, 0x13, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
-	0x49, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x64, 0x6e, 0x73, 0x2e,
-	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
-	0x06, 0x5a, 0x04, 0x2e, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64,
+	0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d,
+	0x61, 0x69, 0x6e, 0x12, 0x2d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79,
+	0x70, 0x65, 0x22, 0xd0, 0x01, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x22, 0x0a,
+	0x04, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x6e,
+	0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x75, 0x6c,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74,
+	0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x76, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x76, 0x65, 0x22, 0xdb, 0x01, 0x0a, 0x16, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69,
+	0x6e, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x2d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x64, 0x6e,
+	0x73, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x56, 0x65,
+	0x72, 0x64, 0x69, 0x63, 0x74, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63,
+	0x68, 0x65, 0x73, 0x22, 0x37, 0x0a, 0x12, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x73,
+	0x75, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0xc8, 0x01, 0x0a,
+	0x13, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x13, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2d, 0x0a,
+	0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49,
+	0x70, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x12, 0x22, 0x0a, 0x04,
+	0x72, 0x75, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73,
+	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65,
+	0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x46, 0x0a, 0x16, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x2c, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x14, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73,
+	0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x22,
+	0x2d, 0x0a, 0x17, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x98,
+	0x01, 0x0a, 0x16, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2c, 0x0a, 0x06, 0x66, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x64, 0x6e, 0x73, 0x2e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52,
+	0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x49, 0x6d, 0x70, 0x6f,
+	0x72, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x17, 0x0a, 0x07,
+	0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64,
+	0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x93, 0x01, 0x0a, 0x0f, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x65, 0x64, 0x49, 0x70, 0x52, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x2a, 0x0a, 0x08, 0x70,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x52, 0x08, 0x70,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69,
+	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x22,
+	0x81, 0x02, 0x0a, 0x17, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12, 0x1c, 0x0a,
+	0x09, 0x75, 0x6e, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x75, 0x6e, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x6b,
+	0x69, 0x70, 0x70, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73,
+	0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61,
+	0x74, 0x65, 0x64, 0x2a, 0xaf, 0x04, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x43, 0x6f, 0x64, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45,
+	0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4e, 0x4f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00, 0x12,
+	0x19, 0x0a, 0x15, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45,
+	0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x45, 0x52, 0x52, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x52, 0x45,
+	0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x45, 0x52, 0x56,
+	0x46, 0x41, 0x49, 0x4c, 0x10, 0x02, 0x12, 0x1a, 0x0a, 0x16, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e,
+	0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4e, 0x58, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e,
+	0x10, 0x03, 0x12, 0x18, 0x0a, 0x14, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43,
+	0x4f, 0x44, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x49, 0x4d, 0x50, 0x10, 0x04, 0x12, 0x19, 0x0a, 0x15,
+	0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x52, 0x45,
+	0x46, 0x55, 0x53, 0x45, 0x44, 0x10, 0x05, 0x12, 0x1a, 0x0a, 0x16, 0x52, 0x45, 0x53, 0x50, 0x4f,
+	0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x59, 0x58, 0x44, 0x4f, 0x4d, 0x41, 0x49,
+	0x4e, 0x10, 0x06, 0x12, 0x19, 0x0a, 0x15, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f,
+	0x43, 0x4f, 0x44, 0x45, 0x5f, 0x59, 0x58, 0x52, 0x52, 0x53, 0x45, 0x54, 0x10, 0x07, 0x12, 0x19,
+	0x0a, 0x15, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f,
+	0x4e, 0x58, 0x52, 0x52, 0x53, 0x45, 0x54, 0x10, 0x08, 0x12, 0x19, 0x0a, 0x15, 0x52, 0x45, 0x53,
+	0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x41, 0x55,
+	0x54, 0x48, 0x10, 0x09, 0x12, 0x19, 0x0a, 0x15, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45,
+	0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5a, 0x4f, 0x4e, 0x45, 0x10, 0x0a, 0x12,
+	0x1b, 0x0a, 0x17, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45,
+	0x5f, 0x44, 0x53, 0x4f, 0x54, 0x59, 0x50, 0x45, 0x4e, 0x49, 0x10, 0x0b, 0x12, 0x19, 0x0a, 0x15,
+	0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41,
+	0x44, 0x56, 0x45, 0x52, 0x53, 0x10, 0x10, 0x12, 0x18, 0x0a, 0x14, 0x52, 0x45, 0x53, 0x50, 0x4f,
+	0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41, 0x44, 0x4b, 0x45, 0x59, 0x10,
+	0x11, 0x12, 0x19, 0x0a, 0x15, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f,
+	0x44, 0x45, 0x5f, 0x42, 0x41, 0x44, 0x54, 0x49, 0x4d, 0x45, 0x10, 0x12, 0x12, 0x19, 0x0a, 0x15,
+	0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41,
+	0x44, 0x4d, 0x4f, 0x44, 0x45, 0x10, 0x13, 0x12, 0x19, 0x0a, 0x15, 0x52, 0x45, 0x53, 0x50, 0x4f,
+	0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41, 0x44, 0x4e, 0x41, 0x4d, 0x45,
+	0x10, 0x14, 0x12, 0x18, 0x0a, 0x14, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43,
+	0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41, 0x44, 0x41, 0x4c, 0x47, 0x10, 0x15, 0x12, 0x1a, 0x0a, 0x16,
+	0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41,
+	0x44, 0x54, 0x52, 0x55, 0x4e, 0x43, 0x10, 0x16, 0x12, 0x1b, 0x0a, 0x17, 0x52, 0x45, 0x53, 0x50,
+	0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41, 0x44, 0x43, 0x4f, 0x4f,
+	0x4b, 0x49, 0x45, 0x10, 0x17, 0x2a, 0x81, 0x01, 0x0a, 0x09, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70,
+	0x6f, 0x72, 0x74, 0x12, 0x15, 0x0a, 0x11, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52, 0x54,
+	0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x54, 0x52,
+	0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x55, 0x44, 0x50, 0x10, 0x01, 0x12, 0x11, 0x0a,
+	0x0d, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x54, 0x43, 0x50, 0x10, 0x02,
+	0x12, 0x11, 0x0a, 0x0d, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x44, 0x4f,
+	0x54, 0x10, 0x03, 0x12, 0x11, 0x0a, 0x0d, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52, 0x54,
+	0x5f, 0x44, 0x4f, 0x48, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50,
+	0x4f, 0x52, 0x54, 0x5f, 0x44, 0x4f, 0x51, 0x10, 0x05, 0x2a, 0x86, 0x10, 0x0a, 0x09, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12,
+	0x10, 0x0a, 0x0c, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x10,
+	0x01, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x4e, 0x53, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x4d, 0x44, 0x10, 0x03, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d, 0x46, 0x10, 0x04, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55,
+	0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x05,
+	0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53,
+	0x4f, 0x41, 0x10, 0x06, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x4d, 0x42, 0x10, 0x07, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d, 0x47, 0x10, 0x08, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55,
+	0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d, 0x52, 0x10, 0x09, 0x12, 0x13, 0x0a,
+	0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x55, 0x4c, 0x4c,
+	0x10, 0x0a, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x57, 0x4b, 0x53, 0x10, 0x0b, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x54, 0x52, 0x10, 0x0c, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55,
+	0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x48, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x0d,
+	0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d,
+	0x49, 0x4e, 0x46, 0x4f, 0x10, 0x0e, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d, 0x58, 0x10, 0x0f, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54, 0x58, 0x54, 0x10, 0x10, 0x12, 0x11, 0x0a,
+	0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x50, 0x10, 0x11,
+	0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41,
+	0x46, 0x53, 0x44, 0x42, 0x10, 0x12, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x58, 0x32, 0x35, 0x10, 0x13, 0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55,
+	0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x53, 0x44, 0x4e, 0x10, 0x14, 0x12,
+	0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x54,
+	0x10, 0x15, 0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x4e, 0x53, 0x41, 0x50, 0x10, 0x16, 0x12, 0x17, 0x0a, 0x13, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x53, 0x41, 0x50, 0x5f, 0x50, 0x54, 0x52, 0x10, 0x17,
+	0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53,
+	0x49, 0x47, 0x10, 0x18, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x4b, 0x45, 0x59, 0x10, 0x19, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52,
+	0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x58, 0x10, 0x1a, 0x12, 0x13, 0x0a, 0x0f, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x47, 0x50, 0x4f, 0x53, 0x10, 0x1b,
+	0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41,
+	0x41, 0x41, 0x41, 0x10, 0x1c, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x4c, 0x4f, 0x43, 0x10, 0x1d, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x58, 0x54, 0x10, 0x1e, 0x12, 0x12, 0x0a,
+	0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x49, 0x44, 0x10,
+	0x1f, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x4e, 0x49, 0x4d, 0x4c, 0x4f, 0x43, 0x10, 0x20, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52,
+	0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x52, 0x56, 0x10, 0x21, 0x12, 0x13, 0x0a, 0x0f,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x54, 0x4d, 0x41, 0x10,
+	0x22, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x4e, 0x41, 0x50, 0x54, 0x52, 0x10, 0x23, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4b, 0x58, 0x10, 0x24, 0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55,
+	0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x45, 0x52, 0x54, 0x10, 0x25, 0x12,
+	0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x36,
+	0x10, 0x26, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x44, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x27, 0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52,
+	0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x49, 0x4e, 0x4b, 0x10, 0x28, 0x12, 0x12, 0x0a,
+	0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4f, 0x50, 0x54, 0x10,
+	0x29, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x41, 0x50, 0x4c, 0x10, 0x2a, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x44, 0x53, 0x10, 0x2b, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52,
+	0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x53, 0x48, 0x46, 0x50, 0x10, 0x2c, 0x12, 0x17,
+	0x0a, 0x13, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x50, 0x53,
+	0x45, 0x43, 0x4b, 0x45, 0x59, 0x10, 0x2d, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x52, 0x53, 0x49, 0x47, 0x10, 0x2e, 0x12, 0x13, 0x0a,
+	0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x53, 0x45, 0x43,
+	0x10, 0x2f, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x44, 0x4e, 0x53, 0x4b, 0x45, 0x59, 0x10, 0x30, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x48, 0x43, 0x49, 0x44, 0x10, 0x31, 0x12,
+	0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x53,
+	0x45, 0x43, 0x33, 0x10, 0x32, 0x12, 0x19, 0x0a, 0x15, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x4e, 0x53, 0x45, 0x43, 0x33, 0x50, 0x41, 0x52, 0x41, 0x4d, 0x10, 0x33,
+	0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54,
+	0x4c, 0x53, 0x41, 0x10, 0x34, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x53, 0x4d, 0x49, 0x4d, 0x45, 0x41, 0x10, 0x35, 0x12, 0x12, 0x0a, 0x0e,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x48, 0x49, 0x50, 0x10, 0x37,
+	0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e,
+	0x49, 0x4e, 0x46, 0x4f, 0x10, 0x38, 0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x4b, 0x45, 0x59, 0x10, 0x39, 0x12, 0x15, 0x0a, 0x11, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54, 0x41, 0x4c, 0x49, 0x4e, 0x4b,
+	0x10, 0x3a, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x43, 0x44, 0x53, 0x10, 0x3b, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x44, 0x4e, 0x53, 0x4b, 0x45, 0x59, 0x10, 0x3c, 0x12, 0x19,
+	0x0a, 0x15, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4f, 0x50, 0x45,
+	0x4e, 0x50, 0x47, 0x50, 0x4b, 0x45, 0x59, 0x10, 0x3d, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x53, 0x59, 0x4e, 0x43, 0x10, 0x3e, 0x12,
+	0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x5a, 0x4f,
+	0x4e, 0x45, 0x4d, 0x44, 0x10, 0x3f, 0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x56, 0x43, 0x42, 0x10, 0x40, 0x12, 0x14, 0x0a, 0x10, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x48, 0x54, 0x54, 0x50, 0x53, 0x10,
+	0x41, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x44, 0x53, 0x59, 0x4e, 0x43, 0x10, 0x42, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x50, 0x46, 0x10, 0x63, 0x12, 0x14, 0x0a, 0x10, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x49, 0x4e, 0x46, 0x4f, 0x10,
+	0x64, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x55, 0x49, 0x44, 0x10, 0x65, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x47, 0x49, 0x44, 0x10, 0x66, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x10, 0x67,
+	0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e,
+	0x49, 0x44, 0x10, 0x68, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x4c, 0x33, 0x32, 0x10, 0x69, 0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52,
+	0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4c, 0x36, 0x34, 0x10, 0x6a, 0x12, 0x11, 0x0a, 0x0d,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4c, 0x50, 0x10, 0x6b, 0x12,
+	0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x55,
+	0x49, 0x34, 0x38, 0x10, 0x6c, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x45, 0x55, 0x49, 0x36, 0x34, 0x10, 0x6d, 0x12, 0x16, 0x0a, 0x11, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x58, 0x4e, 0x41, 0x4d, 0x45,
+	0x10, 0x80, 0x01, 0x12, 0x14, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x54, 0x4b, 0x45, 0x59, 0x10, 0xf9, 0x01, 0x12, 0x14, 0x0a, 0x0f, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54, 0x53, 0x49, 0x47, 0x10, 0xfa, 0x01, 0x12,
+	0x14, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x58,
+	0x46, 0x52, 0x10, 0xfb, 0x01, 0x12, 0x14, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x41, 0x58, 0x46, 0x52, 0x10, 0xfc, 0x01, 0x12, 0x15, 0x0a, 0x10, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d, 0x41, 0x49, 0x4c, 0x42, 0x10,
+	0xfd, 0x01, 0x12, 0x15, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x4d, 0x41, 0x49, 0x4c, 0x41, 0x10, 0xfe, 0x01, 0x12, 0x13, 0x0a, 0x0e, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x4e, 0x59, 0x10, 0xff, 0x01, 0x12, 0x13,
+	0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x52, 0x49,
+	0x10, 0x80, 0x02, 0x12, 0x13, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x43, 0x41, 0x41, 0x10, 0x81, 0x02, 0x12, 0x13, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52,
+	0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x56, 0x43, 0x10, 0x82, 0x02, 0x12, 0x13, 0x0a,
+	0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x4f, 0x41, 0x10,
+	0x83, 0x02, 0x12, 0x18, 0x0a, 0x13, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x41, 0x4d, 0x54, 0x52, 0x45, 0x4c, 0x41, 0x59, 0x10, 0x84, 0x02, 0x12, 0x17, 0x0a, 0x12,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x53, 0x49, 0x4e,
+	0x46, 0x4f, 0x10, 0x85, 0x02, 0x12, 0x16, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x57, 0x41, 0x4c, 0x4c, 0x45, 0x54, 0x10, 0x86, 0x02, 0x12, 0x13, 0x0a,
+	0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x4c, 0x41, 0x10,
+	0x87, 0x02, 0x12, 0x13, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x49, 0x50, 0x4e, 0x10, 0x88, 0x02, 0x12, 0x13, 0x0a, 0x0d, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54, 0x41, 0x10, 0x80, 0x80, 0x02, 0x12, 0x14, 0x0a, 0x0e,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x4c, 0x56, 0x10, 0x81,
+	0x80, 0x02, 0x2a, 0xc8, 0x01, 0x0a, 0x0e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x18, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x45,
+	0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57,
+	0x4e, 0x10, 0x00, 0x12, 0x1d, 0x0a, 0x19, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x45, 0x56, 0x45,
+	0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54,
+	0x10, 0x01, 0x12, 0x21, 0x0a, 0x1d, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x45, 0x56, 0x45, 0x4e,
+	0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54, 0x5f,
+	0x45, 0x4e, 0x44, 0x10, 0x02, 0x12, 0x1a, 0x0a, 0x16, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x45,
+	0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x44, 0x44, 0x45, 0x44, 0x10,
+	0x03, 0x12, 0x1c, 0x0a, 0x18, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x4d, 0x4f, 0x56, 0x45, 0x44, 0x10, 0x04, 0x12,
+	0x1c, 0x0a, 0x18, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x45, 0x58, 0x50, 0x49, 0x52, 0x45, 0x44, 0x10, 0x05, 0x2a, 0x47, 0x0a,
+	0x0f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x12, 0x1a, 0x0a, 0x16, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x4c, 0x49, 0x53, 0x54, 0x5f, 0x46, 0x4f,
+	0x52, 0x4d, 0x41, 0x54, 0x5f, 0x4a, 0x53, 0x4f, 0x4e, 0x4c, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14,
+	0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x4c, 0x49, 0x53, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54,
+	0x5f, 0x43, 0x53, 0x56, 0x10, 0x01, 0x2a, 0x3c, 0x0a, 0x0a, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x49, 0x4d, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4d,
+	0x4f, 0x44, 0x45, 0x5f, 0x4d, 0x45, 0x52, 0x47, 0x45, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x49,
+	0x4d, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x52, 0x45, 0x50, 0x4c, 0x41,
+	0x43, 0x45, 0x10, 0x01, 0x32, 0xc3, 0x09, 0x0a, 0x0a, 0x44, 0x6e, 0x73, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x33, 0x0a, 0x0e, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x6e, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0f, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x44, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x44, 0x6e, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x49, 0x70, 0x12, 0x13, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49,
+	0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a,
+	0x0a, 0x09, 0x55, 0x6e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x70, 0x12, 0x15, 0x2e, 0x64, 0x6e,
+	0x73, 0x2e, 0x55, 0x6e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x55, 0x6e, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0e, 0x4c, 0x69,
+	0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x73, 0x12, 0x1a, 0x2e, 0x64,
+	0x6e, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x70, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x70,
+	0x12, 0x13, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x70, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x49, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x08, 0x47,
+	0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x14, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x47, 0x65,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x64, 0x6e, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0f, 0x54, 0x61, 0x69, 0x6c, 0x44, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x1b, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x54, 0x61,
+	0x69, 0x6c, 0x44, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x54, 0x61, 0x69, 0x6c, 0x44,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x30, 0x01, 0x12, 0x52, 0x0a, 0x11, 0x41, 0x64, 0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x77,
+	0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1d, 0x2e, 0x64, 0x6e, 0x73, 0x2e,
+	0x41, 0x64, 0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x41,
+	0x64, 0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5b, 0x0a, 0x14, 0x52, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x20, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x41, 0x6c, 0x6c,
+	0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x21, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x41,
+	0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x6c,
+	0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x19, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x6c, 0x6f,
+	0x77, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a,
+	0x0c, 0x41, 0x64, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x18, 0x2e,
+	0x64, 0x6e, 0x73, 0x2e, 0x41, 0x64, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x41, 0x64,
+	0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x4c, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x1b, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x49, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75, 0x6c,
+	0x65, 0x73, 0x12, 0x1a, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b,
+	0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x75,
+	0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0e, 0x45,
+	0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x12, 0x1a, 0x2e,
+	0x64, 0x6e, 0x73, 0x2e, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x56, 0x65, 0x72, 0x64, 0x69,
+	0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x64, 0x6e, 0x73, 0x2e,
+	0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x0b, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x17, 0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
+	0x2e, 0x64, 0x6e, 0x73, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4e, 0x0a, 0x0f, 0x45, 0x78,
+	0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1b, 0x2e,
+	0x64, 0x6e, 0x73, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x6e, 0x73,
+	0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4e, 0x0a, 0x0f, 0x49, 0x6d,
+	0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1b, 0x2e,
+	0x64, 0x6e, 0x73, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x6e, 0x73,
+	0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x2f,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -275,23 +3674,126 @@ func file_dns_proto_rawDescGZIP() []byte {
 	return file_dns_proto_rawDescData
 }
 
-var file_dns_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_dns_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_dns_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_dns_proto_goTypes = []any{
-	(*DnsRequest)(nil),      // 0: dns.DnsRequest
-	(*DnsResponse)(nil),     // 1: dns.DnsResponse
-	(*BlockIpRequest)(nil),  // 2: dns.BlockIpRequest
-	(*BlockIpResponse)(nil), // 3: dns.BlockIpResponse
+	(ResponseCode)(0),                    // 0: dns.ResponseCode
+	(Transport)(0),                       // 1: dns.Transport
+	(QueryType)(0),                       // 2: dns.QueryType
+	(BlockEventType)(0),                  // 3: dns.BlockEventType
+	(BlocklistFormat)(0),                 // 4: dns.BlocklistFormat
+	(ImportMode)(0),                      // 5: dns.ImportMode
+	(*DnsRequest)(nil),                   // 6: dns.DnsRequest
+	(*Answer)(nil),                       // 7: dns.Answer
+	(*DnsResponse)(nil),                  // 8: dns.DnsResponse
+	(*BlockIpRequest)(nil),               // 9: dns.BlockIpRequest
+	(*BlockIpResponse)(nil),              // 10: dns.BlockIpResponse
+	(*UnblockIpRequest)(nil),             // 11: dns.UnblockIpRequest
+	(*UnblockIpResponse)(nil),            // 12: dns.UnblockIpResponse
+	(*ListBlockedIpsRequest)(nil),        // 13: dns.ListBlockedIpsRequest
+	(*BlockedIp)(nil),                    // 14: dns.BlockedIp
+	(*ListBlockedIpsResponse)(nil),       // 15: dns.ListBlockedIpsResponse
+	(*CheckIpRequest)(nil),               // 16: dns.CheckIpRequest
+	(*CheckIpResponse)(nil),              // 17: dns.CheckIpResponse
+	(*GetStatsRequest)(nil),              // 18: dns.GetStatsRequest
+	(*GetStatsResponse)(nil),             // 19: dns.GetStatsResponse
+	(*TailDnsRequestsRequest)(nil),       // 20: dns.TailDnsRequestsRequest
+	(*TailDnsRequestsResponse)(nil),      // 21: dns.TailDnsRequestsResponse
+	(*AllowlistEntry)(nil),               // 22: dns.AllowlistEntry
+	(*AddAllowlistEntryRequest)(nil),     // 23: dns.AddAllowlistEntryRequest
+	(*AddAllowlistEntryResponse)(nil),    // 24: dns.AddAllowlistEntryResponse
+	(*RemoveAllowlistEntryRequest)(nil),  // 25: dns.RemoveAllowlistEntryRequest
+	(*RemoveAllowlistEntryResponse)(nil), // 26: dns.RemoveAllowlistEntryResponse
+	(*ListAllowlistRequest)(nil),         // 27: dns.ListAllowlistRequest
+	(*ListAllowlistResponse)(nil),        // 28: dns.ListAllowlistResponse
+	(*BlockRule)(nil),                    // 29: dns.BlockRule
+	(*AddBlockRuleRequest)(nil),          // 30: dns.AddBlockRuleRequest
+	(*AddBlockRuleResponse)(nil),         // 31: dns.AddBlockRuleResponse
+	(*RemoveBlockRuleRequest)(nil),       // 32: dns.RemoveBlockRuleRequest
+	(*RemoveBlockRuleResponse)(nil),      // 33: dns.RemoveBlockRuleResponse
+	(*ListBlockRulesRequest)(nil),        // 34: dns.ListBlockRulesRequest
+	(*ListBlockRulesResponse)(nil),       // 35: dns.ListBlockRulesResponse
+	(*ExplainVerdictRequest)(nil),        // 36: dns.ExplainVerdictRequest
+	(*VerdictMatch)(nil),                 // 37: dns.VerdictMatch
+	(*ExplainVerdictResponse)(nil),       // 38: dns.ExplainVerdictResponse
+	(*WatchBlocksRequest)(nil),           // 39: dns.WatchBlocksRequest
+	(*WatchBlocksResponse)(nil),          // 40: dns.WatchBlocksResponse
+	(*ExportBlocklistRequest)(nil),       // 41: dns.ExportBlocklistRequest
+	(*ExportBlocklistResponse)(nil),      // 42: dns.ExportBlocklistResponse
+	(*ImportBlocklistRequest)(nil),       // 43: dns.ImportBlocklistRequest
+	(*BlocklistChange)(nil),              // 44: dns.BlocklistChange
+	(*ImportBlocklistResponse)(nil),      // 45: dns.ImportBlocklistResponse
+	nil,                                  // 46: dns.GetStatsResponse.CountersEntry
 }
 var file_dns_proto_depIdxs = []int32{
-	0, // 0: dns.DnsService.SendDnsRequest:input_type -> dns.DnsRequest
-	2, // 1: dns.DnsService.BlockIp:input_type -> dns.BlockIpRequest
-	1, // 2: dns.DnsService.SendDnsRequest:output_type -> dns.DnsResponse
-	3, // 3: dns.DnsService.BlockIp:output_type -> dns.BlockIpResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: dns.DnsRequest.query_type:type_name -> dns.QueryType
+	0,  // 1: dns.DnsRequest.rcode:type_name -> dns.ResponseCode
+	7,  // 2: dns.DnsRequest.answers:type_name -> dns.Answer
+	1,  // 3: dns.DnsRequest.transport:type_name -> dns.Transport
+	2,  // 4: dns.Answer.type:type_name -> dns.QueryType
+	29, // 5: dns.DnsResponse.rule:type_name -> dns.BlockRule
+	14, // 6: dns.ListBlockedIpsResponse.blocked_ips:type_name -> dns.BlockedIp
+	14, // 7: dns.CheckIpResponse.entry:type_name -> dns.BlockedIp
+	46, // 8: dns.GetStatsResponse.counters:type_name -> dns.GetStatsResponse.CountersEntry
+	6,  // 9: dns.TailDnsRequestsResponse.request:type_name -> dns.DnsRequest
+	22, // 10: dns.AddAllowlistEntryResponse.entry:type_name -> dns.AllowlistEntry
+	22, // 11: dns.ListAllowlistResponse.entries:type_name -> dns.AllowlistEntry
+	2,  // 12: dns.BlockRule.query_type:type_name -> dns.QueryType
+	2,  // 13: dns.AddBlockRuleRequest.query_type:type_name -> dns.QueryType
+	29, // 14: dns.AddBlockRuleResponse.rule:type_name -> dns.BlockRule
+	29, // 15: dns.ListBlockRulesResponse.rules:type_name -> dns.BlockRule
+	2,  // 16: dns.ExplainVerdictRequest.query_type:type_name -> dns.QueryType
+	29, // 17: dns.VerdictMatch.rule:type_name -> dns.BlockRule
+	2,  // 18: dns.ExplainVerdictResponse.query_type:type_name -> dns.QueryType
+	37, // 19: dns.ExplainVerdictResponse.matches:type_name -> dns.VerdictMatch
+	3,  // 20: dns.WatchBlocksResponse.type:type_name -> dns.BlockEventType
+	14, // 21: dns.WatchBlocksResponse.blocked_ip:type_name -> dns.BlockedIp
+	29, // 22: dns.WatchBlocksResponse.rule:type_name -> dns.BlockRule
+	4,  // 23: dns.ExportBlocklistRequest.format:type_name -> dns.BlocklistFormat
+	4,  // 24: dns.ImportBlocklistRequest.format:type_name -> dns.BlocklistFormat
+	5,  // 25: dns.ImportBlocklistRequest.mode:type_name -> dns.ImportMode
+	14, // 26: dns.BlocklistChange.entry:type_name -> dns.BlockedIp
+	14, // 27: dns.BlocklistChange.previous:type_name -> dns.BlockedIp
+	44, // 28: dns.ImportBlocklistResponse.changes:type_name -> dns.BlocklistChange
+	6,  // 29: dns.DnsService.SendDnsRequest:input_type -> dns.DnsRequest
+	9,  // 30: dns.DnsService.BlockIp:input_type -> dns.BlockIpRequest
+	11, // 31: dns.DnsService.UnblockIp:input_type -> dns.UnblockIpRequest
+	13, // 32: dns.DnsService.ListBlockedIps:input_type -> dns.ListBlockedIpsRequest
+	16, // 33: dns.DnsService.CheckIp:input_type -> dns.CheckIpRequest
+	18, // 34: dns.DnsService.GetStats:input_type -> dns.GetStatsRequest
+	20, // 35: dns.DnsService.TailDnsRequests:input_type -> dns.TailDnsRequestsRequest
+	23, // 36: dns.DnsService.AddAllowlistEntry:input_type -> dns.AddAllowlistEntryRequest
+	25, // 37: dns.DnsService.RemoveAllowlistEntry:input_type -> dns.RemoveAllowlistEntryRequest
+	27, // 38: dns.DnsService.ListAllowlist:input_type -> dns.ListAllowlistRequest
+	30, // 39: dns.DnsService.AddBlockRule:input_type -> dns.AddBlockRuleRequest
+	32, // 40: dns.DnsService.RemoveBlockRule:input_type -> dns.RemoveBlockRuleRequest
+	34, // 41: dns.DnsService.ListBlockRules:input_type -> dns.ListBlockRulesRequest
+	36, // 42: dns.DnsService.ExplainVerdict:input_type -> dns.ExplainVerdictRequest
+	39, // 43: dns.DnsService.WatchBlocks:input_type -> dns.WatchBlocksRequest
+	41, // 44: dns.DnsService.ExportBlocklist:input_type -> dns.ExportBlocklistRequest
+	43, // 45: dns.DnsService.ImportBlocklist:input_type -> dns.ImportBlocklistRequest
+	8,  // 46: dns.DnsService.SendDnsRequest:output_type -> dns.DnsResponse
+	10, // 47: dns.DnsService.BlockIp:output_type -> dns.BlockIpResponse
+	12, // 48: dns.DnsService.UnblockIp:output_type -> dns.UnblockIpResponse
+	15, // 49: dns.DnsService.ListBlockedIps:output_type -> dns.ListBlockedIpsResponse
+	17, // 50: dns.DnsService.CheckIp:output_type -> dns.CheckIpResponse
+	19, // 51: dns.DnsService.GetStats:output_type -> dns.GetStatsResponse
+	21, // 52: dns.DnsService.TailDnsRequests:output_type -> dns.TailDnsRequestsResponse
+	24, // 53: dns.DnsService.AddAllowlistEntry:output_type -> dns.AddAllowlistEntryResponse
+	26, // 54: dns.DnsService.RemoveAllowlistEntry:output_type -> dns.RemoveAllowlistEntryResponse
+	28, // 55: dns.DnsService.ListAllowlist:output_type -> dns.ListAllowlistResponse
+	31, // 56: dns.DnsService.AddBlockRule:output_type -> dns.AddBlockRuleResponse
+	33, // 57: dns.DnsService.RemoveBlockRule:output_type -> dns.RemoveBlockRuleResponse
+	35, // 58: dns.DnsService.ListBlockRules:output_type -> dns.ListBlockRulesResponse
+	38, // 59: dns.DnsService.ExplainVerdict:output_type -> dns.ExplainVerdictResponse
+	40, // 60: dns.DnsService.WatchBlocks:output_type -> dns.WatchBlocksResponse
+	42, // 61: dns.DnsService.ExportBlocklist:output_type -> dns.ExportBlocklistResponse
+	45, // 62: dns.DnsService.ImportBlocklist:output_type -> dns.ImportBlocklistResponse
+	46, // [46:63] is the sub-list for method output_type
+	29, // [29:46] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_dns_proto_init() }
@@ -312,8 +3814,140 @@ func file_dns_proto_init() {
 				return nil
 			}
 		}
-		file_dns_proto_msgTypes[1].Exporter = func(v any, i int) any {
-			switch v := v.(*DnsResponse); i {
+		file_dns_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Answer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*DnsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*BlockIpRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*BlockIpResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*UnblockIpRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*UnblockIpResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ListBlockedIpsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*BlockedIp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ListBlockedIpsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckIpRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckIpResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*GetStatsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -324,8 +3958,8 @@ func file_dns_proto_init() {
 				return nil
 			}
 		}
-		file_dns_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch v := v.(*BlockIpRequest); i {
+		file_dns_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*GetStatsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -336,8 +3970,308 @@ func file_dns_proto_init() {
 				return nil
 			}
 		}
-		file_dns_proto_msgTypes[3].Exporter = func(v any, i int) any {
-			switch v := v.(*BlockIpResponse); i {
+		file_dns_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*TailDnsRequestsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*TailDnsRequestsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*AllowlistEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*AddAllowlistEntryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*AddAllowlistEntryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*RemoveAllowlistEntryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*RemoveAllowlistEntryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAllowlistRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAllowlistResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*BlockRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[24].Exporter = func(v any, i int) any {
+			switch v := v.(*AddBlockRuleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[25].Exporter = func(v any, i int) any {
+			switch v := v.(*AddBlockRuleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[26].Exporter = func(v any, i int) any {
+			switch v := v.(*RemoveBlockRuleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[27].Exporter = func(v any, i int) any {
+			switch v := v.(*RemoveBlockRuleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[28].Exporter = func(v any, i int) any {
+			switch v := v.(*ListBlockRulesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[29].Exporter = func(v any, i int) any {
+			switch v := v.(*ListBlockRulesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[30].Exporter = func(v any, i int) any {
+			switch v := v.(*ExplainVerdictRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[31].Exporter = func(v any, i int) any {
+			switch v := v.(*VerdictMatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[32].Exporter = func(v any, i int) any {
+			switch v := v.(*ExplainVerdictResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[33].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchBlocksRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[34].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchBlocksResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[35].Exporter = func(v any, i int) any {
+			switch v := v.(*ExportBlocklistRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[36].Exporter = func(v any, i int) any {
+			switch v := v.(*ExportBlocklistResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[37].Exporter = func(v any, i int) any {
+			switch v := v.(*ImportBlocklistRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[38].Exporter = func(v any, i int) any {
+			switch v := v.(*BlocklistChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dns_proto_msgTypes[39].Exporter = func(v any, i int) any {
+			switch v := v.(*ImportBlocklistResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -349,18 +4283,20 @@ func file_dns_proto_init() {
 			}
 		}
 	}
+	file_dns_proto_msgTypes[0].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_dns_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      6,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_dns_proto_goTypes,
 		DependencyIndexes: file_dns_proto_depIdxs,
+		EnumInfos:         file_dns_proto_enumTypes,
 		MessageInfos:      file_dns_proto_msgTypes,
 	}.Build()
 	File_dns_proto = out.File
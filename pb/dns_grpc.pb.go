@@ -20,6 +20,21 @@ const _ = grpc.SupportPackageIsVersion7
 type DnsServiceClient interface {
 	SendDnsRequest(ctx context.Context, in *DnsRequest, opts ...grpc.CallOption) (*DnsResponse, error)
 	BlockIp(ctx context.Context, in *BlockIpRequest, opts ...grpc.CallOption) (*BlockIpResponse, error)
+	UnblockIp(ctx context.Context, in *UnblockIpRequest, opts ...grpc.CallOption) (*UnblockIpResponse, error)
+	ListBlockedIps(ctx context.Context, in *ListBlockedIpsRequest, opts ...grpc.CallOption) (*ListBlockedIpsResponse, error)
+	CheckIp(ctx context.Context, in *CheckIpRequest, opts ...grpc.CallOption) (*CheckIpResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	TailDnsRequests(ctx context.Context, in *TailDnsRequestsRequest, opts ...grpc.CallOption) (DnsService_TailDnsRequestsClient, error)
+	AddAllowlistEntry(ctx context.Context, in *AddAllowlistEntryRequest, opts ...grpc.CallOption) (*AddAllowlistEntryResponse, error)
+	RemoveAllowlistEntry(ctx context.Context, in *RemoveAllowlistEntryRequest, opts ...grpc.CallOption) (*RemoveAllowlistEntryResponse, error)
+	ListAllowlist(ctx context.Context, in *ListAllowlistRequest, opts ...grpc.CallOption) (*ListAllowlistResponse, error)
+	AddBlockRule(ctx context.Context, in *AddBlockRuleRequest, opts ...grpc.CallOption) (*AddBlockRuleResponse, error)
+	RemoveBlockRule(ctx context.Context, in *RemoveBlockRuleRequest, opts ...grpc.CallOption) (*RemoveBlockRuleResponse, error)
+	ListBlockRules(ctx context.Context, in *ListBlockRulesRequest, opts ...grpc.CallOption) (*ListBlockRulesResponse, error)
+	ExplainVerdict(ctx context.Context, in *ExplainVerdictRequest, opts ...grpc.CallOption) (*ExplainVerdictResponse, error)
+	WatchBlocks(ctx context.Context, in *WatchBlocksRequest, opts ...grpc.CallOption) (DnsService_WatchBlocksClient, error)
+	ExportBlocklist(ctx context.Context, in *ExportBlocklistRequest, opts ...grpc.CallOption) (DnsService_ExportBlocklistClient, error)
+	ImportBlocklist(ctx context.Context, opts ...grpc.CallOption) (DnsService_ImportBlocklistClient, error)
 }
 
 type dnsServiceClient struct {
@@ -48,12 +63,256 @@ func (c *dnsServiceClient) BlockIp(ctx context.Context, in *BlockIpRequest, opts
 	return out, nil
 }
 
+func (c *dnsServiceClient) UnblockIp(ctx context.Context, in *UnblockIpRequest, opts ...grpc.CallOption) (*UnblockIpResponse, error) {
+	out := new(UnblockIpResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/UnblockIp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) ListBlockedIps(ctx context.Context, in *ListBlockedIpsRequest, opts ...grpc.CallOption) (*ListBlockedIpsResponse, error) {
+	out := new(ListBlockedIpsResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/ListBlockedIps", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) CheckIp(ctx context.Context, in *CheckIpRequest, opts ...grpc.CallOption) (*CheckIpResponse, error) {
+	out := new(CheckIpResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/CheckIp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/GetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) TailDnsRequests(ctx context.Context, in *TailDnsRequestsRequest, opts ...grpc.CallOption) (DnsService_TailDnsRequestsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DnsService_ServiceDesc.Streams[0], "/dns.DnsService/TailDnsRequests", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dnsServiceTailDnsRequestsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DnsService_TailDnsRequestsClient interface {
+	Recv() (*TailDnsRequestsResponse, error)
+	grpc.ClientStream
+}
+
+type dnsServiceTailDnsRequestsClient struct {
+	grpc.ClientStream
+}
+
+func (x *dnsServiceTailDnsRequestsClient) Recv() (*TailDnsRequestsResponse, error) {
+	m := new(TailDnsRequestsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dnsServiceClient) AddAllowlistEntry(ctx context.Context, in *AddAllowlistEntryRequest, opts ...grpc.CallOption) (*AddAllowlistEntryResponse, error) {
+	out := new(AddAllowlistEntryResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/AddAllowlistEntry", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) RemoveAllowlistEntry(ctx context.Context, in *RemoveAllowlistEntryRequest, opts ...grpc.CallOption) (*RemoveAllowlistEntryResponse, error) {
+	out := new(RemoveAllowlistEntryResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/RemoveAllowlistEntry", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) ListAllowlist(ctx context.Context, in *ListAllowlistRequest, opts ...grpc.CallOption) (*ListAllowlistResponse, error) {
+	out := new(ListAllowlistResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/ListAllowlist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) AddBlockRule(ctx context.Context, in *AddBlockRuleRequest, opts ...grpc.CallOption) (*AddBlockRuleResponse, error) {
+	out := new(AddBlockRuleResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/AddBlockRule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) RemoveBlockRule(ctx context.Context, in *RemoveBlockRuleRequest, opts ...grpc.CallOption) (*RemoveBlockRuleResponse, error) {
+	out := new(RemoveBlockRuleResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/RemoveBlockRule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) ListBlockRules(ctx context.Context, in *ListBlockRulesRequest, opts ...grpc.CallOption) (*ListBlockRulesResponse, error) {
+	out := new(ListBlockRulesResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/ListBlockRules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) ExplainVerdict(ctx context.Context, in *ExplainVerdictRequest, opts ...grpc.CallOption) (*ExplainVerdictResponse, error) {
+	out := new(ExplainVerdictResponse)
+	err := c.cc.Invoke(ctx, "/dns.DnsService/ExplainVerdict", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dnsServiceClient) WatchBlocks(ctx context.Context, in *WatchBlocksRequest, opts ...grpc.CallOption) (DnsService_WatchBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DnsService_ServiceDesc.Streams[1], "/dns.DnsService/WatchBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dnsServiceWatchBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DnsService_WatchBlocksClient interface {
+	Recv() (*WatchBlocksResponse, error)
+	grpc.ClientStream
+}
+
+type dnsServiceWatchBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *dnsServiceWatchBlocksClient) Recv() (*WatchBlocksResponse, error) {
+	m := new(WatchBlocksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dnsServiceClient) ExportBlocklist(ctx context.Context, in *ExportBlocklistRequest, opts ...grpc.CallOption) (DnsService_ExportBlocklistClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DnsService_ServiceDesc.Streams[2], "/dns.DnsService/ExportBlocklist", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dnsServiceExportBlocklistClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DnsService_ExportBlocklistClient interface {
+	Recv() (*ExportBlocklistResponse, error)
+	grpc.ClientStream
+}
+
+type dnsServiceExportBlocklistClient struct {
+	grpc.ClientStream
+}
+
+func (x *dnsServiceExportBlocklistClient) Recv() (*ExportBlocklistResponse, error) {
+	m := new(ExportBlocklistResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dnsServiceClient) ImportBlocklist(ctx context.Context, opts ...grpc.CallOption) (DnsService_ImportBlocklistClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DnsService_ServiceDesc.Streams[3], "/dns.DnsService/ImportBlocklist", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dnsServiceImportBlocklistClient{stream}
+	return x, nil
+}
+
+type DnsService_ImportBlocklistClient interface {
+	Send(*ImportBlocklistRequest) error
+	CloseAndRecv() (*ImportBlocklistResponse, error)
+	grpc.ClientStream
+}
+
+type dnsServiceImportBlocklistClient struct {
+	grpc.ClientStream
+}
+
+func (x *dnsServiceImportBlocklistClient) Send(m *ImportBlocklistRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dnsServiceImportBlocklistClient) CloseAndRecv() (*ImportBlocklistResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportBlocklistResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DnsServiceServer is the server API for DnsService service.
 // All implementations must embed UnimplementedDnsServiceServer
 // for forward compatibility
 type DnsServiceServer interface {
 	SendDnsRequest(context.Context, *DnsRequest) (*DnsResponse, error)
 	BlockIp(context.Context, *BlockIpRequest) (*BlockIpResponse, error)
+	UnblockIp(context.Context, *UnblockIpRequest) (*UnblockIpResponse, error)
+	ListBlockedIps(context.Context, *ListBlockedIpsRequest) (*ListBlockedIpsResponse, error)
+	CheckIp(context.Context, *CheckIpRequest) (*CheckIpResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	TailDnsRequests(*TailDnsRequestsRequest, DnsService_TailDnsRequestsServer) error
+	AddAllowlistEntry(context.Context, *AddAllowlistEntryRequest) (*AddAllowlistEntryResponse, error)
+	RemoveAllowlistEntry(context.Context, *RemoveAllowlistEntryRequest) (*RemoveAllowlistEntryResponse, error)
+	ListAllowlist(context.Context, *ListAllowlistRequest) (*ListAllowlistResponse, error)
+	AddBlockRule(context.Context, *AddBlockRuleRequest) (*AddBlockRuleResponse, error)
+	RemoveBlockRule(context.Context, *RemoveBlockRuleRequest) (*RemoveBlockRuleResponse, error)
+	ListBlockRules(context.Context, *ListBlockRulesRequest) (*ListBlockRulesResponse, error)
+	ExplainVerdict(context.Context, *ExplainVerdictRequest) (*ExplainVerdictResponse, error)
+	WatchBlocks(*WatchBlocksRequest, DnsService_WatchBlocksServer) error
+	ExportBlocklist(*ExportBlocklistRequest, DnsService_ExportBlocklistServer) error
+	ImportBlocklist(DnsService_ImportBlocklistServer) error
 	mustEmbedUnimplementedDnsServiceServer()
 }
 
@@ -67,6 +326,51 @@ func (UnimplementedDnsServiceServer) SendDnsRequest(context.Context, *DnsRequest
 func (UnimplementedDnsServiceServer) BlockIp(context.Context, *BlockIpRequest) (*BlockIpResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BlockIp not implemented")
 }
+func (UnimplementedDnsServiceServer) UnblockIp(context.Context, *UnblockIpRequest) (*UnblockIpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnblockIp not implemented")
+}
+func (UnimplementedDnsServiceServer) ListBlockedIps(context.Context, *ListBlockedIpsRequest) (*ListBlockedIpsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlockedIps not implemented")
+}
+func (UnimplementedDnsServiceServer) CheckIp(context.Context, *CheckIpRequest) (*CheckIpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckIp not implemented")
+}
+func (UnimplementedDnsServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedDnsServiceServer) TailDnsRequests(*TailDnsRequestsRequest, DnsService_TailDnsRequestsServer) error {
+	return status.Errorf(codes.Unimplemented, "method TailDnsRequests not implemented")
+}
+func (UnimplementedDnsServiceServer) AddAllowlistEntry(context.Context, *AddAllowlistEntryRequest) (*AddAllowlistEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddAllowlistEntry not implemented")
+}
+func (UnimplementedDnsServiceServer) RemoveAllowlistEntry(context.Context, *RemoveAllowlistEntryRequest) (*RemoveAllowlistEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveAllowlistEntry not implemented")
+}
+func (UnimplementedDnsServiceServer) ListAllowlist(context.Context, *ListAllowlistRequest) (*ListAllowlistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAllowlist not implemented")
+}
+func (UnimplementedDnsServiceServer) AddBlockRule(context.Context, *AddBlockRuleRequest) (*AddBlockRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBlockRule not implemented")
+}
+func (UnimplementedDnsServiceServer) RemoveBlockRule(context.Context, *RemoveBlockRuleRequest) (*RemoveBlockRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveBlockRule not implemented")
+}
+func (UnimplementedDnsServiceServer) ListBlockRules(context.Context, *ListBlockRulesRequest) (*ListBlockRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlockRules not implemented")
+}
+func (UnimplementedDnsServiceServer) ExplainVerdict(context.Context, *ExplainVerdictRequest) (*ExplainVerdictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainVerdict not implemented")
+}
+func (UnimplementedDnsServiceServer) WatchBlocks(*WatchBlocksRequest, DnsService_WatchBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBlocks not implemented")
+}
+func (UnimplementedDnsServiceServer) ExportBlocklist(*ExportBlocklistRequest, DnsService_ExportBlocklistServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportBlocklist not implemented")
+}
+func (UnimplementedDnsServiceServer) ImportBlocklist(DnsService_ImportBlocklistServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportBlocklist not implemented")
+}
 func (UnimplementedDnsServiceServer) mustEmbedUnimplementedDnsServiceServer() {}
 
 // UnsafeDnsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -116,6 +420,293 @@ func _DnsService_BlockIp_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DnsService_UnblockIp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnblockIpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).UnblockIp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/UnblockIp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).UnblockIp(ctx, req.(*UnblockIpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_ListBlockedIps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlockedIpsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).ListBlockedIps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/ListBlockedIps",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).ListBlockedIps(ctx, req.(*ListBlockedIpsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_CheckIp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckIpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).CheckIp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/CheckIp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).CheckIp(ctx, req.(*CheckIpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/GetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_TailDnsRequests_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailDnsRequestsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DnsServiceServer).TailDnsRequests(m, &dnsServiceTailDnsRequestsServer{stream})
+}
+
+type DnsService_TailDnsRequestsServer interface {
+	Send(*TailDnsRequestsResponse) error
+	grpc.ServerStream
+}
+
+type dnsServiceTailDnsRequestsServer struct {
+	grpc.ServerStream
+}
+
+func (x *dnsServiceTailDnsRequestsServer) Send(m *TailDnsRequestsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DnsService_AddAllowlistEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddAllowlistEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).AddAllowlistEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/AddAllowlistEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).AddAllowlistEntry(ctx, req.(*AddAllowlistEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_RemoveAllowlistEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveAllowlistEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).RemoveAllowlistEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/RemoveAllowlistEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).RemoveAllowlistEntry(ctx, req.(*RemoveAllowlistEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_ListAllowlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAllowlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).ListAllowlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/ListAllowlist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).ListAllowlist(ctx, req.(*ListAllowlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_AddBlockRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBlockRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).AddBlockRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/AddBlockRule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).AddBlockRule(ctx, req.(*AddBlockRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_RemoveBlockRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveBlockRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).RemoveBlockRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/RemoveBlockRule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).RemoveBlockRule(ctx, req.(*RemoveBlockRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_ListBlockRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlockRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).ListBlockRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/ListBlockRules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).ListBlockRules(ctx, req.(*ListBlockRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_ExplainVerdict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainVerdictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DnsServiceServer).ExplainVerdict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dns.DnsService/ExplainVerdict",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DnsServiceServer).ExplainVerdict(ctx, req.(*ExplainVerdictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DnsService_WatchBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DnsServiceServer).WatchBlocks(m, &dnsServiceWatchBlocksServer{stream})
+}
+
+type DnsService_WatchBlocksServer interface {
+	Send(*WatchBlocksResponse) error
+	grpc.ServerStream
+}
+
+type dnsServiceWatchBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *dnsServiceWatchBlocksServer) Send(m *WatchBlocksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DnsService_ExportBlocklist_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportBlocklistRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DnsServiceServer).ExportBlocklist(m, &dnsServiceExportBlocklistServer{stream})
+}
+
+type DnsService_ExportBlocklistServer interface {
+	Send(*ExportBlocklistResponse) error
+	grpc.ServerStream
+}
+
+type dnsServiceExportBlocklistServer struct {
+	grpc.ServerStream
+}
+
+func (x *dnsServiceExportBlocklistServer) Send(m *ExportBlocklistResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DnsService_ImportBlocklist_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DnsServiceServer).ImportBlocklist(&dnsServiceImportBlocklistServer{stream})
+}
+
+type DnsService_ImportBlocklistServer interface {
+	SendAndClose(*ImportBlocklistResponse) error
+	Recv() (*ImportBlocklistRequest, error)
+	grpc.ServerStream
+}
+
+type dnsServiceImportBlocklistServer struct {
+	grpc.ServerStream
+}
+
+func (x *dnsServiceImportBlocklistServer) SendAndClose(m *ImportBlocklistResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dnsServiceImportBlocklistServer) Recv() (*ImportBlocklistRequest, error) {
+	m := new(ImportBlocklistRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DnsService_ServiceDesc is the grpc.ServiceDesc for DnsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -131,7 +722,72 @@ var DnsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BlockIp",
 			Handler:    _DnsService_BlockIp_Handler,
 		},
+		{
+			MethodName: "UnblockIp",
+			Handler:    _DnsService_UnblockIp_Handler,
+		},
+		{
+			MethodName: "ListBlockedIps",
+			Handler:    _DnsService_ListBlockedIps_Handler,
+		},
+		{
+			MethodName: "CheckIp",
+			Handler:    _DnsService_CheckIp_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _DnsService_GetStats_Handler,
+		},
+		{
+			MethodName: "AddAllowlistEntry",
+			Handler:    _DnsService_AddAllowlistEntry_Handler,
+		},
+		{
+			MethodName: "RemoveAllowlistEntry",
+			Handler:    _DnsService_RemoveAllowlistEntry_Handler,
+		},
+		{
+			MethodName: "ListAllowlist",
+			Handler:    _DnsService_ListAllowlist_Handler,
+		},
+		{
+			MethodName: "AddBlockRule",
+			Handler:    _DnsService_AddBlockRule_Handler,
+		},
+		{
+			MethodName: "RemoveBlockRule",
+			Handler:    _DnsService_RemoveBlockRule_Handler,
+		},
+		{
+			MethodName: "ListBlockRules",
+			Handler:    _DnsService_ListBlockRules_Handler,
+		},
+		{
+			MethodName: "ExplainVerdict",
+			Handler:    _DnsService_ExplainVerdict_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TailDnsRequests",
+			Handler:       _DnsService_TailDnsRequests_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchBlocks",
+			Handler:       _DnsService_WatchBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportBlocklist",
+			Handler:       _DnsService_ExportBlocklist_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportBlocklist",
+			Handler:       _DnsService_ImportBlocklist_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "dns.proto",
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/allowlist"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const reasonAllowlisted = "allowlisted"
+
+// allowlistCache holds the allowlist of the store, reloaded periodically so
+// that changes made through other servers are picked up, and on every change
+// made through this one.
+type allowlistCache struct {
+	current atomic.Pointer[allowlist.List]
+}
+
+// list returns the current allowlist, nil until the first load.
+func (c *allowlistCache) list() *allowlist.List {
+	return c.current.Load()
+}
+
+func (c *allowlistCache) refresh(ctx context.Context, st store.Store) error {
+	entries, err := st.ListAllowed(ctx)
+	if err != nil {
+		return err
+	}
+	c.current.Store(allowlist.New(entries))
+	return nil
+}
+
+// run reloads the allowlist every interval until ctx is done.
+func (c *allowlistCache) run(ctx context.Context, st store.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.refresh(ctx, st); err != nil {
+			log.Printf("Failed to load allowlist: %v", err)
+			stats.Add("allowlist_refresh_failed", 1)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// allowlisted reports whether the IP and the domain of a request are
+// allowlisted.
+func (ts *tenantState) allowlisted(ip, domain string) (ipAllowed, domainAllowed bool) {
+	l := ts.allowlist.list()
+	_, ipAllowed = l.MatchIP(ip)
+	_, domainAllowed = l.MatchDomain(domain)
+	return ipAllowed, domainAllowed
+}
+
+// AddAllowlistEntry protects an IP, a CIDR or a domain suffix from blocks
+func (s *server) AddAllowlistEntry(ctx context.Context, req *pb.AddAllowlistEntryRequest) (*pb.AddAllowlistEntryResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	kind, value, err := allowlist.Parse(req.GetValue())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	entry := store.AllowEntry{Value: value, Kind: kind, Reason: req.GetReason(), CreatedAt: time.Now().UTC()}
+	if err := ts.store.Allow(ctx, entry); err != nil {
+		log.Printf("Failed to add allowlist entry: %v", err)
+		return &pb.AddAllowlistEntryResponse{Status: "failed"}, err
+	}
+	audit(ctx, "allowlist add %s %s reason=%q", kind, value, req.GetReason())
+	ts.reloadAllowlist(ctx)
+	return &pb.AddAllowlistEntryResponse{Status: "success", Entry: allowlistEntry(entry)}, nil
+}
+
+// RemoveAllowlistEntry removes an entry from the allowlist
+func (s *server) RemoveAllowlistEntry(ctx context.Context, req *pb.RemoveAllowlistEntryRequest) (*pb.RemoveAllowlistEntryResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, value, err := allowlist.Parse(req.GetValue())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	found, err := ts.store.Disallow(ctx, value)
+	if err != nil {
+		log.Printf("Failed to remove allowlist entry: %v", err)
+		return &pb.RemoveAllowlistEntryResponse{Status: "failed"}, err
+	}
+	if !found {
+		return &pb.RemoveAllowlistEntryResponse{Status: "not_found"}, nil
+	}
+	audit(ctx, "allowlist remove %s", value)
+	ts.reloadAllowlist(ctx)
+	return &pb.RemoveAllowlistEntryResponse{Status: "success"}, nil
+}
+
+// ListAllowlist returns every allowlist entry
+func (s *server) ListAllowlist(ctx context.Context, req *pb.ListAllowlistRequest) (*pb.ListAllowlistResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ts.store.ListAllowed(ctx)
+	if err != nil {
+		log.Printf("Failed to list allowlist: %v", err)
+		return nil, err
+	}
+	resp := &pb.ListAllowlistResponse{}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, allowlistEntry(e))
+	}
+	return resp, nil
+}
+
+func (ts *tenantState) reloadAllowlist(ctx context.Context) {
+	if err := ts.allowlist.refresh(ctx, ts.store); err != nil {
+		log.Printf("Failed to reload allowlist of tenant %s: %v", ts.id, err)
+	}
+}
+
+// allowlistEntry converts a store allowlist entry to its protobuf
+// representation
+func allowlistEntry(e store.AllowEntry) *pb.AllowlistEntry {
+	return &pb.AllowlistEntry{
+		Value:     e.Value,
+		Kind:      e.Kind,
+		Reason:    e.Reason,
+		CreatedAt: e.CreatedAt.Unix(),
+	}
+}
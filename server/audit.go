@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
+
+	"google.golang.org/grpc/peer"
+)
+
+// audit logs a security-relevant event with the address of the client that
+// caused it and its tenant.
+func audit(ctx context.Context, format string, args ...any) {
+	client := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		client = p.Addr.String()
+	}
+	log.Printf("AUDIT client=%s tenant=%s %s", client, tenant.FromContext(ctx), fmt.Sprintf(format, args...))
+}
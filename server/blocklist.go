@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/blockfile"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// exportChunkSize is the size of the chunks of ExportBlocklist.
+	exportChunkSize = 64 * 1024
+	// maxImportSize bounds the files of ImportBlocklist, which are held in
+	// memory.
+	maxImportSize = 64 * 1024 * 1024
+	// maxImportChanges is the number of changes listed by ImportBlocklist.
+	maxImportChanges = 10000
+)
+
+// blocklistFormat returns the blockfile format of f.
+func blocklistFormat(f pb.BlocklistFormat) (string, error) {
+	switch f {
+	case pb.BlocklistFormat_BLOCKLIST_FORMAT_JSONL:
+		return blockfile.FormatJSONL, nil
+	case pb.BlocklistFormat_BLOCKLIST_FORMAT_CSV:
+		return blockfile.FormatCSV, nil
+	}
+	return "", status.Errorf(codes.InvalidArgument, "unknown blocklist format %d", f)
+}
+
+// ExportBlocklist streams every blacklist entry of the tenant, with its
+// reason, creation time and remaining TTL, as a JSON Lines or CSV file
+func (s *server) ExportBlocklist(req *pb.ExportBlocklistRequest, stream pb.DnsService_ExportBlocklistServer) error {
+	ctx := stream.Context()
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return err
+	}
+	format, err := blocklistFormat(req.GetFormat())
+	if err != nil {
+		return err
+	}
+	entries, err := ts.store.List(ctx)
+	if err != nil {
+		log.Printf("Failed to list blocked IPs: %v", err)
+		return err
+	}
+	var buf bytes.Buffer
+	if err := blockfile.Write(&buf, format, entries, time.Now()); err != nil {
+		return err
+	}
+	audit(ctx, "blocklist export format=%s entries=%d", format, len(entries))
+	for data := buf.Bytes(); len(data) > 0; {
+		n := min(exportChunkSize, len(data))
+		if err := stream.Send(&pb.ExportBlocklistResponse{Data: data[:n]}); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// ImportBlocklist reads a JSON Lines or CSV file of blacklist entries and
+// merges it into the blacklist of the tenant, or replaces the blacklist with
+// it, returning the changes. With dry_run the changes are only computed.
+// Entries protected by the allowlist are skipped, as with BlockIp.
+func (s *server) ImportBlocklist(stream pb.DnsService_ImportBlocklistServer) error {
+	ctx := stream.Context()
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return err
+	}
+	var first *pb.ImportBlocklistRequest
+	var data []byte
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first == nil {
+			first = req
+		}
+		if len(data)+len(req.GetData()) > maxImportSize {
+			return status.Errorf(codes.ResourceExhausted, "the file exceeds %d bytes", maxImportSize)
+		}
+		data = append(data, req.GetData()...)
+	}
+	format, err := blocklistFormat(first.GetFormat())
+	if err != nil {
+		return err
+	}
+	imported, err := blockfile.Read(bytes.NewReader(data), format, time.Now())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	current, err := ts.store.List(ctx)
+	if err != nil {
+		log.Printf("Failed to list blocked IPs: %v", err)
+		return err
+	}
+	replace := first.GetMode() == pb.ImportMode_IMPORT_MODE_REPLACE
+	changes, unchanged := blockfile.Diff(current, imported, replace)
+
+	resp := &pb.ImportBlocklistResponse{Status: "success", Unchanged: int32(unchanged)}
+	if first.GetDryRun() {
+		resp.Status = "dry_run"
+	}
+	failed := 0
+	list := func(c *pb.BlocklistChange) {
+		if len(resp.Changes) < maxImportChanges {
+			resp.Changes = append(resp.Changes, c)
+		} else {
+			resp.Truncated = true
+		}
+	}
+	for _, c := range changes {
+		change := &pb.BlocklistChange{Action: c.Action, Entry: blockedIp(c.Entry)}
+		if c.Action == blockfile.ActionUpdate {
+			change.Previous = blockedIp(c.Previous)
+		}
+		if c.Action != blockfile.ActionRemove {
+			if allowed, ok := ts.allowlist.list().MatchIP(c.Entry.IP); ok {
+				change.Action, change.Detail = "skip", "allowlisted by "+allowed.Value
+				resp.Skipped++
+				list(change)
+				continue
+			}
+		}
+		if !first.GetDryRun() {
+			var err error
+			if c.Action == blockfile.ActionRemove {
+				_, err = ts.store.Unblock(ctx, c.Entry.IP)
+			} else {
+				err = ts.store.Block(ctx, c.Entry)
+			}
+			if err != nil {
+				failed++
+				change.Detail = "failed: " + err.Error()
+				list(change)
+				continue
+			}
+			if c.Action == blockfile.ActionRemove {
+				ts.snapshot.remove(c.Entry.IP)
+			} else {
+				ts.snapshot.put(c.Entry)
+			}
+		}
+		switch c.Action {
+		case blockfile.ActionAdd:
+			resp.Added++
+		case blockfile.ActionUpdate:
+			resp.Updated++
+		case blockfile.ActionRemove:
+			resp.Removed++
+		}
+		list(change)
+	}
+	if first.GetDryRun() {
+		return stream.SendAndClose(resp)
+	}
+
+	if failed > 0 {
+		resp.Status = "failed"
+		log.Printf("Failed to apply %d of the %d changes of a blocklist import", failed, len(changes))
+	}
+	mode := strings.ToLower(strings.TrimPrefix(first.GetMode().String(), "IMPORT_MODE_"))
+	audit(ctx, "blocklist import format=%s mode=%s added=%d updated=%d removed=%d skipped=%d failed=%d", format, mode, resp.Added, resp.Updated, resp.Removed, resp.Skipped, failed)
+	stats.Add("ips_blocked", int64(resp.Added+resp.Updated))
+	stats.Add("ips_unblocked", int64(resp.Removed))
+	ts.watch.changedNow()
+	return stream.SendAndClose(resp)
+}
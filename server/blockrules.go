@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/blockrule"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/qtype"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const reasonBlockRule = "block_rule"
+
+// ruleCache holds the block rules of the store, reloaded periodically so
+// that changes made through other servers are picked up, and on every change
+// made through this one.
+type ruleCache struct {
+	current atomic.Pointer[blockrule.Set]
+}
+
+// set returns the current rules, nil until the first load.
+func (c *ruleCache) set() *blockrule.Set {
+	return c.current.Load()
+}
+
+func (c *ruleCache) refresh(ctx context.Context, st store.Store) error {
+	rules, err := st.ListBlockRules(ctx)
+	if err != nil {
+		return err
+	}
+	c.current.Store(blockrule.New(rules))
+	return nil
+}
+
+// run reloads the rules every interval until ctx is done.
+func (c *ruleCache) run(ctx context.Context, st store.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.refresh(ctx, st); err != nil {
+			log.Printf("Failed to load block rules: %v", err)
+			stats.Add("block_rules_refresh_failed", 1)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AddBlockRule blocks the requests of a source for a domain pattern or a
+// query type
+func (s *server) AddBlockRule(ctx context.Context, req *pb.AddBlockRuleRequest) (*pb.AddBlockRuleResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	source, err := blockrule.ParseSource(req.GetSource())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pattern, err := blockrule.ParsePattern(req.GetDomainPattern())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !qtype.Valid(req.GetQueryType()) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid query type %d", req.GetQueryType())
+	}
+	if source == "" && pattern == "" && req.GetQueryType() == pb.QueryType_QUERY_TYPE_UNKNOWN {
+		return nil, status.Error(codes.InvalidArgument, "a block rule needs a source, a domain pattern or a query type")
+	}
+	// The rule is rejected as soon as it covers an allowlisted address,
+	// like the blocks of BlockIp
+	allowed, ok := ts.allowlist.list().MatchPrefix(source)
+	if !ok {
+		// The allowlist entry of a domain covers its subdomains
+		allowed, ok = ts.allowlist.list().MatchDomain(strings.TrimPrefix(pattern, "*."))
+	}
+	if ok {
+		audit(ctx, "rejected block rule source=%q domain=%q type=%s: allowlisted by %s %s", source, pattern, qtype.String(req.GetQueryType()), allowed.Kind, allowed.Value)
+		stats.Add("blocks_rejected_allowlisted", 1)
+		return nil, status.Errorf(codes.FailedPrecondition, "the rule is overridden by the allowlist entry %s", allowed.Value)
+	}
+
+	rule := store.BlockRule{
+		ID:            blockrule.ID(source, pattern, uint32(req.GetQueryType())),
+		Source:        source,
+		DomainPattern: pattern,
+		QueryType:     uint32(req.GetQueryType()),
+		Reason:        req.GetReason(),
+		CreatedAt:     time.Now().UTC(),
+	}
+	if req.GetTtlSeconds() > 0 {
+		rule.ExpiresAt = rule.CreatedAt.Add(time.Duration(req.GetTtlSeconds()) * time.Second)
+	}
+	if err := ts.store.AddBlockRule(ctx, rule); err != nil {
+		log.Printf("Failed to add block rule: %v", err)
+		return &pb.AddBlockRuleResponse{Status: "failed"}, err
+	}
+	audit(ctx, "block rule add %s source=%q domain=%q type=%s reason=%q", rule.ID, source, pattern, qtype.String(req.GetQueryType()), rule.Reason)
+	stats.Add("block_rules_added", 1)
+	ts.reloadRules(ctx)
+	return &pb.AddBlockRuleResponse{Status: "success", Rule: blockRule(rule)}, nil
+}
+
+// RemoveBlockRule removes a block rule by ID
+func (s *server) RemoveBlockRule(ctx context.Context, req *pb.RemoveBlockRuleRequest) (*pb.RemoveBlockRuleResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing rule ID")
+	}
+	found, err := ts.store.RemoveBlockRule(ctx, req.GetId())
+	if err != nil {
+		log.Printf("Failed to remove block rule: %v", err)
+		return &pb.RemoveBlockRuleResponse{Status: "failed"}, err
+	}
+	if !found {
+		return &pb.RemoveBlockRuleResponse{Status: "not_found"}, nil
+	}
+	audit(ctx, "block rule remove %s", req.GetId())
+	ts.reloadRules(ctx)
+	return &pb.RemoveBlockRuleResponse{Status: "success"}, nil
+}
+
+// ListBlockRules returns every block rule with its remaining TTL
+func (s *server) ListBlockRules(ctx context.Context, req *pb.ListBlockRulesRequest) (*pb.ListBlockRulesResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ts.store.ListBlockRules(ctx)
+	if err != nil {
+		log.Printf("Failed to list block rules: %v", err)
+		return nil, err
+	}
+	resp := &pb.ListBlockRulesResponse{}
+	for _, r := range rules {
+		resp.Rules = append(resp.Rules, blockRule(r))
+	}
+	return resp, nil
+}
+
+// matchRules returns the unexpired rules blocking req, leaving out those
+// scoped to domains when its domain is allowlisted.
+func (ts *tenantState) matchRules(req *pb.DnsRequest, domainAllowed bool) []store.BlockRule {
+	rules := ts.rules.set().Matches(req.GetIpAddress(), req.GetDomain(), uint32(req.GetQueryType()), time.Now())
+	if !domainAllowed {
+		return rules
+	}
+	var kept []store.BlockRule
+	for _, r := range rules {
+		if r.DomainPattern == "" {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+func (ts *tenantState) reloadRules(ctx context.Context) {
+	ts.watch.changedNow()
+	if err := ts.rules.refresh(ctx, ts.store); err != nil {
+		log.Printf("Failed to reload block rules of tenant %s: %v", ts.id, err)
+	}
+}
+
+// blockRule converts a store block rule to its protobuf representation
+func blockRule(r store.BlockRule) *pb.BlockRule {
+	ttl := int64(-1)
+	if !r.ExpiresAt.IsZero() {
+		ttl = int64(time.Until(r.ExpiresAt).Seconds())
+	}
+	return &pb.BlockRule{
+		Id:            r.ID,
+		Source:        r.Source,
+		DomainPattern: r.DomainPattern,
+		QueryType:     pb.QueryType(r.QueryType),
+		Reason:        r.Reason,
+		TtlSeconds:    ttl,
+		CreatedAt:     r.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/rpz"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
+)
+
+// config holds the server settings, read from the environment with defaults
+// matching compose.yml.
+type config struct {
+	store store.Config
+	// failurePolicy decides the verdicts when the store is unreachable, the
+	// snapshot it may use is refreshed every snapshotInterval.
+	failurePolicy    failurePolicy
+	snapshotInterval time.Duration
+	// allowlistRefreshInterval and blockRulesRefreshInterval are the periods
+	// of the allowlist and block rule reloads.
+	allowlistRefreshInterval  time.Duration
+	blockRulesRefreshInterval time.Duration
+	// watchPollInterval is the period at which WatchBlocks lists the store
+	// for the changes made through other servers.
+	watchPollInterval time.Duration
+	// rpzAddr is the address serving the RPZ zones, "" disables them. The
+	// zone of the default tenant is rpzZone, the others are prefixed with
+	// the tenant ID. Transfers are allowed from rpzTransferAllow, and
+	// rpzNotify are the secondaries notified of the changes.
+	rpzAddr          string
+	rpzZone          string
+	rpzAction        string
+	rpzTransferAllow []string
+	rpzNotify        []string
+	// bus is the event bus, "kafka" or "memory".
+	bus                   string
+	kafkaBootstrapServers string
+	// topicsConfig is the YAML file of the topic specs, see topics.LoadSpecs.
+	topicsConfig string
+	// deadLetterTopic receives the messages that could not be delivered.
+	deadLetterTopic string
+	// spoolDir holds the messages waiting for the event bus, "" disables
+	// the spool.
+	spoolDir      string
+	spoolMaxBytes int64
+	// tenants are the tenants read from TENANTS_CONFIG, nil when only the
+	// default tenant exists.
+	tenants *tenant.Config
+}
+
+func loadConfig() (config, error) {
+	spoolMaxBytes, err := strconv.ParseInt(getEnv("SPOOL_MAX_BYTES", "268435456"), 10, 64)
+	if err != nil {
+		return config{}, fmt.Errorf("invalid SPOOL_MAX_BYTES: %w", err)
+	}
+	policy, err := parseFailurePolicy(getEnv("BLACKLIST_FAILURE_POLICY", "open"))
+	if err != nil {
+		return config{}, err
+	}
+	snapshotInterval, err := time.ParseDuration(getEnv("BLACKLIST_SNAPSHOT_INTERVAL", "30s"))
+	if err != nil || snapshotInterval <= 0 {
+		return config{}, fmt.Errorf("invalid BLACKLIST_SNAPSHOT_INTERVAL %q", getEnv("BLACKLIST_SNAPSHOT_INTERVAL", "30s"))
+	}
+	allowlistRefreshInterval, err := time.ParseDuration(getEnv("ALLOWLIST_REFRESH_INTERVAL", "10s"))
+	if err != nil || allowlistRefreshInterval <= 0 {
+		return config{}, fmt.Errorf("invalid ALLOWLIST_REFRESH_INTERVAL %q", getEnv("ALLOWLIST_REFRESH_INTERVAL", "10s"))
+	}
+	blockRulesRefreshInterval, err := time.ParseDuration(getEnv("BLOCK_RULES_REFRESH_INTERVAL", "10s"))
+	if err != nil || blockRulesRefreshInterval <= 0 {
+		return config{}, fmt.Errorf("invalid BLOCK_RULES_REFRESH_INTERVAL %q", getEnv("BLOCK_RULES_REFRESH_INTERVAL", "10s"))
+	}
+	watchPollInterval, err := time.ParseDuration(getEnv("WATCH_POLL_INTERVAL", "2s"))
+	if err != nil || watchPollInterval <= 0 {
+		return config{}, fmt.Errorf("invalid WATCH_POLL_INTERVAL %q", getEnv("WATCH_POLL_INTERVAL", "2s"))
+	}
+	var tenants *tenant.Config
+	if path := getEnv("TENANTS_CONFIG", ""); path != "" {
+		if tenants, err = tenant.Load(path); err != nil {
+			return config{}, err
+		}
+	}
+	return config{
+		store: store.Config{
+			Backend: getEnv("BLACKLIST_STORE", "redis"),
+			Redis: store.RedisConfig{
+				Mode:       getEnv("REDIS_MODE", "standalone"),
+				Addrs:      strings.Split(getEnv("REDIS_ADDR", "redis:6379"), ","),
+				MasterName: getEnv("REDIS_MASTER_NAME", "mymaster"),
+				Password:   getEnv("REDIS_PASSWORD", ""),
+				Namespace:  getEnv("REDIS_NAMESPACE", "blacklist"),
+			},
+			BoltPath: getEnv("BLACKLIST_BOLT_PATH", "blacklist.db"),
+		},
+		failurePolicy:             policy,
+		snapshotInterval:          snapshotInterval,
+		allowlistRefreshInterval:  allowlistRefreshInterval,
+		blockRulesRefreshInterval: blockRulesRefreshInterval,
+		watchPollInterval:         watchPollInterval,
+		rpzAddr:                   getEnv("RPZ_ADDR", ""),
+		rpzZone:                   getEnv("RPZ_ZONE", "rpz.dns-stream-analyzer"),
+		rpzAction:                 getEnv("RPZ_ACTION", rpz.ActionNXDOMAIN),
+		rpzTransferAllow:          strings.Split(getEnv("RPZ_TRANSFER_ALLOW", "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"), ","),
+		rpzNotify:                 strings.Split(getEnv("RPZ_NOTIFY", ""), ","),
+		bus:                       getEnv("EVENT_BUS", "kafka"),
+		kafkaBootstrapServers:     getEnv("KAFKA_BOOTSTRAP_SERVERS", "broker:9092"),
+		topicsConfig:              getEnv("TOPICS_CONFIG", ""),
+		deadLetterTopic:           getEnv("DEAD_LETTER_TOPIC", topic+".dlq"),
+		spoolDir:                  getEnv("SPOOL_DIR", ""),
+		spoolMaxBytes:             spoolMaxBytes,
+		tenants:                   tenants,
+	}, nil
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/deadletter"
+)
+
+// respooler is a publisher able to publish again the messages whose
+// delivery failed, see spooledPublisher.
+type respooler interface {
+	Respool(m *bus.Message)
+}
+
+// handleDeliveries reads the delivery reports of publisher until it is
+// closed. The messages that could not be delivered because the bus was
+// unreachable are published again, ahead of the spool, when publisher has
+// one; the others are published to the dead-letter topic
+func handleDeliveries(publisher bus.Publisher, deadLetterTopic string) {
+	for d := range publisher.Deliveries() {
+		if d.Err == nil {
+			log.Printf("Delivered message to %s[%d]@%d\n", d.Message.Topic, d.Message.Partition, d.Message.Offset)
+			continue
+		}
+
+		log.Printf("Delivery failed to %s: %v\n", d.Message.Topic, d.Err)
+		stats.Add("kafka_delivery_failed", 1)
+		if r, ok := publisher.(respooler); ok && transient(d.Err) {
+			// The dead-letter topic is on the same unreachable bus
+			r.Respool(d.Message)
+			continue
+		}
+		if d.Message.Topic == deadLetterTopic {
+			// Dead-lettering a dead letter would loop forever
+			log.Printf("Dead letter lost: %s", d.Message.Value)
+			stats.Add("dead_letters_lost", 1)
+			continue
+		}
+		err := publisher.Publish(deadletter.New(deadLetterTopic, d.Message, "server", d.Err))
+		if err != nil {
+			log.Printf("Failed to dead-letter message: %v", err)
+			stats.Add("dead_letters_lost", 1)
+			continue
+		}
+		stats.Add("dead_letters", 1)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+// ExplainVerdict runs the decision path of SendDnsRequest on a request,
+// without publishing nor counting it, and returns the verdict with every
+// allowlist entry, blacklist entry and block rule matching the request
+func (s *server) ExplainVerdict(ctx context.Context, req *pb.ExplainVerdictRequest) (*pb.ExplainVerdictResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dns := &pb.DnsRequest{IpAddress: req.GetIpAddress(), Domain: req.GetDomain(), QueryType: req.GetQueryType()}
+	if err := normalizeDnsRequest(dns); err != nil {
+		return nil, err
+	}
+	resp := &pb.ExplainVerdictResponse{
+		IpAddress: dns.GetIpAddress(),
+		Domain:    dns.GetDomain(),
+		QueryType: dns.GetQueryType(),
+	}
+
+	// Same order as SendDnsRequest: an allowlisted IP overrides the
+	// blacklist, which comes before the block rules; an allowlisted domain
+	// only overrides the rules scoped to domains
+	ipAllowed, domainAllowed := ts.allowlisted(dns.GetIpAddress(), dns.GetDomain())
+	blocked, reason, entry, _ := s.decide(ctx, ts, dns.GetIpAddress())
+	effective := ts.matchRules(dns, domainAllowed)
+	allowed := ts.allowlist.list().Matches(dns.GetIpAddress(), dns.GetDomain())
+	for i, e := range allowed {
+		resp.Matches = append(resp.Matches, &pb.VerdictMatch{
+			Kind:       "allowlist_" + e.Kind,
+			Value:      e.Value,
+			Reason:     e.Reason,
+			CreatedAt:  e.CreatedAt.Unix(),
+			TtlSeconds: -1,
+			Decisive:   i == 0 && (ipAllowed || (!blocked && len(effective) == 0)),
+		})
+	}
+	if entry != nil {
+		m := blockedIp(*entry)
+		resp.Matches = append(resp.Matches, &pb.VerdictMatch{
+			Kind:       "blacklist_ip",
+			Value:      m.GetIpAddress(),
+			Reason:     m.GetReason(),
+			CreatedAt:  m.GetCreatedAt(),
+			TtlSeconds: m.GetTtlSeconds(),
+			Decisive:   !ipAllowed,
+		})
+	}
+	rules := ts.rules.set().Matches(dns.GetIpAddress(), dns.GetDomain(), uint32(dns.GetQueryType()), time.Now())
+	decisive := false
+	for _, r := range rules {
+		rule := blockRule(r)
+		// The first rule left by the allowlist decides
+		overridden := domainAllowed && r.DomainPattern != ""
+		resp.Matches = append(resp.Matches, &pb.VerdictMatch{
+			Kind:       ruleMatchKind(r),
+			Value:      rule.GetId(),
+			Rule:       rule,
+			Reason:     rule.GetReason(),
+			CreatedAt:  rule.GetCreatedAt(),
+			TtlSeconds: rule.GetTtlSeconds(),
+			Decisive:   !decisive && !overridden && !ipAllowed && !blocked,
+		})
+		decisive = decisive || !overridden
+	}
+
+	switch {
+	case ipAllowed:
+		resp.Status, resp.Reason = "success", reasonAllowlisted
+	case blocked:
+		resp.Status, resp.Reason = "blocked", reason
+	case len(effective) > 0:
+		resp.Status, resp.Reason = "blocked", reasonBlockRule
+	case reason == "" && domainAllowed:
+		resp.Status, resp.Reason = "success", reasonAllowlisted
+	default:
+		resp.Status, resp.Reason = "success", reason
+	}
+	return resp, nil
+}
+
+// ruleMatchKind returns the VerdictMatch kind of a block rule, after its
+// source.
+func ruleMatchKind(r store.BlockRule) string {
+	switch {
+	case r.Source == "":
+		return "block_rule_any_source"
+	case strings.Contains(r.Source, "/"):
+		return "block_rule_cidr"
+	default:
+		return "block_rule_ip"
+	}
+}
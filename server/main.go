@@ -2,135 +2,438 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"time"
 
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/event"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/qtype"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/spool"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/topics"
 	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
-	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
-	"github.com/go-redis/redis/v8"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 const (
-	port      string = ":50051"
-	redisAddr string = "redis:6379"
+	port string = ":50051"
 )
 
 var topic string = "myTopic"
 
 type server struct {
 	pb.UnimplementedDnsServiceServer
-	redisClient *redis.Client
-	producer    *kafka.Producer
+	store     store.Store
+	publisher bus.Publisher
+	tail      *tailHub
+	allowlist *allowlistCache
+	rules     *ruleCache
+	// policy applies when the store fails, snapshot is only kept with
+	// failSnapshot.
+	policy   failurePolicy
+	snapshot *snapshot
+	// watch journals the block changes for WatchBlocks.
+	watch *blockJournal
+	// store, allowlist, rules, snapshot and watch belong to the default
+	// tenant, tenants holds the state of the others declared in
+	// tenantConfig.
+	tenantConfig *tenant.Config
+	tenants      map[string]*tenantState
 }
 
 // SendDnsRequest handles incoming DNS requests
 func (s *server) SendDnsRequest(ctx context.Context, req *pb.DnsRequest) (*pb.DnsResponse, error) {
-	// Check if IP is already marked as malicious in Redis
-	val, err := s.redisClient.Get(ctx, req.GetIpAddress()).Result()
-	if err == nil && val == "malicious" {
-		log.Printf("Blacklisted IP detected, blocking: %s", req.GetIpAddress())
-		return &pb.DnsResponse{Status: "blocked"}, nil
+	stats.Add("dns_requests", 1)
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := normalizeDnsRequest(req); err != nil {
+		stats.Add("dns_requests_invalid", 1)
+		return nil, err
+	}
+	// Check if IP is already blacklisted, unless allowlisted. An allowlisted
+	// domain only overrides the block rules scoped to domains, blacklisted
+	// clients stay blocked whatever they query
+	ipAllowed, domainAllowed := ts.allowlisted(req.GetIpAddress(), req.GetDomain())
+	if ipAllowed || domainAllowed {
+		stats.Add("dns_requests_allowlisted", 1)
+	}
+	blocked, reason := false, reasonAllowlisted
+	if !ipAllowed {
+		blocked, reason = s.verdict(ctx, ts, req.GetIpAddress())
+		if !blocked && reason == "" && domainAllowed {
+			reason = reasonAllowlisted
+		}
+	}
+	if blocked {
+		log.Printf("Blacklisted IP detected, blocking: %s (%s)", req.GetIpAddress(), reason)
+		stats.Add("dns_requests_blocked", 1)
+		s.tail.publish(ts.id, &pb.TailDnsRequestsResponse{Request: req, Status: "blocked"})
+		return &pb.DnsResponse{Status: "blocked", Reason: reason}, nil
+	}
+	if !ipAllowed {
+		if rules := ts.matchRules(req, domainAllowed); len(rules) > 0 {
+			rule := rules[0]
+			log.Printf("Block rule %s matched, blocking: %s %s %s", rule.ID, req.GetIpAddress(), req.GetDomain(), qtype.String(req.GetQueryType()))
+			stats.Add("dns_requests_blocked", 1)
+			stats.Add("dns_requests_blocked_by_rule", 1)
+			s.tail.publish(ts.id, &pb.TailDnsRequestsResponse{Request: req, Status: "blocked"})
+			return &pb.DnsResponse{Status: "blocked", Reason: reasonBlockRule, Rule: blockRule(rule)}, nil
+		}
 	}
 
-	// Produce message to Kafka topic
-	message := fmt.Sprintf("IP: %s, Domain: %s, QueryType: %s, Timestamp: %d",
-		req.GetIpAddress(), req.GetDomain(), req.GetQueryType(), req.GetTimestamp())
-
-	s.producer.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-		Value:          []byte(message),
-	}, nil)
-
-	log.Printf("Sent DNS request to Kafka: %v", message)
-	return &pb.DnsResponse{Status: "success"}, nil
+	// Produce message to the topic of the tenant, keyed by IP so that the
+	// requests of a source are read in order by a single consumer
+	message, err := event.Encode(req)
+	if err != nil {
+		return nil, err
+	}
+	err = s.publisher.Publish(&bus.Message{
+		Topic: s.topicOf(ts.id),
+		Key:   []byte(req.GetIpAddress()),
+		Value: message,
+		Headers: []bus.Header{
+			{Key: "content-type", Value: []byte(event.ContentType)},
+			{Key: tenant.HeaderKey, Value: []byte(ts.id)},
+		},
+	})
+	if err != nil {
+		// The request was neither queued nor spooled, it is lost
+		log.Printf("Failed to send DNS request to Kafka: %v", err)
+		stats.Add("kafka_produce_failed", 1)
+		s.tail.publish(ts.id, &pb.TailDnsRequestsResponse{Request: req, Status: "failed"})
+		return &pb.DnsResponse{Status: "failed", Reason: reason}, nil
+	}
+	log.Printf("Sent DNS request to Kafka: %s", message)
+	stats.Add("dns_requests_forwarded", 1)
+	s.tail.publish(ts.id, &pb.TailDnsRequestsResponse{Request: req, Status: "success"})
+	return &pb.DnsResponse{Status: "success", Reason: reason}, nil
 }
 
 // BlockIp handles blocking IPs based on consumer feedback
 func (s *server) BlockIp(ctx context.Context, req *pb.BlockIpRequest) (*pb.BlockIpResponse, error) {
-	err := s.redisClient.Set(ctx, req.GetIpAddress(), "malicious", 0).Err()
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := canonicalIP(req.GetIpAddress())
+	if err != nil {
+		return nil, err
+	}
+	if allowed, ok := ts.allowlist.list().MatchIP(ip); ok {
+		audit(ctx, "rejected block of %s: allowlisted by %s %s, block reason=%q", ip, allowed.Kind, allowed.Value, req.GetReason())
+		stats.Add("blocks_rejected_allowlisted", 1)
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is allowlisted by %s", ip, allowed.Value)
+	}
+	entry := store.Entry{
+		IP:        ip,
+		Reason:    req.GetReason(),
+		CreatedAt: time.Now().UTC(),
+	}
+	if req.GetTtlSeconds() > 0 {
+		entry.ExpiresAt = entry.CreatedAt.Add(time.Duration(req.GetTtlSeconds()) * time.Second)
+	}
+	err = ts.store.Block(ctx, entry)
 	if err != nil {
 		log.Printf("Failed to block IP: %v", err)
 		return &pb.BlockIpResponse{Status: "failed"}, err
 	}
-	log.Printf("Blocked IP: %s", req.GetIpAddress())
+	ts.snapshot.put(entry)
+	ts.watch.changedNow()
+	log.Printf("Blocked IP: %s (tenant %s)", ip, ts.id)
+	stats.Add("ips_blocked", 1)
 	return &pb.BlockIpResponse{Status: "success"}, nil
 }
 
-func main() {
-	// Redis setup
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-	})
-	defer redisClient.Close()
+// UnblockIp removes an IP from the blacklist
+func (s *server) UnblockIp(ctx context.Context, req *pb.UnblockIpRequest) (*pb.UnblockIpResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := canonicalIP(req.GetIpAddress())
+	if err != nil {
+		return nil, err
+	}
+	found, err := ts.store.Unblock(ctx, ip)
+	if err != nil {
+		log.Printf("Failed to unblock IP: %v", err)
+		return &pb.UnblockIpResponse{Status: "failed"}, err
+	}
+	ts.snapshot.remove(ip)
+	ts.watch.changedNow()
+	if !found {
+		return &pb.UnblockIpResponse{Status: "not_found"}, nil
+	}
+	log.Printf("Unblocked IP: %s (tenant %s)", ip, ts.id)
+	stats.Add("ips_unblocked", 1)
+	return &pb.UnblockIpResponse{Status: "success"}, nil
+}
 
-	// Kafka create topic
-	// Create admin client and topic before serving gRPC
-	adminClient, err := kafka.NewAdminClient(&kafka.ConfigMap{"bootstrap.servers": "broker:9092"})
+// ListBlockedIps returns every blacklisted IP with its remaining TTL
+func (s *server) ListBlockedIps(ctx context.Context, req *pb.ListBlockedIpsRequest) (*pb.ListBlockedIpsResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ts.store.List(ctx)
 	if err != nil {
-		log.Fatalf("Failed to create Kafka admin client: %v", err)
+		log.Printf("Failed to list blocked IPs: %v", err)
+		return nil, err
 	}
-	defer adminClient.Close()
+	resp := &pb.ListBlockedIpsResponse{}
+	for _, e := range entries {
+		resp.BlockedIps = append(resp.BlockedIps, blockedIp(e))
+	}
+	return resp, nil
+}
 
-	// Check and create the topic if it doesn't exist
-	metadata, err := adminClient.GetMetadata(&topic, false, 5000)
+// canonicalIP parses an IP address received by the server into the
+// canonical form used as blacklist key, see ipaddr.Canonical
+func canonicalIP(s string) (string, error) {
+	ip, err := ipaddr.Canonical(s)
 	if err != nil {
-		log.Fatalf("Failed to get metadata: %v", err)
+		return "", status.Error(codes.InvalidArgument, err.Error())
 	}
-	if _, ok := metadata.Topics[topic]; !ok {
-		results, err := adminClient.CreateTopics(
-			context.Background(),
-			[]kafka.TopicSpecification{{Topic: topic, NumPartitions: 3, ReplicationFactor: 2}},
-			nil,
-		)
-		if err != nil {
-			log.Fatalf("Failed to create topic: %v", err)
-		}
-		for _, result := range results {
-			if result.Error.Code() != kafka.ErrNoError {
-				log.Fatalf("Failed to create topic %s: %s", result.Topic, result.Error.String())
+	return ip, nil
+}
+
+// blockedIp converts a store entry to its protobuf representation
+func blockedIp(e store.Entry) *pb.BlockedIp {
+	ttl := int64(-1)
+	if !e.ExpiresAt.IsZero() {
+		ttl = int64(time.Until(e.ExpiresAt).Seconds())
+	}
+	return &pb.BlockedIp{
+		IpAddress:  e.IP,
+		TtlSeconds: ttl,
+		Reason:     e.Reason,
+		CreatedAt:  e.CreatedAt.Unix(),
+	}
+}
+
+// CheckIp reports whether an IP is blacklisted
+func (s *server) CheckIp(ctx context.Context, req *pb.CheckIpRequest) (*pb.CheckIpResponse, error) {
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := canonicalIP(req.GetIpAddress())
+	if err != nil {
+		return nil, err
+	}
+	e, err := ts.store.Get(ctx, ip)
+	if errors.Is(err, store.ErrNotFound) {
+		return &pb.CheckIpResponse{IpAddress: ip}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CheckIpResponse{IpAddress: ip, Blocked: true, Entry: blockedIp(e)}, nil
+}
+
+// GetStats returns the server counters
+func (s *server) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	return &pb.GetStatsResponse{Counters: statsSnapshot()}, nil
+}
+
+// TailDnsRequests streams every DNS request of the tenant processed by the
+// server until the client goes away
+func (s *server) TailDnsRequests(req *pb.TailDnsRequestsRequest, stream pb.DnsService_TailDnsRequestsServer) error {
+	events, done := s.tail.subscribe(tenant.FromContext(stream.Context()))
+	defer done()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev := <-events:
+			if err := stream.Send(ev); err != nil {
+				return err
 			}
 		}
-		log.Printf("Topic %s created successfully", topic)
-	} else {
-		log.Printf("Topic %s already exists", topic)
 	}
+}
 
-	// Kafka producer setup
-	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": "broker:9092"})
+// openPublisher returns the publisher of the configured event bus, after
+// provisioning the topics, backed by the disk spool unless it is disabled
+func openPublisher(cfg config) (bus.Publisher, error) {
+	publisher, err := openBusPublisher(cfg)
+	if err != nil || cfg.spoolDir == "" {
+		return publisher, err
+	}
+	s, err := spool.Open(cfg.spoolDir, cfg.spoolMaxBytes)
 	if err != nil {
-		log.Fatalf("Failed to create Kafka producer: %v", err)
+		publisher.Close()
+		return nil, fmt.Errorf("failed to open spool: %w", err)
 	}
-	defer producer.Close()
+	return newSpooledPublisher(publisher, s, cfg.deadLetterTopic), nil
+}
 
-	// Start producer delivery report handler in a separate goroutine
-	go func() {
-		for e := range producer.Events() {
-			switch ev := e.(type) {
-			case *kafka.Message:
-				if ev.TopicPartition.Error != nil {
-					log.Printf("Delivery failed: %v\n", ev.TopicPartition)
-				} else {
-					log.Printf("Delivered message to %v\n", ev.TopicPartition)
-				}
-			}
+// openBusPublisher returns the publisher of the configured event bus, after
+// provisioning the topics
+func openBusPublisher(cfg config) (bus.Publisher, error) {
+	specs := defaultTopicSpecs
+	if cfg.topicsConfig != "" {
+		var err error
+		if specs, err = topics.LoadSpecs(cfg.topicsConfig); err != nil {
+			return nil, err
+		}
+	}
+	specs = withTenantTopics(specs, cfg.tenants)
+
+	switch cfg.bus {
+	case "kafka":
+		if err := provisionTopics(cfg.kafkaBootstrapServers, specs); err != nil {
+			return nil, err
+		}
+		return bus.NewKafkaPublisher(cfg.kafkaBootstrapServers)
+	case "memory":
+		log.Printf("Using the in-memory event bus, requests are not sent to consumers")
+		memory := bus.NewMemory(1)
+		for _, spec := range specs {
+			memory.CreateTopic(spec.Name, spec.Partitions)
 		}
-	}()
+		return memory.Publisher(), nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %q", cfg.bus)
+	}
+}
+
+// defaultTopicSpecs are provisioned when TOPICS_CONFIG is not set
+var defaultTopicSpecs = []topics.Spec{
+	{Name: topic, Partitions: 3, ReplicationFactor: 2},
+	{Name: topic + ".dlq", Partitions: 1, ReplicationFactor: 2},
+}
+
+// withTenantTopics returns specs with the topics of the tenants of cfg that
+// specs do not declare, with the partitions and replication factor of the
+// shared topic.
+func withTenantTopics(specs []topics.Spec, cfg *tenant.Config) []topics.Spec {
+	declared := make(map[string]bool)
+	for _, spec := range specs {
+		declared[spec.Name] = true
+	}
+	template := defaultTopicSpecs[0]
+	for _, spec := range specs {
+		if spec.Name == topic {
+			template = spec
+		}
+	}
+	for _, id := range cfg.IDs() {
+		name := cfg.Topic(id, topic)
+		if declared[name] {
+			continue
+		}
+		declared[name] = true
+		spec := template
+		spec.Name = name
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// provisionTopics creates or reconciles the Kafka topics and logs the changes
+func provisionTopics(bootstrapServers string, specs []topics.Spec) error {
+	admin, err := topics.NewKafkaAdmin(bootstrapServers)
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka admin client: %w", err)
+	}
+	defer admin.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	changes, err := topics.Provision(ctx, admin, specs)
+	for _, change := range changes {
+		log.Printf("Topic provisioning: %v", change)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		log.Printf("Topics are up to date")
+	}
+	return nil
+}
+
+func main() {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Blacklist store setup
+	blacklist, err := store.Open(cfg.store)
+	if err != nil {
+		log.Fatalf("Failed to open %s blacklist store: %v", cfg.store.Backend, err)
+	}
+	defer blacklist.Close()
+	if r, ok := blacklist.(*store.Redis); ok {
+		// Blacklists of earlier releases are kept as raw IP keys
+		n, err := r.MigrateLegacy(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to migrate the legacy blacklist keys: %v", err)
+		}
+		if n > 0 {
+			log.Printf("Imported %d blocked IPs from the legacy blacklist keys", n)
+		}
+	}
+
+	// Event bus setup
+	publisher, err := openPublisher(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create %s publisher: %v", cfg.bus, err)
+	}
+	defer publisher.Close()
+
+	// Start delivery report handler in a separate goroutine
+	go handleDeliveries(publisher, cfg.deadLetterTopic)
 
 	// Start gRPC server
 	listener, err := net.Listen("tcp", port)
 	if err != nil {
 		log.Fatalf("Failed to listen on port %s: %v", port, err)
 	}
-	grpcServer := grpc.NewServer()
-	reflection.Register(grpcServer)
+	srv := &server{
+		store:        blacklist,
+		publisher:    publisher,
+		tail:         newTailHub(),
+		allowlist:    &allowlistCache{},
+		rules:        &ruleCache{},
+		policy:       cfg.failurePolicy,
+		watch:        newBlockJournal(cfg.watchPollInterval),
+		tenantConfig: cfg.tenants,
+	}
+	go srv.allowlist.run(context.Background(), blacklist, cfg.allowlistRefreshInterval)
+	go srv.rules.run(context.Background(), blacklist, cfg.blockRulesRefreshInterval)
+	if cfg.failurePolicy == failSnapshot {
+		srv.snapshot = newSnapshot(tenant.Default)
+		go srv.snapshot.run(context.Background(), blacklist, cfg.snapshotInterval)
+	}
+	srv.tenants, err = openTenants(context.Background(), blacklist, cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up tenants: %v", err)
+	}
+	if cfg.rpzAddr != "" {
+		if err := srv.serveRPZ(context.Background(), cfg); err != nil {
+			log.Fatalf("Failed to serve the RPZ zones: %v", err)
+		}
+	}
 
-	pb.RegisterDnsServiceServer(grpcServer, &server{
-		redisClient: redisClient,
-		producer:    producer,
-	})
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(srv.unaryTenant),
+		grpc.StreamInterceptor(srv.streamTenant),
+	)
+	reflection.Register(grpcServer)
+	pb.RegisterDnsServiceServer(grpcServer, srv)
 
 	log.Printf("Server is listening on %v", port)
 	if err := grpcServer.Serve(listener); err != nil {
@@ -2,72 +2,981 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/deadletter"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/event"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/rpz"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/spool"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
 	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
-	"github.com/go-redis/redis/v8"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
-const (
-	testAddress   = "grpc-server:50051"
-	testRedisAddr = "redis:6379"
-)
+func newTestServer() *server {
+	return &server{
+		store:     store.NewMemory(),
+		tail:      newTailHub(),
+		allowlist: &allowlistCache{},
+		rules:     &ruleCache{},
+		watch:     newBlockJournal(20 * time.Millisecond),
+	}
+}
 
-func TestBlacklistIP(t *testing.T) {
-	// Setup Redis client
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: testRedisAddr,
-	})
-	defer redisClient.Close()
+func TestBlockIpBlocksDnsRequests(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+
+	blockResp, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "192.168.1.70", Reason: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", blockResp.GetStatus())
+
+	req := &pb.DnsRequest{IpAddress: "192.168.1.70", Domain: "test.com", QueryType: pb.QueryType_QUERY_TYPE_A, Timestamp: time.Now().Unix()}
+	resp, err := s.SendDnsRequest(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "blocked", resp.GetStatus())
+
+	checkResp, err := s.CheckIp(ctx, &pb.CheckIpRequest{IpAddress: "192.168.1.70"})
+	require.NoError(t, err)
+	assert.True(t, checkResp.GetBlocked())
+	assert.Equal(t, "test", checkResp.GetEntry().GetReason())
+	assert.Equal(t, int64(-1), checkResp.GetEntry().GetTtlSeconds())
+}
+
+func TestBlockIpWithTtl(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+
+	_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.1", TtlSeconds: 3600})
+	require.NoError(t, err)
+
+	listResp, err := s.ListBlockedIps(ctx, &pb.ListBlockedIpsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listResp.GetBlockedIps(), 1)
+	assert.Equal(t, "10.0.0.1", listResp.GetBlockedIps()[0].GetIpAddress())
+	assert.InDelta(t, 3600, listResp.GetBlockedIps()[0].GetTtlSeconds(), 5)
+}
+
+func TestUnblockIp(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+
+	_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+
+	resp, err := s.UnblockIp(ctx, &pb.UnblockIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp.GetStatus())
+
+	resp, err = s.UnblockIp(ctx, &pb.UnblockIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, "not_found", resp.GetStatus())
+
+	checkResp, err := s.CheckIp(ctx, &pb.CheckIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.False(t, checkResp.GetBlocked())
+}
+
+func TestSendDnsRequestPublishesEvent(t *testing.T) {
+	b := bus.NewMemory(3)
+	subscriber := b.Subscriber("test")
+	require.NoError(t, subscriber.Subscribe([]string{topic}))
+	s := newTestServer()
+	s.publisher = b.Publisher()
+
+	req := &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "test.com", QueryType: pb.QueryType_QUERY_TYPE_A, Timestamp: 42}
+	resp, err := s.SendDnsRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp.GetStatus())
+
+	msg, err := subscriber.Read(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", string(msg.Key))
+	got, err := event.Decode(msg.Value)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(req, got), "got %v", got)
+}
+
+// failingPublisher is a publisher whose delivery reports are written by the
+// test.
+type failingPublisher struct {
+	deliveries chan bus.Delivery
+	published  []*bus.Message
+}
+
+func (p *failingPublisher) Publish(m *bus.Message) error {
+	p.published = append(p.published, m)
+	return nil
+}
+
+func (p *failingPublisher) Deliveries() <-chan bus.Delivery { return p.deliveries }
+func (p *failingPublisher) Flush(timeout time.Duration) int { return 0 }
+func (p *failingPublisher) Close()                          {}
+
+func TestHandleDeliveriesDeadLettersFailures(t *testing.T) {
+	p := &failingPublisher{deliveries: make(chan bus.Delivery, 3)}
+	p.deliveries <- bus.Delivery{Message: &bus.Message{Topic: topic, Partition: 1, Offset: 12, Value: []byte("ok")}}
+	p.deliveries <- bus.Delivery{
+		Message: &bus.Message{Topic: topic, Partition: -1, Offset: -1, Value: []byte("lost")},
+		Err:     errors.New("Local: Message timed out"),
+	}
+	p.deliveries <- bus.Delivery{Message: &bus.Message{Topic: "dlq", Value: []byte("dead")}, Err: errors.New("broker down")}
+	close(p.deliveries)
+
+	handleDeliveries(p, "dlq")
+
+	require.Len(t, p.published, 1, "only the failed message of the main topic is dead-lettered")
+	dead := p.published[0]
+	assert.Equal(t, "dlq", dead.Topic)
+	assert.Equal(t, "lost", string(dead.Value))
+	assert.Equal(t, topic, deadletter.Header(dead, deadletter.HeaderTopic))
+	assert.Equal(t, "Local: Message timed out", deadletter.Header(dead, deadletter.HeaderError))
+	assert.Equal(t, "server", deadletter.Header(dead, deadletter.HeaderSource))
+}
+
+// unavailablePublisher is a publisher refusing messages with ErrUnavailable
+// while down is set.
+type unavailablePublisher struct {
+	bus.Publisher
+	down atomic.Bool
+}
+
+func (p *unavailablePublisher) Publish(m *bus.Message) error {
+	if p.down.Load() {
+		return bus.ErrUnavailable
+	}
+	return p.Publisher.Publish(m)
+}
+
+func TestSpoolReplaysInOrder(t *testing.T) {
+	b := bus.NewMemory(1)
+	subscriber := b.Subscriber("test")
+	require.NoError(t, subscriber.Subscribe([]string{topic}))
+	inner := &unavailablePublisher{Publisher: b.Publisher()}
+	inner.down.Store(true)
+	sp, err := spool.Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	publisher := newSpooledPublisher(inner, sp, "dlq")
+	defer publisher.Close()
+	s := newTestServer()
+	s.publisher = publisher
+
+	send := func(ip string) {
+		resp, err := s.SendDnsRequest(context.Background(), &pb.DnsRequest{IpAddress: ip, Domain: "test.com", QueryType: pb.QueryType_QUERY_TYPE_A})
+		require.NoError(t, err)
+		assert.Equal(t, "success", resp.GetStatus())
+	}
+	send("10.0.0.1")
+	send("10.0.0.2")
+	assert.Equal(t, int64(2), statsSnapshot()["spool_events"])
+	assert.Positive(t, statsSnapshot()["spool_bytes"])
+	_, err = subscriber.Read(50 * time.Millisecond)
+	assert.ErrorIs(t, err, bus.ErrTimeout)
+
+	inner.down.Store(false)
+	send("10.0.0.3")
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		msg, err := subscriber.Read(3 * spoolRetryInterval)
+		require.NoError(t, err)
+		assert.Equal(t, ip, string(msg.Key))
+	}
+	assert.Equal(t, int64(0), statsSnapshot()["spool_events"])
+	assert.Equal(t, int64(0), statsSnapshot()["spool_oldest_age_seconds"])
+}
+
+func TestHandleDeliveriesSpoolsUnreachableFailures(t *testing.T) {
+	b := bus.NewMemory(1)
+	subscriber := b.Subscriber("test")
+	require.NoError(t, subscriber.Subscribe([]string{topic, "dlq"}))
+	b.FailDeliveries(fmt.Errorf("%w: Local: Message timed out", bus.ErrUnavailable))
+	sp, err := spool.Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	publisher := newSpooledPublisher(b.Publisher(), sp, "dlq")
+	defer publisher.Close()
+	go handleDeliveries(publisher, "dlq")
+	s := newTestServer()
+	s.publisher = publisher
+
+	send := func(ip string) {
+		resp, err := s.SendDnsRequest(context.Background(), &pb.DnsRequest{IpAddress: ip, Domain: "test.com", QueryType: pb.QueryType_QUERY_TYPE_A})
+		require.NoError(t, err)
+		assert.Equal(t, "success", resp.GetStatus())
+	}
+	send("10.0.0.1")
+	require.Eventually(t, func() bool { return statsSnapshot()["spool_retry_events"] == 1 }, time.Second, 5*time.Millisecond)
+	_, err = subscriber.Read(50 * time.Millisecond)
+	assert.ErrorIs(t, err, bus.ErrTimeout, "nothing is dead-lettered")
+	// Later requests are spooled behind the failed one
+	send("10.0.0.2")
+	assert.Eventually(t, func() bool {
+		snap := statsSnapshot()
+		return snap["spool_retry_events"]+snap["spool_events"] == 2
+	}, time.Second, 5*time.Millisecond)
+
+	b.FailDeliveries(nil)
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		msg, err := subscriber.Read(3 * spoolRetryInterval)
+		require.NoError(t, err)
+		assert.Equal(t, topic, msg.Topic)
+		assert.Equal(t, ip, string(msg.Key))
+	}
+	assert.Eventually(t, func() bool {
+		snap := statsSnapshot()
+		return snap["spool_retry_events"]+snap["spool_events"] == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSendDnsRequestFailsWhenSpoolIsFull(t *testing.T) {
+	inner := &unavailablePublisher{Publisher: bus.NewMemory(1).Publisher()}
+	inner.down.Store(true)
+	sp, err := spool.Open(t.TempDir(), 1)
+	require.NoError(t, err)
+	publisher := newSpooledPublisher(inner, sp, "dlq")
+	defer publisher.Close()
+	s := newTestServer()
+	s.publisher = publisher
+
+	resp, err := s.SendDnsRequest(context.Background(), &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "test.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "failed", resp.GetStatus())
+}
+
+// faultyStore is a store failing every call while failing is set.
+type faultyStore struct {
+	store.Store
+	failing atomic.Bool
+}
+
+var errStoreDown = errors.New("store down")
+
+func (s *faultyStore) Get(ctx context.Context, ip string) (store.Entry, error) {
+	if s.failing.Load() {
+		return store.Entry{}, errStoreDown
+	}
+	return s.Store.Get(ctx, ip)
+}
+
+func (s *faultyStore) List(ctx context.Context) ([]store.Entry, error) {
+	if s.failing.Load() {
+		return nil, errStoreDown
+	}
+	return s.Store.List(ctx)
+}
+
+func TestFailurePolicies(t *testing.T) {
+	tests := []struct {
+		policy      failurePolicy
+		snapshot    bool
+		blacklisted *pb.DnsResponse
+		clean       *pb.DnsResponse
+		counter     string
+	}{
+		{failOpen, false, &pb.DnsResponse{Status: "success", Reason: reasonFailOpen}, &pb.DnsResponse{Status: "success", Reason: reasonFailOpen}, "verdicts_fail_open"},
+		{failClosed, false, &pb.DnsResponse{Status: "blocked", Reason: reasonFailClosed}, &pb.DnsResponse{Status: "blocked", Reason: reasonFailClosed}, "verdicts_fail_closed"},
+		{failSnapshot, true, &pb.DnsResponse{Status: "blocked", Reason: reasonSnapshotBlacklist}, &pb.DnsResponse{Status: "success", Reason: reasonSnapshotClean}, "verdicts_snapshot"},
+		{failSnapshot, false, &pb.DnsResponse{Status: "blocked", Reason: reasonNoSnapshot}, &pb.DnsResponse{Status: "blocked", Reason: reasonNoSnapshot}, "verdicts_fail_closed"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/snapshot=%v", tt.policy, tt.snapshot), func(t *testing.T) {
+			ctx := context.Background()
+			st := &faultyStore{Store: store.NewMemory()}
+			s := newTestServer()
+			s.store = st
+			s.publisher = bus.NewMemory(1).Publisher()
+			s.policy = tt.policy
+			if tt.policy == failSnapshot {
+				s.snapshot = newSnapshot(tenant.Default)
+			}
+			if tt.snapshot {
+				require.NoError(t, s.snapshot.refresh(ctx, st))
+			}
+			_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.1"})
+			require.NoError(t, err)
+
+			resp, err := s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "test.com"})
+			require.NoError(t, err)
+			assert.Equal(t, "blocked", resp.GetStatus())
+			assert.Equal(t, reasonBlacklisted, resp.GetReason())
+
+			st.failing.Store(true)
+			before := statsSnapshot()[tt.counter]
+			resp, err = s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "test.com"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.blacklisted.String(), resp.String())
+			resp, err = s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "10.0.0.2", Domain: "test.com"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.clean.String(), resp.String())
+			assert.Equal(t, before+2, statsSnapshot()[tt.counter])
+
+			if tt.snapshot {
+				// A failed refresh keeps the previous snapshot
+				assert.Error(t, s.snapshot.refresh(ctx, st))
+				blocked, ok := s.snapshot.blocked("10.0.0.1", time.Now())
+				assert.True(t, ok)
+				assert.True(t, blocked)
+			}
+		})
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	s := newTestServer()
+	s.publisher = bus.NewMemory(1).Publisher()
+	ctx := context.Background()
+
+	_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "192.168.1.70"})
+	require.NoError(t, err)
+
+	for _, value := range []string{"10.1.2.3/8", "Example.com."} {
+		resp, err := s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: value, Reason: "infrastructure"})
+		require.NoError(t, err)
+		assert.Equal(t, "success", resp.GetStatus())
+	}
+	_, err = s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: "not a value"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := s.ListAllowlist(ctx, &pb.ListAllowlistRequest{})
+	require.NoError(t, err)
+	var values []string
+	for _, e := range list.GetEntries() {
+		values = append(values, e.GetKind()+" "+e.GetValue())
+	}
+	assert.ElementsMatch(t, []string{"cidr 10.0.0.0/8", "domain example.com"}, values)
+
+	_, err = s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.20.30.40"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Blacklisted IPs stay blocked, even for allowlisted domains
+	resp, err := s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "192.168.1.70", Domain: "www.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "blocked", resp.GetStatus())
+	assert.Equal(t, reasonBlacklisted, resp.GetReason())
+	resp, err = s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "192.168.1.71", Domain: "www.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp.GetStatus())
+	assert.Equal(t, reasonAllowlisted, resp.GetReason())
+
+	// An allowlisted domain overrides the rules scoped to domains only
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{DomainPattern: "*.example.org"})
+	require.NoError(t, err)
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "192.168.2.0/24"})
+	require.NoError(t, err)
+	_, err = s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: "www.example.org"})
+	require.NoError(t, err)
+	resp, err = s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "192.168.1.71", Domain: "www.example.org"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp.GetStatus())
+	resp, err = s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "192.168.1.71", Domain: "api.example.org"})
+	require.NoError(t, err)
+	assert.Equal(t, "blocked", resp.GetStatus())
+	resp, err = s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "192.168.2.1", Domain: "www.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "blocked", resp.GetStatus())
+	assert.Equal(t, reasonBlockRule, resp.GetReason())
+
+	// Allowlisted IPs are never blocked
+	resp, err = s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "api.example.org"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp.GetStatus())
+	assert.Equal(t, reasonAllowlisted, resp.GetReason())
+
+	removed, err := s.RemoveAllowlistEntry(ctx, &pb.RemoveAllowlistEntryRequest{Value: "10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", removed.GetStatus())
+	removed, err = s.RemoveAllowlistEntry(ctx, &pb.RemoveAllowlistEntryRequest{Value: "10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.Equal(t, "not_found", removed.GetStatus())
+	_, err = s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.20.30.40"})
+	assert.NoError(t, err)
+}
+
+func TestIpAddressesAreCanonicalized(t *testing.T) {
+	s := newTestServer()
+	s.publisher = bus.NewMemory(1).Publisher()
+	ctx := context.Background()
+
+	_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "::ffff:192.168.1.70"})
+	require.NoError(t, err)
+	_, err = s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "2001:DB8::1"})
+	require.NoError(t, err)
+
+	resp, err := s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "192.168.1.70", Domain: "test.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "blocked", resp.GetStatus())
+	check, err := s.CheckIp(ctx, &pb.CheckIpRequest{IpAddress: "2001:db8:0::1"})
+	require.NoError(t, err)
+	assert.True(t, check.GetBlocked())
+	assert.Equal(t, "2001:db8::1", check.GetIpAddress())
+
+	list, err := s.ListBlockedIps(ctx, &pb.ListBlockedIpsRequest{})
+	require.NoError(t, err)
+	var ips []string
+	for _, b := range list.GetBlockedIps() {
+		ips = append(ips, b.GetIpAddress())
+	}
+	assert.ElementsMatch(t, []string{"192.168.1.70", "2001:db8::1"}, ips)
 
-	// Flush the Redis database
-	err := redisClient.FlushDB(redisClient.Context()).Err()
-	if err != nil {
-		t.Fatalf("Failed to flush Redis database: %v", err)
+	unblock, err := s.UnblockIp(ctx, &pb.UnblockIpRequest{IpAddress: "::FFFF:C0A8:146"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", unblock.GetStatus())
+
+	for _, ip := range []string{"", "garbage", "192.168.001.070", "fe80::1%eth0"} {
+		_, err := s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: ip, Domain: "test.com"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), ip)
+		_, err = s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: ip})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), ip)
+		_, err = s.UnblockIp(ctx, &pb.UnblockIpRequest{IpAddress: ip})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), ip)
+		_, err = s.CheckIp(ctx, &pb.CheckIpRequest{IpAddress: ip})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), ip)
 	}
+}
+
+func TestSendDnsRequestNormalizesDomainsAndQueryTypes(t *testing.T) {
+	b := bus.NewMemory(1)
+	subscriber := b.Subscriber("test")
+	require.NoError(t, subscriber.Subscribe([]string{topic}))
+	s := newTestServer()
+	s.publisher = b.Publisher()
+	ctx := context.Background()
 
-	// Connect to the gRPC server
-	conn, err := grpc.NewClient(testAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		t.Fatalf("Failed to connect: %v", err)
+	for _, tt := range []struct {
+		domain string
+		qtype  pb.QueryType
+		want   string
+	}{
+		{"WWW.Bücher.Example.", pb.QueryType_QUERY_TYPE_TXT, "www.xn--bcher-kva.example"},
+		{"_dmarc.example.com", pb.QueryType(65534), "_dmarc.example.com"},
+	} {
+		req := &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: tt.domain, QueryType: tt.qtype}
+		_, err := s.SendDnsRequest(ctx, req)
+		require.NoError(t, err)
+		msg, err := subscriber.Read(time.Second)
+		require.NoError(t, err)
+		got, err := event.Decode(msg.Value)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got.GetDomain())
+		assert.Equal(t, tt.qtype, got.GetQueryType())
 	}
-	defer conn.Close()
 
-	client := pb.NewDnsServiceClient(conn)
+	for _, domain := range []string{"", "a..b", "exa mple.com", strings.Repeat("a", 64) + ".com"} {
+		_, err := s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: domain})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), domain)
+	}
+	_, err := s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "test.com", QueryType: pb.QueryType(70000)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
 
-	// Define the test IP address
-	testIPAddress := "192.168.1.70"
+func TestSendDnsRequestNormalizesTelemetry(t *testing.T) {
+	b := bus.NewMemory(1)
+	subscriber := b.Subscriber("test")
+	require.NoError(t, subscriber.Subscribe([]string{topic}))
+	s := newTestServer()
+	s.publisher = b.Publisher()
+	ctx := context.Background()
 
-	// First connection should succeed
 	req := &pb.DnsRequest{
-		IpAddress: testIPAddress,
-		Domain:    "test.com",
-		QueryType: "A",
-		Timestamp: time.Now().Unix(),
+		IpAddress: "10.0.0.1",
+		Domain:    "example.com",
+		QueryType: pb.QueryType_QUERY_TYPE_AAAA,
+		Rcode:     pb.ResponseCode_RESPONSE_CODE_NOERROR.Enum(),
+		Answers: []*pb.Answer{
+			{Type: pb.QueryType_QUERY_TYPE_CNAME, Data: "cdn.example.net.", Ttl: 60},
+			{Type: pb.QueryType_QUERY_TYPE_AAAA, Data: "2001:DB8::1", Ttl: 300},
+		},
+		ClientPort:       53000,
+		Transport:        pb.Transport_TRANSPORT_DOT,
+		QuerySize:        40,
+		ResponseSize:     120,
+		EdnsClientSubnet: "203.0.113.77/24",
+		SensorId:         "resolver-1",
+	}
+	_, err := s.SendDnsRequest(ctx, req)
+	require.NoError(t, err)
+	msg, err := subscriber.Read(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, event.ContentType, deadletter.Header(msg, "content-type"))
+	got, err := event.Decode(msg.Value)
+	require.NoError(t, err)
+	assert.Equal(t, pb.ResponseCode_RESPONSE_CODE_NOERROR, got.GetRcode())
+	require.Len(t, got.GetAnswers(), 2)
+	assert.Equal(t, "2001:db8::1", got.GetAnswers()[1].GetData())
+	assert.Equal(t, uint32(300), got.GetAnswers()[1].GetTtl())
+	assert.Equal(t, "203.0.113.0/24", got.GetEdnsClientSubnet())
+	assert.Equal(t, pb.Transport_TRANSPORT_DOT, got.GetTransport())
+	assert.Equal(t, uint32(120), got.GetResponseSize())
+	assert.Equal(t, "resolver-1", got.GetSensorId())
+
+	for name, req := range map[string]*pb.DnsRequest{
+		"rcode":  {Rcode: pb.ResponseCode(5000).Enum()},
+		"A data": {Answers: []*pb.Answer{{Type: pb.QueryType_QUERY_TYPE_A, Data: "2001:db8::1"}}},
+		"port":   {ClientPort: 70000},
+		"ecs":    {EdnsClientSubnet: "203.0.113.0"},
+		"sensor": {SensorId: strings.Repeat("s", 256)},
+	} {
+		req.IpAddress, req.Domain = "10.0.0.1", "example.com"
+		_, err := s.SendDnsRequest(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+	}
+}
+
+func TestTenantsAreIsolated(t *testing.T) {
+	cfg, err := tenant.New([]tenant.Tenant{
+		{ID: "payments", Tokens: []string{"payments-token"}, Topic: "myTopic.payments"},
+		{ID: "retail"},
+	})
+	require.NoError(t, err)
+	b := bus.NewMemory(1)
+	subscriber := b.Subscriber("test")
+	require.NoError(t, subscriber.Subscribe([]string{"myTopic.payments"}))
+	s := newTestServer()
+	s.publisher = b.Publisher()
+	s.tenantConfig = cfg
+	s.tenants, err = openTenants(context.Background(), s.store, config{tenants: cfg, allowlistRefreshInterval: time.Hour, blockRulesRefreshInterval: time.Hour, watchPollInterval: time.Hour})
+	require.NoError(t, err)
+
+	// The tenant comes from the token, or from the metadata of tenants
+	// without tokens
+	incoming := func(kv ...string) context.Context {
+		ctx, err := s.withTenant(metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...)))
+		require.NoError(t, err)
+		return ctx
+	}
+	payments := incoming("authorization", "Bearer payments-token")
+	retail := incoming(tenant.MetadataKey, "retail")
+	assert.Equal(t, "payments", tenant.FromContext(payments))
+	assert.Equal(t, tenant.Default, tenant.FromContext(incoming()))
+	for code, md := range map[codes.Code]metadata.MD{
+		codes.Unauthenticated:  metadata.Pairs(tenant.MetadataKey, "payments"),
+		codes.PermissionDenied: metadata.Pairs(tenant.MetadataKey, "retail", "authorization", "Bearer payments-token"),
+		codes.InvalidArgument:  metadata.Pairs(tenant.MetadataKey, "Not A Tenant"),
+	} {
+		_, err := s.withTenant(metadata.NewIncomingContext(context.Background(), md))
+		assert.Equal(t, code, status.Code(err), md)
+	}
+
+	_, err = s.BlockIp(payments, &pb.BlockIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	for ctx, want := range map[context.Context]bool{payments: true, retail: false, context.Background(): false} {
+		check, err := s.CheckIp(ctx, &pb.CheckIpRequest{IpAddress: "10.0.0.1"})
+		require.NoError(t, err)
+		assert.Equal(t, want, check.GetBlocked(), tenant.FromContext(ctx))
+		list, err := s.ListBlockedIps(ctx, &pb.ListBlockedIpsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, want, len(list.GetBlockedIps()) == 1, tenant.FromContext(ctx))
 	}
-	resp, err := client.SendDnsRequest(context.Background(), req)
+
+	// Allowlists are per tenant too
+	_, err = s.AddAllowlistEntry(retail, &pb.AddAllowlistEntryRequest{Value: "10.0.0.2"})
+	require.NoError(t, err)
+	_, err = s.BlockIp(retail, &pb.BlockIpRequest{IpAddress: "10.0.0.2"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = s.BlockIp(payments, &pb.BlockIpRequest{IpAddress: "10.0.0.2"})
 	assert.NoError(t, err)
+
+	// Requests go to the topic of their tenant, with their tenant header
+	resp, err := s.SendDnsRequest(payments, &pb.DnsRequest{IpAddress: "10.0.0.3", Domain: "test.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp.GetStatus())
+	msg, err := subscriber.Read(time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Headers, bus.Header{Key: tenant.HeaderKey, Value: []byte("payments")})
+	resp, err = s.SendDnsRequest(retail, &pb.DnsRequest{IpAddress: "10.0.0.1", Domain: "test.com"})
+	require.NoError(t, err)
 	assert.Equal(t, "success", resp.GetStatus())
+}
+
+func TestBlockRules(t *testing.T) {
+	s := newTestServer()
+	s.publisher = bus.NewMemory(1).Publisher()
+	ctx := context.Background()
+
+	added, err := s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "10.0.0.1", DomainPattern: "*.C2.example", Reason: "c2 beacon"})
+	require.NoError(t, err)
+	assert.Equal(t, "*.c2.example", added.GetRule().GetDomainPattern())
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "10.0.0.0/8", QueryType: pb.QueryType_QUERY_TYPE_TXT, TtlSeconds: 3600})
+	require.NoError(t, err)
 
-	// Block the IP address
-	blockReq := &pb.BlockIpRequest{
-		IpAddress: testIPAddress,
+	send := func(ip, domain string, qt pb.QueryType) *pb.DnsResponse {
+		resp, err := s.SendDnsRequest(ctx, &pb.DnsRequest{IpAddress: ip, Domain: domain, QueryType: qt})
+		require.NoError(t, err)
+		return resp
 	}
-	blockResp, err := client.BlockIp(context.Background(), blockReq)
-	assert.NoError(t, err)
-	assert.Equal(t, "success", blockResp.GetStatus())
+	resp := send("10.0.0.1", "beacon.c2.example", pb.QueryType_QUERY_TYPE_A)
+	assert.Equal(t, "blocked", resp.GetStatus())
+	assert.Equal(t, reasonBlockRule, resp.GetReason())
+	assert.Equal(t, added.GetRule().GetId(), resp.GetRule().GetId())
+	assert.Equal(t, "c2 beacon", resp.GetRule().GetReason())
+	assert.Equal(t, "success", send("10.0.0.1", "example.com", pb.QueryType_QUERY_TYPE_A).GetStatus())
+	assert.Equal(t, "success", send("10.0.0.2", "beacon.c2.example", pb.QueryType_QUERY_TYPE_A).GetStatus())
+	assert.Equal(t, "blocked", send("10.0.0.2", "example.com", pb.QueryType_QUERY_TYPE_TXT).GetStatus())
+	assert.Equal(t, "success", send("11.0.0.1", "example.com", pb.QueryType_QUERY_TYPE_TXT).GetStatus())
 
-	// Second connection should be blocked
-	resp, err = client.SendDnsRequest(context.Background(), req)
+	list, err := s.ListBlockRules(ctx, &pb.ListBlockRulesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetRules(), 2)
+
+	// The allowlist overrides rules, and rules it would override are
+	// rejected
+	_, err = s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: "10.0.0.2"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", send("10.0.0.2", "example.com", pb.QueryType_QUERY_TYPE_TXT).GetStatus())
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "10.0.0.2", DomainPattern: "example.com"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	for _, source := range []string{"10.0.0.0/24", "::ffff:10.0.0.0/120"} {
+		_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: source, DomainPattern: "example.com"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err), source)
+	}
+	_, err = s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: "192.168.0.0/16"})
+	require.NoError(t, err)
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "192.168.1.0/24"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "192.0.0.0/8", QueryType: pb.QueryType_QUERY_TYPE_TXT})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "10.0.1.0/24", DomainPattern: "example.com"})
 	assert.NoError(t, err)
+
+	for _, req := range []*pb.AddBlockRuleRequest{
+		{},
+		{Source: "example.com"},
+		{DomainPattern: "bad..example"},
+		{Source: "10.0.0.1", QueryType: pb.QueryType(70000)},
+	} {
+		_, err := s.AddBlockRule(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), req)
+	}
+
+	removed, err := s.RemoveBlockRule(ctx, &pb.RemoveBlockRuleRequest{Id: added.GetRule().GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, "success", removed.GetStatus())
+	assert.Equal(t, "success", send("10.0.0.1", "beacon.c2.example", pb.QueryType_QUERY_TYPE_A).GetStatus())
+	removed, err = s.RemoveBlockRule(ctx, &pb.RemoveBlockRuleRequest{Id: added.GetRule().GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, "not_found", removed.GetStatus())
+}
+
+func TestExplainVerdict(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+	_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.1", Reason: "scanner", TtlSeconds: 3600})
+	require.NoError(t, err)
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{Source: "10.0.0.0/8", QueryType: pb.QueryType_QUERY_TYPE_TXT})
+	require.NoError(t, err)
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{DomainPattern: "*.evil.com", Reason: "c2"})
+	require.NoError(t, err)
+
+	type match struct {
+		kind     string
+		decisive bool
+	}
+	explain := func(ip, domain string, qt pb.QueryType) (*pb.ExplainVerdictResponse, []match) {
+		resp, err := s.ExplainVerdict(ctx, &pb.ExplainVerdictRequest{IpAddress: ip, Domain: domain, QueryType: qt})
+		require.NoError(t, err)
+		var matches []match
+		for _, m := range resp.GetMatches() {
+			matches = append(matches, match{m.GetKind(), m.GetDecisive()})
+		}
+		return resp, matches
+	}
+	requests := statsSnapshot()["dns_requests"]
+
+	resp, matches := explain("::ffff:10.0.0.1", "A.Evil.com", pb.QueryType_QUERY_TYPE_TXT)
 	assert.Equal(t, "blocked", resp.GetStatus())
+	assert.Equal(t, reasonBlacklisted, resp.GetReason())
+	assert.Equal(t, "10.0.0.1", resp.GetIpAddress())
+	assert.Equal(t, "a.evil.com", resp.GetDomain())
+	assert.Equal(t, []match{{"blacklist_ip", true}, {"block_rule_cidr", false}, {"block_rule_any_source", false}}, matches)
+	assert.Equal(t, "scanner", resp.GetMatches()[0].GetReason())
+	assert.InDelta(t, 3600, resp.GetMatches()[0].GetTtlSeconds(), 5)
+	assert.Equal(t, "c2", resp.GetMatches()[2].GetRule().GetReason())
 
-	// Third connection should also be blocked
-	resp, err = client.SendDnsRequest(context.Background(), req)
-	assert.NoError(t, err)
+	resp, matches = explain("10.0.0.2", "example.com", pb.QueryType_QUERY_TYPE_TXT)
 	assert.Equal(t, "blocked", resp.GetStatus())
+	assert.Equal(t, reasonBlockRule, resp.GetReason())
+	assert.Equal(t, []match{{"block_rule_cidr", true}}, matches)
+
+	resp, matches = explain("11.0.0.1", "example.com", pb.QueryType_QUERY_TYPE_A)
+	assert.Equal(t, "success", resp.GetStatus())
+	assert.Empty(t, matches)
+
+	// An allowlisted domain overrides the rules scoped to domains, not the
+	// blacklist nor the other rules
+	_, err = s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: "evil.com"})
+	require.NoError(t, err)
+	resp, matches = explain("10.0.0.1", "a.evil.com", pb.QueryType_QUERY_TYPE_TXT)
+	assert.Equal(t, "blocked", resp.GetStatus())
+	assert.Equal(t, reasonBlacklisted, resp.GetReason())
+	assert.Equal(t, []match{{"allowlist_domain", false}, {"blacklist_ip", true}, {"block_rule_cidr", false}, {"block_rule_any_source", false}}, matches)
+	resp, matches = explain("10.0.0.2", "a.evil.com", pb.QueryType_QUERY_TYPE_TXT)
+	assert.Equal(t, "blocked", resp.GetStatus())
+	assert.Equal(t, reasonBlockRule, resp.GetReason())
+	assert.Equal(t, []match{{"allowlist_domain", false}, {"block_rule_cidr", true}, {"block_rule_any_source", false}}, matches)
+	resp, matches = explain("10.0.0.2", "a.evil.com", pb.QueryType_QUERY_TYPE_A)
+	assert.Equal(t, "success", resp.GetStatus())
+	assert.Equal(t, reasonAllowlisted, resp.GetReason())
+	assert.Equal(t, []match{{"allowlist_domain", true}, {"block_rule_any_source", false}}, matches)
+
+	// An allowlisted IP overrides every other match
+	_, err = s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: "10.0.0.1"})
+	require.NoError(t, err)
+	resp, matches = explain("10.0.0.1", "a.evil.com", pb.QueryType_QUERY_TYPE_TXT)
+	assert.Equal(t, "success", resp.GetStatus())
+	assert.Equal(t, reasonAllowlisted, resp.GetReason())
+	assert.Equal(t, []match{{"allowlist_ip", true}, {"allowlist_domain", false}, {"blacklist_ip", false}, {"block_rule_cidr", false}, {"block_rule_any_source", false}}, matches)
+
+	// Explanations are neither counted nor published
+	assert.Equal(t, requests, statsSnapshot()["dns_requests"])
+
+	_, err = s.ExplainVerdict(ctx, &pb.ExplainVerdictRequest{IpAddress: "garbage", Domain: "example.com"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// watchStream is a WatchBlocks stream forwarding the events to a channel.
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *pb.WatchBlocksResponse
+}
+
+func (w *watchStream) Context() context.Context { return w.ctx }
+
+func (w *watchStream) Send(ev *pb.WatchBlocksResponse) error {
+	w.events <- ev
+	return nil
+}
+
+func TestWatchBlocks(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+	_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.1", Reason: "before"})
+	require.NoError(t, err)
+
+	watch := func(token string) (*watchStream, context.CancelFunc, chan error) {
+		wctx, cancel := context.WithCancel(ctx)
+		w := &watchStream{ctx: wctx, events: make(chan *pb.WatchBlocksResponse, 16)}
+		done := make(chan error, 1)
+		go func() { done <- s.WatchBlocks(&pb.WatchBlocksRequest{ResumeToken: token}, w) }()
+		return w, cancel, done
+	}
+	next := func(w *watchStream) *pb.WatchBlocksResponse {
+		select {
+		case ev := <-w.events:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("no block event")
+			return nil
+		}
+	}
+
+	// The stream starts with a snapshot of the blocks
+	w, cancel, done := watch("")
+	ev := next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT, ev.GetType())
+	assert.Equal(t, "10.0.0.1", ev.GetBlockedIp().GetIpAddress())
+	ev = next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT_END, ev.GetType())
+	assert.NotEmpty(t, ev.GetResumeToken())
+
+	// Then every change, with a resume token
+	_, err = s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.2", Reason: "added", TtlSeconds: 1})
+	require.NoError(t, err)
+	ev = next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_ADDED, ev.GetType())
+	assert.Equal(t, "10.0.0.2", ev.GetBlockedIp().GetIpAddress())
+	assert.Equal(t, "added", ev.GetBlockedIp().GetReason())
+	token := ev.GetResumeToken()
+
+	rule, err := s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{DomainPattern: "*.evil.com", Reason: "rule"})
+	require.NoError(t, err)
+	ev = next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_ADDED, ev.GetType())
+	assert.Equal(t, rule.GetRule().GetId(), ev.GetRule().GetId())
+
+	_, err = s.UnblockIp(ctx, &pb.UnblockIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	ev = next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_REMOVED, ev.GetType())
+	assert.Equal(t, "10.0.0.1", ev.GetBlockedIp().GetIpAddress())
+
+	ev = next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_EXPIRED, ev.GetType())
+	assert.Equal(t, "10.0.0.2", ev.GetBlockedIp().GetIpAddress())
+	cancel()
+	assert.NoError(t, <-done)
+
+	// A resumed stream replays the events following its token, without a
+	// snapshot
+	w, cancel, done = watch(token)
+	ev = next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_ADDED, ev.GetType())
+	assert.Equal(t, rule.GetRule().GetId(), ev.GetRule().GetId())
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_REMOVED, next(w).GetType())
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_EXPIRED, next(w).GetType())
+	cancel()
+	assert.NoError(t, <-done)
+
+	// An unknown token gets a snapshot
+	w, cancel, done = watch("other.1")
+	ev = next(w)
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT, ev.GetType())
+	assert.Equal(t, rule.GetRule().GetId(), ev.GetRule().GetId())
+	assert.Equal(t, pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT_END, next(w).GetType())
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestRPZFollowsBlocks(t *testing.T) {
+	s := newTestServer()
+	ctx, cancel := context.WithCancel(tenant.NewContext(context.Background(), tenant.Default))
+	defer cancel()
+	ts, err := s.scope(ctx)
+	require.NoError(t, err)
+	zone, err := rpz.NewZone(rpzOrigin(tenant.Default, config{rpzZone: "rpz.test"}), rpz.ActionNXDOMAIN, 1)
+	require.NoError(t, err)
+	go s.followRPZ(ctx, ts, zone, nil)
+
+	_, err = s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	_, err = s.AddBlockRule(ctx, &pb.AddBlockRuleRequest{DomainPattern: "*.evil.com"})
+	require.NoError(t, err)
+	names := func() []string {
+		var names []string
+		for _, rr := range zone.AXFR() {
+			if rr.Header().Rrtype == dns.TypeCNAME {
+				names = append(names, rr.Header().Name)
+			}
+		}
+		return names
+	}
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"*.evil.com.rpz.test.", "32.1.0.0.10.rpz-client-ip.rpz.test."}, names())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	serial := zone.Serial()
+	_, err = s.UnblockIp(ctx, &pb.UnblockIpRequest{IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(names()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Greater(t, zone.Serial(), serial)
+	assert.Equal(t, "tenant-a.rpz.test.", rpzOrigin("tenant-a", config{rpzZone: "rpz.test."}))
+}
+
+// exportStream is an ExportBlocklist stream collecting the file.
+type exportStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	data []byte
+}
+
+func (e *exportStream) Context() context.Context { return e.ctx }
+
+func (e *exportStream) Send(resp *pb.ExportBlocklistResponse) error {
+	e.data = append(e.data, resp.GetData()...)
+	return nil
+}
+
+// importStream is an ImportBlocklist stream sending a file in two chunks.
+type importStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []*pb.ImportBlocklistRequest
+	resp *pb.ImportBlocklistResponse
+}
+
+func newImportStream(ctx context.Context, mode pb.ImportMode, dryRun bool, data string) *importStream {
+	half := len(data) / 2
+	return &importStream{ctx: ctx, reqs: []*pb.ImportBlocklistRequest{
+		{Format: pb.BlocklistFormat_BLOCKLIST_FORMAT_CSV, Mode: mode, DryRun: dryRun, Data: []byte(data[:half])},
+		{Data: []byte(data[half:])},
+	}}
+}
+
+func (i *importStream) Context() context.Context { return i.ctx }
+
+func (i *importStream) Recv() (*pb.ImportBlocklistRequest, error) {
+	if len(i.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := i.reqs[0]
+	i.reqs = i.reqs[1:]
+	return req, nil
+}
+
+func (i *importStream) SendAndClose(resp *pb.ImportBlocklistResponse) error {
+	i.resp = resp
+	return nil
+}
+
+func TestExportImportBlocklist(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+	_, err := s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.1", Reason: "scan", TtlSeconds: 3600})
+	require.NoError(t, err)
+	_, err = s.BlockIp(ctx, &pb.BlockIpRequest{IpAddress: "10.0.0.2", Reason: "old"})
+	require.NoError(t, err)
+	_, err = s.AddAllowlistEntry(ctx, &pb.AddAllowlistEntryRequest{Value: "192.168.0.0/16"})
+	require.NoError(t, err)
+
+	export := &exportStream{ctx: ctx}
+	require.NoError(t, s.ExportBlocklist(&pb.ExportBlocklistRequest{Format: pb.BlocklistFormat_BLOCKLIST_FORMAT_CSV}, export))
+	lines := strings.Split(strings.TrimSpace(string(export.data)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "ip_address,reason,created_at,ttl_seconds", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "10.0.0.1,scan,"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[1], ",3600"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], ",-1"), lines[2])
+
+	// A dry run reports the changes without applying them
+	file := "ip_address,reason,ttl_seconds\n10.0.0.1,scan,3600\n10.0.0.2,new,-1\n10.0.0.3,,\n192.168.1.1,,\n"
+	dry := newImportStream(ctx, pb.ImportMode_IMPORT_MODE_REPLACE, true, file)
+	require.NoError(t, s.ImportBlocklist(dry))
+	assert.Equal(t, "dry_run", dry.resp.GetStatus())
+	assert.EqualValues(t, 1, dry.resp.GetAdded())
+	assert.EqualValues(t, 1, dry.resp.GetUpdated())
+	assert.EqualValues(t, 1, dry.resp.GetUnchanged())
+	assert.EqualValues(t, 1, dry.resp.GetSkipped())
+	var actions []string
+	for _, c := range dry.resp.GetChanges() {
+		actions = append(actions, c.GetEntry().GetIpAddress()+" "+c.GetAction())
+	}
+	assert.Equal(t, []string{"10.0.0.2 update", "10.0.0.3 add", "192.168.1.1 skip"}, actions)
+	assert.Equal(t, "old", dry.resp.GetChanges()[0].GetPrevious().GetReason())
+	check, err := s.CheckIp(ctx, &pb.CheckIpRequest{IpAddress: "10.0.0.3"})
+	require.NoError(t, err)
+	assert.False(t, check.GetBlocked())
+
+	// Replacing removes the entries missing from the file
+	replace := newImportStream(ctx, pb.ImportMode_IMPORT_MODE_REPLACE, false, "ip_address\n10.0.0.3\n")
+	require.NoError(t, s.ImportBlocklist(replace))
+	assert.Equal(t, "success", replace.resp.GetStatus())
+	assert.EqualValues(t, 1, replace.resp.GetAdded())
+	assert.EqualValues(t, 2, replace.resp.GetRemoved())
+	list, err := s.ListBlockedIps(ctx, &pb.ListBlockedIpsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetBlockedIps(), 1)
+	assert.Equal(t, "10.0.0.3", list.GetBlockedIps()[0].GetIpAddress())
+
+	// Merging keeps them
+	merge := newImportStream(ctx, pb.ImportMode_IMPORT_MODE_MERGE, false, "ip_address\n10.0.0.4\n")
+	require.NoError(t, s.ImportBlocklist(merge))
+	list, err = s.ListBlockedIps(ctx, &pb.ListBlockedIpsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetBlockedIps(), 2)
+
+	invalid := newImportStream(ctx, pb.ImportMode_IMPORT_MODE_MERGE, false, "ip_address\nnot-an-ip\n")
+	assert.Equal(t, codes.InvalidArgument, status.Code(s.ImportBlocklist(invalid)))
 }
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+)
+
+// failurePolicy decides the verdict of a DNS request when the blacklist store
+// cannot be read.
+type failurePolicy string
+
+const (
+	// failOpen lets every request through, as if the IP was clean.
+	failOpen failurePolicy = "open"
+	// failClosed blocks every request.
+	failClosed failurePolicy = "closed"
+	// failSnapshot looks the IP up in the last snapshot of the blacklist.
+	failSnapshot failurePolicy = "snapshot"
+)
+
+func parseFailurePolicy(s string) (failurePolicy, error) {
+	switch p := failurePolicy(s); p {
+	case failOpen, failClosed, failSnapshot:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown blacklist failure policy %q", s)
+	}
+}
+
+// Verdict reasons returned in DnsResponse.reason.
+const (
+	reasonBlacklisted       = "blacklisted"
+	reasonFailOpen          = "store_unavailable_fail_open"
+	reasonFailClosed        = "store_unavailable_fail_closed"
+	reasonSnapshotBlacklist = "store_unavailable_snapshot_blacklisted"
+	reasonSnapshotClean     = "store_unavailable_snapshot_clean"
+	reasonNoSnapshot        = "store_unavailable_no_snapshot"
+)
+
+// verdict tells whether requests from ip are blocked, and why. Store errors
+// are resolved with the failure policy of the server.
+func (s *server) verdict(ctx context.Context, ts *tenantState, ip string) (bool, string) {
+	blocked, reason, _, err := s.decide(ctx, ts, ip)
+	if err == nil {
+		return blocked, reason
+	}
+	log.Printf("Failed to check blacklist of tenant %s for %s, failing %s: %v", ts.id, ip, s.policy, err)
+	stats.Add("store_errors", 1)
+	switch reason {
+	case reasonFailClosed, reasonNoSnapshot:
+		stats.Add("verdicts_fail_closed", 1)
+	case reasonSnapshotBlacklist, reasonSnapshotClean:
+		stats.Add("verdicts_snapshot", 1)
+	default:
+		stats.Add("verdicts_fail_open", 1)
+	}
+	return blocked, reason
+}
+
+// decide is verdict without logs nor statistics. It also returns the
+// blacklist entry of ip, if the store has one, and the store error resolved
+// with the failure policy.
+func (s *server) decide(ctx context.Context, ts *tenantState, ip string) (bool, string, *store.Entry, error) {
+	e, err := ts.store.Get(ctx, ip)
+	if err == nil {
+		return true, reasonBlacklisted, &e, nil
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return false, "", nil, nil
+	}
+
+	switch s.policy {
+	case failClosed:
+		return true, reasonFailClosed, nil, err
+	case failSnapshot:
+		blocked, ok := ts.snapshot.blocked(ip, time.Now())
+		switch {
+		case !ok:
+			// Without a snapshot yet, fail closed rather than unblock
+			// every IP
+			return true, reasonNoSnapshot, nil, err
+		case blocked:
+			return true, reasonSnapshotBlacklist, nil, err
+		default:
+			return false, reasonSnapshotClean, nil, err
+		}
+	default:
+		return false, reasonFailOpen, nil, err
+	}
+}
+
+// snapshot is an in-memory copy of the blacklist, refreshed periodically and
+// on every change made through the server, used when the store is
+// unreachable.
+type snapshot struct {
+	mu      sync.RWMutex
+	entries map[string]store.Entry
+	takenAt time.Time
+}
+
+// newSnapshot returns the empty snapshot of the tenant id.
+func newSnapshot(id string) *snapshot {
+	sn := &snapshot{}
+	gauge(tenantStat("snapshot_entries", id), func() int64 {
+		sn.mu.RLock()
+		defer sn.mu.RUnlock()
+		return int64(len(sn.entries))
+	})
+	gauge(tenantStat("snapshot_age_seconds", id), func() int64 {
+		sn.mu.RLock()
+		defer sn.mu.RUnlock()
+		if sn.takenAt.IsZero() {
+			return 0
+		}
+		return int64(time.Since(sn.takenAt).Seconds())
+	})
+	return sn
+}
+
+// refresh replaces the snapshot with the current content of st.
+func (sn *snapshot) refresh(ctx context.Context, st store.Store) error {
+	list, err := st.List(ctx)
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]store.Entry, len(list))
+	for _, e := range list {
+		entries[e.IP] = e
+	}
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	sn.entries = entries
+	sn.takenAt = time.Now()
+	return nil
+}
+
+// run refreshes the snapshot every interval until ctx is done.
+func (sn *snapshot) run(ctx context.Context, st store.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := sn.refresh(ctx, st); err != nil {
+			log.Printf("Failed to refresh blacklist snapshot: %v", err)
+			stats.Add("snapshot_refresh_failed", 1)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// blocked reports whether ip is blacklisted in the snapshot, ok is false if
+// no snapshot was taken yet.
+func (sn *snapshot) blocked(ip string, now time.Time) (blocked, ok bool) {
+	if sn == nil {
+		return false, false
+	}
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+	if sn.entries == nil {
+		return false, false
+	}
+	e, found := sn.entries[ip]
+	return found && !e.Expired(now), true
+}
+
+// put records a block made through the server.
+func (sn *snapshot) put(e store.Entry) {
+	if sn == nil {
+		return
+	}
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	if sn.entries != nil {
+		sn.entries[e.IP] = e
+	}
+}
+
+// remove records an unblock made through the server.
+func (sn *snapshot) remove(ip string) {
+	if sn == nil {
+		return
+	}
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	delete(sn.entries, ip)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/rpz"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
+	"github.com/miekg/dns"
+)
+
+// rpzOrigin returns the RPZ zone of the tenant id: the zone of cfg for the
+// default tenant, "<id>." followed by it for the others.
+func rpzOrigin(id string, cfg config) string {
+	if id == tenant.Default {
+		return dns.Fqdn(cfg.rpzZone)
+	}
+	return id + "." + dns.Fqdn(cfg.rpzZone)
+}
+
+// serveRPZ serves the blocks of every tenant as RPZ zones on cfg.rpzAddr,
+// over UDP and TCP, until ctx is done.
+func (s *server) serveRPZ(ctx context.Context, cfg config) error {
+	handler, err := rpz.NewHandler(cfg.rpzTransferAllow)
+	if err != nil {
+		return err
+	}
+	states := []*tenantState{{id: tenant.Default, store: s.store, watch: s.watch}}
+	for _, ts := range s.tenants {
+		states = append(states, ts)
+	}
+	zones := make([]*rpz.Zone, len(states))
+	for i, ts := range states {
+		if zones[i], err = rpz.NewZone(rpzOrigin(ts.id, cfg), cfg.rpzAction, uint32(time.Now().Unix())); err != nil {
+			return err
+		}
+		handler.Add(zones[i])
+	}
+	// Listen before serving, so that a busy port fails the start
+	conn, err := net.ListenPacket("udp", cfg.rpzAddr)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", cfg.rpzAddr)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	for _, srv := range []*dns.Server{{PacketConn: conn, Handler: handler}, {Listener: listener, Handler: handler}} {
+		go func() {
+			if err := srv.ActivateAndServe(); err != nil {
+				log.Printf("RPZ server stopped: %v", err)
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			srv.Shutdown()
+		}()
+	}
+	for i, ts := range states {
+		zone := zones[i]
+		gauge(tenantStat("rpz_serial", ts.id), func() int64 { return int64(zone.Serial()) })
+		go s.followRPZ(ctx, ts, zone, cfg.rpzNotify)
+	}
+	log.Printf("Serving the RPZ zone %s on %s", rpzOrigin(tenant.Default, cfg), cfg.rpzAddr)
+	return nil
+}
+
+// followRPZ updates zone with the blocks of the tenant whenever its block
+// journal changes, and notifies the secondaries.
+func (s *server) followRPZ(ctx context.Context, ts *tenantState, zone *rpz.Zone, notify []string) {
+	ts.watch.run(context.Background(), ts.store)
+	for {
+		items, seq, ok := ts.watch.snapshot(ctx)
+		if !ok {
+			return
+		}
+		var entries []store.Entry
+		var rules []store.BlockRule
+		for _, it := range items {
+			if it.entry != nil {
+				entries = append(entries, *it.entry)
+			} else {
+				rules = append(rules, *it.rule)
+			}
+		}
+		if zone.Update(entries, rules, time.Now()) {
+			stats.Add("rpz_updates", 1)
+			go func() {
+				for _, err := range rpz.Notify(zone, notify) {
+					log.Printf("Failed to notify the RPZ zone %s: %v", zone.Origin(), err)
+					stats.Add("rpz_notify_failed", 1)
+				}
+			}()
+		}
+		_, changed, ok := ts.watch.since(seq)
+		if !ok {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/bus"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/deadletter"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/spool"
+)
+
+// spoolRetryInterval is the delay between two replay attempts while the bus
+// is unavailable.
+const spoolRetryInterval = time.Second
+
+// spooledPublisher is a publisher appending the messages to a disk spool when
+// the underlying publisher queue is full or its bus is unreachable, and
+// replaying them in order once it accepts messages again. While the spool is
+// not empty new messages are spooled too, so that they are published after
+// the spooled ones.
+//
+// Messages the bus accepted but failed to deliver are queued for a retry,
+// ahead of the spool: the messages published after them either failed too,
+// and are queued behind them in the order of their delivery reports, or are
+// spooled.
+type spooledPublisher struct {
+	bus.Publisher
+	spool           *spool.Spool
+	deadLetterTopic string
+
+	// mu orders the direct publications with the replay of the spool.
+	mu sync.Mutex
+	// retries are the messages to publish again before the spool. They are
+	// kept in memory, as they were by the bus, and spooled on Close.
+	retries []*bus.Message
+	notify  chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newSpooledPublisher(publisher bus.Publisher, s *spool.Spool, deadLetterTopic string) *spooledPublisher {
+	p := &spooledPublisher{
+		Publisher:       publisher,
+		spool:           s,
+		deadLetterTopic: deadLetterTopic,
+		notify:          make(chan struct{}, 1),
+		done:            make(chan struct{}),
+	}
+	gauge("spool_events", func() int64 { return int64(s.Len()) })
+	gauge("spool_bytes", s.Size)
+	gauge("spool_retry_events", func() int64 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return int64(len(p.retries))
+	})
+	gauge("spool_oldest_age_seconds", func() int64 {
+		oldest := s.Oldest()
+		if oldest.IsZero() {
+			return 0
+		}
+		return int64(time.Since(oldest).Seconds())
+	})
+
+	if n := s.Len(); n > 0 {
+		log.Printf("Replaying %d spooled messages", n)
+	}
+	p.wg.Add(1)
+	go p.replayLoop()
+	return p
+}
+
+func (p *spooledPublisher) Publish(m *bus.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.spool.Len() == 0 && len(p.retries) == 0 {
+		err := p.Publisher.Publish(m)
+		if !transient(err) {
+			return err
+		}
+		log.Printf("Spooling messages to disk: %v", err)
+	}
+	if err := p.appendLocked(m); err != nil {
+		return err
+	}
+	p.wakeUp()
+	return nil
+}
+
+// Respool queues m, which the bus accepted but failed to deliver because it
+// was unreachable, to be published again ahead of the spool. It is retried
+// with the spool rather than right away, so that a bus failing every
+// delivery is not flooded.
+func (p *spooledPublisher) Respool(m *bus.Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retries = append(p.retries, m)
+	stats.Add("spool_respooled", 1)
+}
+
+// appendLocked appends m to the spool; p.mu must be held.
+func (p *spooledPublisher) appendLocked(m *bus.Message) error {
+	if err := p.spool.Append(m); err != nil {
+		stats.Add("spool_append_failed", 1)
+		return err
+	}
+	stats.Add("spooled", 1)
+	return nil
+}
+
+// Close stops the replay and closes the spool and the underlying publisher.
+// Messages left in the spool are replayed after a restart; those waiting for
+// a retry are spooled first, behind the others.
+func (p *spooledPublisher) Close() {
+	close(p.done)
+	p.wg.Wait()
+	p.mu.Lock()
+	for _, m := range p.retries {
+		if err := p.appendLocked(m); err != nil {
+			log.Printf("Failed to spool undelivered message, it is lost: %v", err)
+		}
+	}
+	p.retries = nil
+	p.mu.Unlock()
+	if err := p.spool.Close(); err != nil {
+		log.Printf("Failed to close spool: %v", err)
+	}
+	p.Publisher.Close()
+}
+
+func (p *spooledPublisher) wakeUp() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// replayLoop replays the spool whenever messages are appended, and retries
+// periodically while the bus is unavailable.
+func (p *spooledPublisher) replayLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(spoolRetryInterval)
+	defer ticker.Stop()
+	for {
+		p.replay()
+		select {
+		case <-p.done:
+			return
+		case <-p.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// replay publishes the messages to retry then the spooled ones, in order,
+// until the spool is empty or the bus refuses a message.
+func (p *spooledPublisher) replay() {
+	for {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		if !p.replayOne() {
+			return
+		}
+	}
+}
+
+// replayOne publishes the first message to retry, or else the head of the
+// spool, and reports whether it was removed.
+func (p *spooledPublisher) replayOne() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.retries) > 0 {
+		if !p.publishLocked(p.retries[0]) {
+			return false
+		}
+		p.retries = p.retries[1:]
+		return true
+	}
+	m, err := p.spool.Peek()
+	if errors.Is(err, spool.ErrEmpty) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Failed to read spool: %v", err)
+		return false
+	}
+
+	if !p.publishLocked(m) {
+		return false
+	}
+	if err := p.spool.Ack(); err != nil {
+		log.Printf("Failed to acknowledge spooled message: %v", err)
+		return false
+	}
+	if p.spool.Len() == 0 {
+		log.Printf("Spool drained")
+	}
+	return true
+}
+
+// publishLocked publishes m, or dead-letters it if the bus refuses it for
+// good, and reports whether it is done with; p.mu must be held.
+func (p *spooledPublisher) publishLocked(m *bus.Message) bool {
+	err := p.Publisher.Publish(m)
+	if transient(err) {
+		return false
+	}
+	if err != nil {
+		// The bus will never accept this message, dead-letter it rather
+		// than blocking the spool
+		log.Printf("Failed to replay spooled message to %s: %v", m.Topic, err)
+		if dlqErr := p.Publisher.Publish(deadletter.New(p.deadLetterTopic, m, "server", err)); dlqErr != nil {
+			log.Printf("Failed to dead-letter spooled message: %v", dlqErr)
+			stats.Add("dead_letters_lost", 1)
+		} else {
+			stats.Add("dead_letters", 1)
+		}
+	} else {
+		stats.Add("spool_replayed", 1)
+	}
+	return true
+}
+
+// transient reports whether a publish error means the message can be
+// published later.
+func transient(err error) bool {
+	return errors.Is(err, bus.ErrQueueFull) || errors.Is(err, bus.ErrUnavailable)
+}
@@ -0,0 +1,27 @@
+package main
+
+import "expvar"
+
+// stats holds the server counters and gauges returned by GetStats.
+var stats = expvar.NewMap("dns_server")
+
+// gauge registers in stats a value computed by f when it is read.
+func gauge(name string, f func() int64) {
+	stats.Set(name, expvar.Func(func() any { return f() }))
+}
+
+// statsSnapshot returns a copy of every integer counter and gauge in stats.
+func statsSnapshot() map[string]int64 {
+	counters := make(map[string]int64)
+	stats.Do(func(kv expvar.KeyValue) {
+		switch v := kv.Value.(type) {
+		case *expvar.Int:
+			counters[kv.Key] = v.Value()
+		case expvar.Func:
+			if n, ok := v.Value().(int64); ok {
+				counters[kv.Key] = n
+			}
+		}
+	})
+	return counters
+}
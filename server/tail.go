@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+// tailBufferSize is the number of events buffered per watcher before new
+// events are dropped for it.
+const tailBufferSize = 256
+
+// tailHub fans out processed DNS requests to TailDnsRequests watchers.
+type tailHub struct {
+	mu sync.Mutex
+	// watchers maps the channel of each watcher to its tenant.
+	watchers map[chan *pb.TailDnsRequestsResponse]string
+}
+
+func newTailHub() *tailHub {
+	return &tailHub{watchers: make(map[chan *pb.TailDnsRequestsResponse]string)}
+}
+
+// subscribe registers a new watcher of the requests of tenant. The returned
+// function must be called once the watcher is done.
+func (h *tailHub) subscribe(tenant string) (<-chan *pb.TailDnsRequestsResponse, func()) {
+	ch := make(chan *pb.TailDnsRequestsResponse, tailBufferSize)
+	h.mu.Lock()
+	h.watchers[ch] = tenant
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.watchers, ch)
+		h.mu.Unlock()
+	}
+}
+
+// publish sends an event of tenant to its watchers without blocking, slow
+// watchers miss events instead of slowing down SendDnsRequest.
+func (h *tailHub) publish(tenant string, ev *pb.TailDnsRequestsResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch, t := range h.watchers {
+		if t != tenant {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+			stats.Add("tail_dropped", 1)
+		}
+	}
+}
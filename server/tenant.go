@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tenantState is the blacklist, allowlist and block rules of a tenant, with
+// the snapshot of its blacklist under the snapshot failure policy and the
+// journal of its blocks for WatchBlocks.
+type tenantState struct {
+	id        string
+	store     store.Store
+	allowlist *allowlistCache
+	rules     *ruleCache
+	snapshot  *snapshot
+	watch     *blockJournal
+}
+
+// newTenantState returns the state of the tenant id, whose allowlist, block
+// rules and snapshot are refreshed in the background with the intervals of
+// cfg.
+func newTenantState(ctx context.Context, root store.Store, id string, cfg config) (*tenantState, error) {
+	st, err := root.Tenant(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the store of tenant %s: %w", id, err)
+	}
+	ts := &tenantState{id: id, store: st, allowlist: &allowlistCache{}, rules: &ruleCache{}, watch: newBlockJournal(cfg.watchPollInterval)}
+	go ts.allowlist.run(ctx, st, cfg.allowlistRefreshInterval)
+	go ts.rules.run(ctx, st, cfg.blockRulesRefreshInterval)
+	if cfg.failurePolicy == failSnapshot {
+		ts.snapshot = newSnapshot(id)
+		go ts.snapshot.run(ctx, st, cfg.snapshotInterval)
+	}
+	return ts, nil
+}
+
+// scope returns the state of the tenant of ctx.
+func (s *server) scope(ctx context.Context) (*tenantState, error) {
+	id := tenant.FromContext(ctx)
+	if id == tenant.Default {
+		return &tenantState{id: id, store: s.store, allowlist: s.allowlist, rules: s.rules, snapshot: s.snapshot, watch: s.watch}, nil
+	}
+	ts, ok := s.tenants[id]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "unknown tenant %s", id)
+	}
+	return ts, nil
+}
+
+// topicOf returns the topic of the DNS requests of the tenant id.
+func (s *server) topicOf(id string) string {
+	return s.tenantConfig.Topic(id, topic)
+}
+
+// withTenant resolves the tenant of an incoming request from its
+// x-tenant-id and authorization metadata and returns ctx carrying it.
+func (s *server) withTenant(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id, token := firstValue(md, tenant.MetadataKey), ""
+	if auth := firstValue(md, "authorization"); auth != "" {
+		scheme, value, _ := strings.Cut(auth, " ")
+		if !strings.EqualFold(scheme, "bearer") || value == "" {
+			return nil, status.Error(codes.Unauthenticated, "authorization is not a bearer token")
+		}
+		token = value
+	}
+	resolved, err := s.tenantConfig.Resolve(id, token)
+	switch {
+	case errors.Is(err, tenant.ErrUnknownToken), errors.Is(err, tenant.ErrTokenRequired):
+		stats.Add("tenant_auth_failed", 1)
+		audit(ctx, "rejected request for tenant %q: %v", id, err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, tenant.ErrForbidden), errors.Is(err, tenant.ErrUnknownTenant):
+		stats.Add("tenant_auth_failed", 1)
+		audit(ctx, "rejected request for tenant %q: %v", id, err)
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return tenant.NewContext(ctx, resolved), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// unaryTenant is the interceptor resolving the tenant of unary calls.
+func (s *server) unaryTenant(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.withTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamTenant is the interceptor resolving the tenant of streaming calls.
+func (s *server) streamTenant(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.withTenant(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+}
+
+// tenantStream is a server stream whose context carries the tenant.
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+// openTenants returns the state of every declared tenant but the default
+// one, which is held by the server itself.
+func openTenants(ctx context.Context, root store.Store, cfg config) (map[string]*tenantState, error) {
+	tenants := make(map[string]*tenantState)
+	for _, id := range cfg.tenants.IDs() {
+		if id == tenant.Default {
+			continue
+		}
+		ts, err := newTenantState(ctx, root, id, cfg)
+		if err != nil {
+			return nil, err
+		}
+		tenants[id] = ts
+	}
+	return tenants, nil
+}
+
+// tenantStat returns the name of the gauge name of the tenant id, name for
+// the default tenant.
+func tenantStat(name, id string) string {
+	if id == tenant.Default {
+		return name
+	}
+	return name + "." + id
+}
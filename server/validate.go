@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/dnsname"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/ipaddr"
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/qtype"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxSensorIDLength bounds the sensor identity carried by every event.
+const maxSensorIDLength = 255
+
+// normalizeDnsRequest validates req and rewrites its addresses and names in
+// canonical form. Errors have the InvalidArgument code.
+func normalizeDnsRequest(req *pb.DnsRequest) error {
+	if err := normalizeDnsRequestFields(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+func normalizeDnsRequestFields(req *pb.DnsRequest) error {
+	var err error
+	if req.IpAddress, err = ipaddr.Canonical(req.GetIpAddress()); err != nil {
+		return err
+	}
+	if req.Domain, err = dnsname.Normalize(req.GetDomain()); err != nil {
+		return err
+	}
+	if !qtype.Valid(req.GetQueryType()) {
+		return fmt.Errorf("invalid query type %d", req.GetQueryType())
+	}
+	if req.Rcode != nil && (req.GetRcode() < 0 || req.GetRcode() > 4095) {
+		return fmt.Errorf("invalid response code %d", req.GetRcode())
+	}
+	for i, a := range req.GetAnswers() {
+		if err := normalizeAnswer(a); err != nil {
+			return fmt.Errorf("answer %d: %w", i, err)
+		}
+	}
+	if req.GetClientPort() > 65535 {
+		return fmt.Errorf("invalid client port %d", req.GetClientPort())
+	}
+	if req.GetEdnsClientSubnet() != "" {
+		prefix, err := netip.ParsePrefix(req.GetEdnsClientSubnet())
+		if err != nil {
+			return fmt.Errorf("invalid EDNS client subnet %q", req.GetEdnsClientSubnet())
+		}
+		req.EdnsClientSubnet = prefix.Masked().String()
+	}
+	if len(req.GetSensorId()) > maxSensorIDLength {
+		return fmt.Errorf("sensor ID longer than %d characters", maxSensorIDLength)
+	}
+	return nil
+}
+
+// normalizeAnswer validates an answer record, and canonicalizes the address
+// of A and AAAA records.
+func normalizeAnswer(a *pb.Answer) error {
+	if !qtype.Valid(a.GetType()) {
+		return fmt.Errorf("invalid type %d", a.GetType())
+	}
+	switch a.GetType() {
+	case pb.QueryType_QUERY_TYPE_A, pb.QueryType_QUERY_TYPE_AAAA:
+		addr, err := ipaddr.Parse(a.GetData())
+		if err != nil {
+			return err
+		}
+		if addr.Is4() != (a.GetType() == pb.QueryType_QUERY_TYPE_A) {
+			return fmt.Errorf("%s record with address %s", qtype.String(a.GetType()), addr)
+		}
+		a.Data = addr.String()
+	}
+	return nil
+}
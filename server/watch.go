@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Raideeen/DNS-Stream-Analyzer/internal/store"
+	"github.com/Raideeen/DNS-Stream-Analyzer/pb"
+)
+
+// journalSize is the number of block events kept for resuming watchers.
+// Watchers resuming from an older event get a new snapshot.
+const journalSize = 10000
+
+// blockItem is a blocked IP or a block rule known by a blockJournal.
+type blockItem struct {
+	entry *store.Entry
+	rule  *store.BlockRule
+	// version changes whenever the block is replaced.
+	version string
+}
+
+func entryItem(e store.Entry) blockItem {
+	return blockItem{entry: &e, version: fmt.Sprintf("%s|%d|%d", e.Reason, e.CreatedAt.UnixNano(), e.ExpiresAt.UnixNano())}
+}
+
+func ruleItem(r store.BlockRule) blockItem {
+	return blockItem{rule: &r, version: fmt.Sprintf("%s|%d|%d", r.Reason, r.CreatedAt.UnixNano(), r.ExpiresAt.UnixNano())}
+}
+
+func (it blockItem) expiresAt() time.Time {
+	if it.entry != nil {
+		return it.entry.ExpiresAt
+	}
+	return it.rule.ExpiresAt
+}
+
+// event returns the WatchBlocks event of the item.
+func (it blockItem) event(t pb.BlockEventType, at time.Time) *pb.WatchBlocksResponse {
+	ev := &pb.WatchBlocksResponse{Type: t, Time: at.Unix()}
+	if it.entry != nil {
+		ev.BlockedIp = blockedIp(*it.entry)
+	} else {
+		ev.Rule = blockRule(*it.rule)
+	}
+	return ev
+}
+
+// blockJournal follows the blocked IPs and block rules of a store for
+// WatchBlocks and the RPZ zones. Blocks may change through any server, so
+// the journal lists the store every interval, and on every change made
+// through this server, and numbers the differences with the previous
+// listing.
+//
+// Resume tokens are "<epoch>.<seq>", where the epoch identifies the journal:
+// tokens of another server or of a previous run get a new snapshot.
+type blockJournal struct {
+	interval time.Duration
+	poke     chan struct{}
+	start    sync.Once
+
+	mu    sync.Mutex
+	epoch string
+	// seq is the number of the last event, events holds the last
+	// journalSize events, oldest first.
+	seq    uint64
+	events []*pb.WatchBlocksResponse
+	// blocks are the blocks of the last listing, by "ip:<ip>" or
+	// "rule:<id>", nil until the first listing.
+	blocks map[string]blockItem
+	// changed is closed, and replaced, when events are added or the first
+	// listing is done.
+	changed chan struct{}
+}
+
+func newBlockJournal(interval time.Duration) *blockJournal {
+	epoch := make([]byte, 8)
+	rand.Read(epoch)
+	return &blockJournal{
+		interval: interval,
+		poke:     make(chan struct{}, 1),
+		epoch:    hex.EncodeToString(epoch),
+		changed:  make(chan struct{}),
+	}
+}
+
+// run starts following st, once, in the background until ctx is done.
+func (j *blockJournal) run(ctx context.Context, st store.Store) {
+	j.start.Do(func() {
+		go j.loop(ctx, st)
+	})
+}
+
+func (j *blockJournal) loop(ctx context.Context, st store.Store) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		if err := j.refresh(ctx, st, time.Now()); err != nil {
+			log.Printf("Failed to list blocks for watchers: %v", err)
+			stats.Add("block_journal_refresh_failed", 1)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-j.poke:
+		}
+	}
+}
+
+// changedNow asks for a listing of the store, after a change made through the
+// server.
+func (j *blockJournal) changedNow() {
+	if j == nil {
+		return
+	}
+	select {
+	case j.poke <- struct{}{}:
+	default:
+	}
+}
+
+// refresh lists st and journals the differences with the previous listing.
+func (j *blockJournal) refresh(ctx context.Context, st store.Store, now time.Time) error {
+	entries, err := st.List(ctx)
+	if err != nil {
+		return err
+	}
+	rules, err := st.ListBlockRules(ctx)
+	if err != nil {
+		return err
+	}
+	blocks := make(map[string]blockItem, len(entries)+len(rules))
+	for _, e := range entries {
+		blocks["ip:"+e.IP] = entryItem(e)
+	}
+	for _, r := range rules {
+		blocks["rule:"+r.ID] = ruleItem(r)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.blocks == nil {
+		j.blocks = blocks
+		j.notify()
+		return nil
+	}
+	var events []*pb.WatchBlocksResponse
+	for key, it := range blocks {
+		if old, ok := j.blocks[key]; !ok || old.version != it.version {
+			events = append(events, it.event(pb.BlockEventType_BLOCK_EVENT_TYPE_ADDED, now))
+		}
+	}
+	for key, old := range j.blocks {
+		if _, ok := blocks[key]; ok {
+			continue
+		}
+		t := pb.BlockEventType_BLOCK_EVENT_TYPE_REMOVED
+		if exp := old.expiresAt(); !exp.IsZero() && !now.Before(exp) {
+			t = pb.BlockEventType_BLOCK_EVENT_TYPE_EXPIRED
+		}
+		events = append(events, old.event(t, now))
+	}
+	j.blocks = blocks
+	for _, ev := range events {
+		j.seq++
+		ev.ResumeToken = j.token(j.seq)
+		j.events = append(j.events, ev)
+	}
+	if len(j.events) > journalSize {
+		j.events = append([]*pb.WatchBlocksResponse(nil), j.events[len(j.events)-journalSize:]...)
+	}
+	if len(events) > 0 {
+		j.notify()
+	}
+	return nil
+}
+
+// notify wakes up the watchers, j.mu must be held.
+func (j *blockJournal) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (j *blockJournal) token(seq uint64) string {
+	return j.epoch + "." + strconv.FormatUint(seq, 10)
+}
+
+// resume returns the sequence number of a resume token, false if the stream
+// cannot be resumed from it.
+func (j *blockJournal) resume(token string) (uint64, bool) {
+	epoch, s, ok := strings.Cut(token, ".")
+	if !ok || epoch != j.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	_, _, ok = j.since(seq)
+	return seq, ok
+}
+
+// snapshot returns the current blocks and the sequence number of the last
+// event, once the first listing is done, or false if ctx is done first.
+func (j *blockJournal) snapshot(ctx context.Context) ([]blockItem, uint64, bool) {
+	for {
+		j.mu.Lock()
+		if j.blocks != nil {
+			items := make([]blockItem, 0, len(j.blocks))
+			for _, it := range j.blocks {
+				items = append(items, it)
+			}
+			seq := j.seq
+			j.mu.Unlock()
+			return items, seq, true
+		}
+		changed := j.changed
+		j.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, 0, false
+		case <-changed:
+		}
+	}
+}
+
+// since returns the events after seq and a channel closed when there are new
+// ones, or false if the events following seq are no longer journaled.
+func (j *blockJournal) since(seq uint64) ([]*pb.WatchBlocksResponse, <-chan struct{}, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	first := j.seq - uint64(len(j.events)) + 1
+	if seq > j.seq || seq+1 < first {
+		return nil, nil, false
+	}
+	return j.events[seq+1-first:], j.changed, true
+}
+
+// WatchBlocks streams the blocked IPs and block rules of the tenant: a
+// snapshot, unless the request resumes a previous stream, then every change
+// until the client goes away
+func (s *server) WatchBlocks(req *pb.WatchBlocksRequest, stream pb.DnsService_WatchBlocksServer) error {
+	ctx := stream.Context()
+	ts, err := s.scope(ctx)
+	if err != nil {
+		return err
+	}
+	ts.watch.run(context.Background(), ts.store)
+	stats.Add("block_watchers", 1)
+	defer stats.Add("block_watchers", -1)
+
+	seq, resumed := ts.watch.resume(req.GetResumeToken())
+	for {
+		if !resumed {
+			items, last, ok := ts.watch.snapshot(ctx)
+			if !ok {
+				return nil
+			}
+			now := time.Now()
+			for _, it := range items {
+				if err := stream.Send(it.event(pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT, now)); err != nil {
+					return err
+				}
+			}
+			end := &pb.WatchBlocksResponse{
+				Type:        pb.BlockEventType_BLOCK_EVENT_TYPE_SNAPSHOT_END,
+				ResumeToken: ts.watch.token(last),
+				Time:        now.Unix(),
+			}
+			if err := stream.Send(end); err != nil {
+				return err
+			}
+			seq, resumed = last, true
+		}
+
+		events, changed, ok := ts.watch.since(seq)
+		if !ok {
+			// The watcher fell behind the journal, start over
+			stats.Add("block_watch_resyncs", 1)
+			resumed = false
+			continue
+		}
+		for _, ev := range events {
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+		seq += uint64(len(events))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}